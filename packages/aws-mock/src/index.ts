@@ -1,9 +1,39 @@
 import { Hono } from "hono";
+import type { Context } from "hono";
 import { StateStore } from "./state/store";
+import type { StoredResource } from "./state/store";
 import { buildHandlerRegistry } from "./resources/registry";
-import type { ResourceHandler } from "./resources/types";
+import type { ResourceHandler, ResourceResult } from "./resources/types";
+import { AwsError } from "./resources/types";
 import { validateRegion } from "./utils/validation";
 
+/** Formats a resource version as an ETag. */
+function etag(version: number): string {
+  return `"${version}"`;
+}
+
+/**
+ * Reports whether an If-Match header, if the request has one, names the
+ * resource's current version.
+ */
+function ifMatchAllows(header: string | undefined, version: number): boolean {
+  if (!header || header.trim() === "*") {
+    return true;
+  }
+  return header
+    .split(",")
+    .some((tag) => tag.trim().replace(/^W\//, "").replaceAll('"', "") === String(version));
+}
+
+/** The body of a resource in responses, with its version as a string. */
+function resourceBody(resource: ResourceResult, version: number | null) {
+  return {
+    id: resource.id,
+    attributes: resource.attributes,
+    ...(version === null ? {} : { version: String(version) }),
+  };
+}
+
 export async function createApp(statePath: string) {
   const app = new Hono();
   const store = new StateStore(statePath);
@@ -11,6 +41,42 @@ export async function createApp(statePath: string) {
   const handlers: Record<string, ResourceHandler> =
     await buildHandlerRegistry(store);
 
+  // Answers with a resource, its version in the ETag header.
+  async function sendResource(
+    c: Context,
+    type: string,
+    resource: ResourceResult,
+    status: 200 | 201 | 202 = 200,
+  ) {
+    const version = await store.resourceVersion(type, resource.id);
+    if (version !== null) {
+      c.header("ETag", etag(version));
+    }
+    return c.json(resourceBody(resource, version), status);
+  }
+
+  // Writes that can change a version run one at a time, so an If-Match
+  // check and the write it guards see the same version.
+  let writes: Promise<unknown> = Promise.resolve();
+  function serialized<T>(fn: () => Promise<T>): Promise<T> {
+    const result = writes.then(fn, fn);
+    writes = result.catch(() => {});
+    return result;
+  }
+
+  // Returns a 412 response when the request's If-Match names a version
+  // other than the resource's current one.
+  async function checkIfMatch(c: Context, type: string, id: string) {
+    const version = await store.resourceVersion(type, id);
+    if (version !== null && !ifMatchAllows(c.req.header("If-Match"), version)) {
+      return c.json(
+        { error: `Resource ${type}/${id} has changed: it is now at version ${version}` },
+        412,
+      );
+    }
+    return null;
+  }
+
   // Provider configuration
   app.post("/provider/configure", async (c) => {
     const body = await c.req.json();
@@ -22,6 +88,29 @@ export async function createApp(statePath: string) {
     return c.json({ region }, 200);
   });
 
+  // Batch read: many resources of any type in one request, all from the
+  // same state. Handlers read straight from the store, so this does too.
+  // Resources that don't exist, or whose type is unknown, come back with
+  // found: false.
+  app.post("/resources/batch-read", async (c) => {
+    const body = await c.req.json();
+    if (!Array.isArray(body.resources)) {
+      return c.json({ error: "Missing resources" }, 400);
+    }
+    const refs: { type: string; id: string }[] = body.resources;
+
+    const stored = await store.readResources(refs);
+    const resources = refs.map(({ type, id }, i) => {
+      const resource = handlers[type] ? stored[i] : null;
+      if (!resource) {
+        return { type, id, found: false, attributes: null };
+      }
+      return { type, ...resourceBody(resource, resource.version ?? 0), found: true };
+    });
+
+    return c.json({ resources });
+  });
+
   // Create
   app.post("/resource/:type", async (c) => {
     const type = c.req.param("type");
@@ -41,12 +130,29 @@ export async function createApp(statePath: string) {
         attributes: body.attributes,
       });
 
-      return c.json(result, 201);
+      return sendResource(c, type, result, 201);
     } catch (e) {
       return c.json({ error: (e as Error).message }, 400);
     }
   });
 
+  // List, keeping resources whose attributes equal every query parameter
+  app.get("/resource/:type", async (c) => {
+    const type = c.req.param("type");
+    if (!handlers[type]) {
+      return c.json({ error: `Unknown resource type: ${type}` }, 404);
+    }
+
+    const filters = Object.entries(c.req.query());
+    const resources = (await store.listResources(type))
+      .filter((resource) =>
+        filters.every(([key, value]) => String(resource.attributes[key]) === value),
+      )
+      .map((resource: StoredResource) => resourceBody(resource, resource.version ?? 0));
+
+    return c.json({ resources });
+  });
+
   // Read
   app.get("/resource/:type/:id", async (c) => {
     const type = c.req.param("type");
@@ -66,7 +172,7 @@ export async function createApp(statePath: string) {
       return c.json({ error: `Resource ${type}/${id} not found` }, 404);
     }
 
-    return c.json(result);
+    return sendResource(c, type, result);
   });
 
   // Update
@@ -79,17 +185,65 @@ export async function createApp(statePath: string) {
     }
 
     const body = await c.req.json();
-    try {
-      const result = await handler.update({
-        resourceType: type,
-        attributes: body.attributes,
-        id,
-      });
+    return serialized(async () => {
+      const conflict = await checkIfMatch(c, type, id);
+      if (conflict) {
+        return conflict;
+      }
+      try {
+        const result = await handler.update({
+          resourceType: type,
+          attributes: body.attributes,
+          id,
+        });
 
-      return c.json(result);
-    } catch (e) {
-      return c.json({ error: (e as Error).message }, 400);
+        return sendResource(c, type, result);
+      } catch (e) {
+        return c.json({ error: (e as Error).message }, 400);
+      }
+    });
+  });
+
+  // Drift: change a resource outside Terraform, now or at apply_at
+  app.post("/drift/:type/:id", async (c) => {
+    const type = c.req.param("type");
+    const id = c.req.param("id");
+    if (!handlers[type]) {
+      return c.json({ error: `Unknown resource type: ${type}` }, 404);
     }
+
+    const body = await c.req.json();
+    if (!body.attributes) {
+      return c.json({ error: "Missing attributes" }, 400);
+    }
+    const applyAt = body.apply_at ? new Date(body.apply_at) : undefined;
+    if (applyAt && Number.isNaN(applyAt.getTime())) {
+      return c.json({ error: `Invalid apply_at: ${body.apply_at}` }, 400);
+    }
+
+    return serialized(async () => {
+      try {
+        const result = await store.driftResource(type, id, body.attributes, applyAt);
+        return sendResource(c, type, result, applyAt && applyAt > new Date() ? 202 : 200);
+      } catch (e) {
+        return c.json({ error: (e as Error).message }, 404);
+      }
+    });
+  });
+
+  // Tombstone: when a resource that no longer exists was deleted
+  app.get("/tombstone/:type/:id", async (c) => {
+    const type = c.req.param("type");
+    const id = c.req.param("id");
+    if (!handlers[type]) {
+      return c.json({ error: `Unknown resource type: ${type}` }, 404);
+    }
+
+    const tombstone = await store.readTombstone(type, id);
+    if (!tombstone) {
+      return c.json({ error: `Resource ${type}/${id} was never deleted` }, 404);
+    }
+    return c.json({ type, id, ...tombstone });
   });
 
   // Delete
@@ -101,13 +255,26 @@ export async function createApp(statePath: string) {
       return c.json({ error: `Unknown resource type: ${type}` }, 404);
     }
 
-    await handler.delete({
-      resourceType: type,
-      attributes: {},
-      id,
-    });
+    return serialized(async () => {
+      const conflict = await checkIfMatch(c, type, id);
+      if (conflict) {
+        return conflict;
+      }
+      try {
+        await handler.delete({
+          resourceType: type,
+          attributes: {},
+          id,
+        });
+      } catch (e) {
+        if (e instanceof AwsError) {
+          return c.json({ error: e.message, code: e.code }, 400);
+        }
+        throw e;
+      }
 
-    return c.body(null, 204);
+      return c.body(null, 204);
+    });
   });
 
   return app;
@@ -1,4 +1,5 @@
 import type { ResourceHandler, ResourceContext, ResourceResult } from "./types";
+import { AwsError } from "./types";
 import type { StateStore } from "../state/store";
 import {
   generateSecurityGroupId,
@@ -69,6 +70,20 @@ export function createSecurityGroupHandler(store: StateStore): ResourceHandler {
 
     async delete(ctx: ResourceContext): Promise<void> {
       const id = ctx.id!;
+      // Instances name their groups by ID in vpc_security_group_ids, and by
+      // ID or name in security_groups.
+      const name = (await store.readResource("aws_security_group", id))?.attributes.name;
+      const instances = await store.listResources("aws_instance");
+      const inUse = instances.some((instance) => {
+        const groups = [
+          ...((instance.attributes.vpc_security_group_ids as string[] | undefined) ?? []),
+          ...((instance.attributes.security_groups as string[] | undefined) ?? []),
+        ];
+        return groups.includes(id) || (typeof name === "string" && name !== "" && groups.includes(name));
+      });
+      if (inUse) {
+        throw new AwsError("DependencyViolation", `resource ${id} has a dependent object`);
+      }
       await store.deleteResource("aws_security_group", id);
     },
   };
@@ -15,3 +15,16 @@ export interface ResourceHandler {
   update(ctx: ResourceContext): Promise<ResourceResult>;
   delete(ctx: ResourceContext): Promise<void>;
 }
+
+/**
+ * An error with an AWS error code, e.g. DependencyViolation. The server
+ * sends the code alongside the message, as AWS does.
+ */
+export class AwsError extends Error {
+  constructor(
+    readonly code: string,
+    message: string,
+  ) {
+    super(message);
+  }
+}
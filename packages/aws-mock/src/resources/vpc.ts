@@ -1,4 +1,5 @@
 import type { ResourceHandler, ResourceContext, ResourceResult } from "./types";
+import { AwsError } from "./types";
 import type { StateStore } from "../state/store";
 import {
   generateVpcId,
@@ -80,6 +81,10 @@ export function createVpcHandler(store: StateStore): ResourceHandler {
 
     async delete(ctx: ResourceContext): Promise<void> {
       const id = ctx.id!;
+      const subnets = await store.listResources("aws_subnet");
+      if (subnets.some((subnet) => subnet.attributes.vpc_id === id)) {
+        throw new AwsError("DependencyViolation", `The vpc '${id}' has dependencies and cannot be deleted.`);
+      }
       await store.deleteResource("aws_vpc", id);
     },
   };
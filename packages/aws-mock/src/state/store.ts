@@ -1,20 +1,74 @@
 import { readFile, writeFile, mkdir } from "node:fs/promises";
 import { dirname } from "node:path";
 
-interface StoredResource {
+export interface StoredResource {
   id: string;
   attributes: Record<string, unknown>;
+  /** Counts the changes made to the resource, from 1 on create. */
+  version?: number;
 }
 
+/** A change to a resource's attributes waiting for its time to come. */
+export interface PendingDrift {
+  type: string;
+  id: string;
+  attributes: Record<string, unknown>;
+  apply_at: string;
+}
+
+/** What the state remembers of a deleted resource. */
+export interface Tombstone {
+  deleted_at: string;
+}
+
+/**
+ * How many tombstones the state keeps per resource type. Beyond it the
+ * oldest are forgotten, so a long-lived state file doesn't grow with every
+ * resource ever deleted. The provider's offline store uses the same cap.
+ */
+export const MAX_TOMBSTONES = 100;
+
 interface StateData {
   version: 1;
   resources: Record<string, Record<string, StoredResource>>;
+  drift?: PendingDrift[];
+  tombstones?: Record<string, Record<string, Tombstone>>;
+  /**
+   * IDs handed out so far per type by the provider's offline backend, which
+   * numbers its IDs. This store makes random IDs and only carries it along.
+   */
+  next_ids?: Record<string, number>;
+}
+
+/**
+ * Merges drifted attributes into a stored resource. Drifting tags drifts
+ * tags_all with them, as a change made in the console would.
+ */
+function applyDrift(resource: StoredResource, attributes: Record<string, unknown>): void {
+  resource.attributes = { ...resource.attributes, ...attributes };
+  resource.version = (resource.version ?? 0) + 1;
+  if ("tags" in attributes && "tags_all" in resource.attributes && !("tags_all" in attributes)) {
+    resource.attributes.tags_all = { ...((attributes.tags as Record<string, string>) ?? {}) };
+  }
+}
+
+/** Drops the oldest of a type's tombstones beyond MAX_TOMBSTONES. */
+function pruneTombstones(tombstones: Record<string, Tombstone>): void {
+  const oldestFirst = Object.entries(tombstones).sort(
+    ([a, x], [b, y]) => Date.parse(x.deleted_at) - Date.parse(y.deleted_at) || a.localeCompare(b),
+  );
+  for (const [id] of oldestFirst.slice(0, Math.max(0, oldestFirst.length - MAX_TOMBSTONES))) {
+    delete tombstones[id];
+  }
 }
 
 export class StateStore {
   private mutex: Promise<void> = Promise.resolve();
 
-  constructor(private filePath: string) {}
+  constructor(
+    private filePath: string,
+    private now: () => Date = () => new Date(),
+  ) {}
 
   private async withLock<T>(fn: () => Promise<T>): Promise<T> {
     let release: () => void;
@@ -32,12 +86,37 @@ export class StateStore {
   }
 
   private async load(): Promise<StateData> {
+    let state: StateData;
     try {
       const raw = await readFile(this.filePath, "utf-8");
-      return JSON.parse(raw);
+      state = JSON.parse(raw);
     } catch {
       return { version: 1, resources: {} };
     }
+    if (this.applyDueDrift(state)) {
+      await this.save(state);
+    }
+    return state;
+  }
+
+  /** Applies the pending drift whose time has come; reports whether any did. */
+  private applyDueDrift(state: StateData): boolean {
+    if (!state.drift?.length) {
+      return false;
+    }
+    const now = this.now().getTime();
+    const due = state.drift.filter((d) => Date.parse(d.apply_at) <= now);
+    if (due.length === 0) {
+      return false;
+    }
+    for (const drift of due) {
+      const resource = state.resources[drift.type]?.[drift.id];
+      if (resource) {
+        applyDrift(resource, drift.attributes);
+      }
+    }
+    state.drift = state.drift.filter((d) => !due.includes(d));
+    return true;
   }
 
   private async save(state: StateData): Promise<void> {
@@ -55,8 +134,9 @@ export class StateStore {
       if (!state.resources[type]) {
         state.resources[type] = {};
       }
-      const resource: StoredResource = { id, attributes };
+      const resource: StoredResource = { id, attributes, version: 1 };
       state.resources[type][id] = resource;
+      delete state.tombstones?.[type]?.[id];
       await this.save(state);
       return resource;
     });
@@ -69,6 +149,33 @@ export class StateStore {
     });
   }
 
+  /**
+   * Reads many resources under one lock, so they come from the same state.
+   * Resources that don't exist map to null.
+   */
+  async readResources(refs: { type: string; id: string }[]): Promise<(StoredResource | null)[]> {
+    return this.withLock(async () => {
+      const state = await this.load();
+      return refs.map(({ type, id }) => state.resources[type]?.[id] ?? null);
+    });
+  }
+
+  /**
+   * Returns the version of a resource, 0 for one stored before versions
+   * were tracked, or null if it doesn't exist.
+   */
+  async resourceVersion(type: string, id: string): Promise<number | null> {
+    const resource = await this.readResource(type, id);
+    return resource ? (resource.version ?? 0) : null;
+  }
+
+  async listResources(type: string): Promise<StoredResource[]> {
+    return this.withLock(async () => {
+      const state = await this.load();
+      return Object.values(state.resources[type] ?? {});
+    });
+  }
+
   async updateResource(
     type: string,
     id: string,
@@ -81,6 +188,33 @@ export class StateStore {
         throw new Error(`Resource ${type}/${id} not found`);
       }
       existing.attributes = attributes;
+      existing.version = (existing.version ?? 0) + 1;
+      await this.save(state);
+      return existing;
+    });
+  }
+
+  /**
+   * Changes a resource's attributes behind Terraform's back, now or, when
+   * applyAt is given, the first time the state is loaded after it.
+   */
+  async driftResource(
+    type: string,
+    id: string,
+    attributes: Record<string, unknown>,
+    applyAt?: Date,
+  ): Promise<StoredResource> {
+    return this.withLock(async () => {
+      const state = await this.load();
+      const existing = state.resources[type]?.[id];
+      if (!existing) {
+        throw new Error(`Resource ${type}/${id} not found`);
+      }
+      if (applyAt && applyAt.getTime() > this.now().getTime()) {
+        state.drift = [...(state.drift ?? []), { type, id, attributes, apply_at: applyAt.toISOString() }];
+      } else {
+        applyDrift(existing, attributes);
+      }
       await this.save(state);
       return existing;
     });
@@ -94,7 +228,22 @@ export class StateStore {
         throw new Error(`Resource ${type}/${id} not found`);
       }
       delete state.resources[type][id];
+      state.tombstones ??= {};
+      state.tombstones[type] ??= {};
+      state.tombstones[type][id] = { deleted_at: this.now().toISOString() };
+      pruneTombstones(state.tombstones[type]);
       await this.save(state);
     });
   }
+
+  /**
+   * Returns when a resource was deleted, or null if the state has never held
+   * it or holds it again.
+   */
+  async readTombstone(type: string, id: string): Promise<Tombstone | null> {
+    return this.withLock(async () => {
+      const state = await this.load();
+      return state.tombstones?.[type]?.[id] ?? null;
+    });
+  }
 }
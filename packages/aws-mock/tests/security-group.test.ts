@@ -156,6 +156,35 @@ describe("aws_security_group handler", () => {
       const result = await store.readResource("aws_security_group", created.id);
       expect(result).toBeNull();
     });
+
+    test("refuses while instances use it, with DependencyViolation", async () => {
+      const created = await handler.create({
+        resourceType: "aws_security_group",
+        attributes: { name: "in-use-sg", vpc_id: "vpc-abc123" },
+      });
+      await store.createResource("aws_instance", "i-abc123", { vpc_security_group_ids: [created.id] });
+
+      const deleting = handler.delete({ resourceType: "aws_security_group", attributes: {}, id: created.id });
+      await expect(deleting).rejects.toMatchObject({ code: "DependencyViolation" });
+      expect(await store.readResource("aws_security_group", created.id)).not.toBeNull();
+    });
+
+    test("refuses while instances name it in security_groups, by ID or name", async () => {
+      const byId = await handler.create({
+        resourceType: "aws_security_group",
+        attributes: { name: "classic-by-id", vpc_id: "vpc-abc123" },
+      });
+      const byName = await handler.create({
+        resourceType: "aws_security_group",
+        attributes: { name: "classic-by-name", vpc_id: "vpc-abc123" },
+      });
+      await store.createResource("aws_instance", "i-abc123", { security_groups: [byId.id, "classic-by-name"] });
+
+      for (const group of [byId, byName]) {
+        const deleting = handler.delete({ resourceType: "aws_security_group", attributes: {}, id: group.id });
+        await expect(deleting).rejects.toMatchObject({ code: "DependencyViolation" });
+      }
+    });
   });
 
   describe("vpc_id reference", () => {
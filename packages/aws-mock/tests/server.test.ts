@@ -56,6 +56,123 @@ describe("mock backend server", () => {
     });
   });
 
+  describe("GET /resource/aws_s3_bucket", () => {
+    test("lists buckets matching the query", async () => {
+      for (const [bucket, env] of [["list-a", "prod"], ["list-b", "dev"]]) {
+        await app.request("/resource/aws_s3_bucket", {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ attributes: { bucket, force_destroy: env === "prod" } }),
+        });
+      }
+
+      const all = await (await app.request("/resource/aws_s3_bucket")).json();
+      expect(all.resources.map((r: { id: string }) => r.id).sort()).toEqual(["list-a", "list-b"]);
+
+      const res = await app.request("/resource/aws_s3_bucket?force_destroy=true");
+      expect(res.status).toBe(200);
+      const body = await res.json();
+      expect(body.resources.map((r: { id: string }) => r.id)).toEqual(["list-a"]);
+    });
+
+    test("returns 404 for unknown resource type", async () => {
+      const res = await app.request("/resource/aws_unknown_thing");
+
+      expect(res.status).toBe(404);
+    });
+  });
+
+  describe("POST /drift/aws_s3_bucket/:id", () => {
+    test("changes the bucket outside Terraform", async () => {
+      await app.request("/resource/aws_s3_bucket", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { bucket: "drift-bucket" } }),
+      });
+
+      const res = await app.request("/drift/aws_s3_bucket/drift-bucket", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { force_destroy: true } }),
+      });
+      expect(res.status).toBe(200);
+
+      const read = await (await app.request("/resource/aws_s3_bucket/drift-bucket")).json();
+      expect(read.attributes.force_destroy).toBe(true);
+    });
+
+    test("accepts drift scheduled for later", async () => {
+      await app.request("/resource/aws_s3_bucket", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { bucket: "later-bucket" } }),
+      });
+
+      const res = await app.request("/drift/aws_s3_bucket/later-bucket", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({
+          attributes: { force_destroy: true },
+          apply_at: new Date(Date.now() + 3600_000).toISOString(),
+        }),
+      });
+      expect(res.status).toBe(202);
+
+      const read = await (await app.request("/resource/aws_s3_bucket/later-bucket")).json();
+      expect(read.attributes.force_destroy).toBe(false);
+    });
+
+    test("returns 404 for a missing resource", async () => {
+      const res = await app.request("/drift/aws_s3_bucket/ghost", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { force_destroy: true } }),
+      });
+
+      expect(res.status).toBe(404);
+    });
+  });
+
+  describe("POST /resources/batch-read", () => {
+    test("reads many resources in one request", async () => {
+      await app.request("/resource/aws_s3_bucket", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { bucket: "batch-bucket" } }),
+      });
+
+      const res = await app.request("/resources/batch-read", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({
+          resources: [
+            { type: "aws_s3_bucket", id: "batch-bucket" },
+            { type: "aws_s3_bucket", id: "no-such-bucket" },
+            { type: "aws_not_a_type", id: "x" },
+          ],
+        }),
+      });
+
+      expect(res.status).toBe(200);
+      const body = await res.json();
+      expect(body.resources).toHaveLength(3);
+      expect(body.resources[0]).toMatchObject({ type: "aws_s3_bucket", id: "batch-bucket", found: true, version: "1" });
+      expect(body.resources[0].attributes.arn).toBe("arn:aws:s3:::batch-bucket");
+      expect(body.resources[1]).toMatchObject({ id: "no-such-bucket", found: false });
+      expect(body.resources[2]).toMatchObject({ type: "aws_not_a_type", found: false });
+    });
+
+    test("rejects a body without resources", async () => {
+      const res = await app.request("/resources/batch-read", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({}),
+      });
+
+      expect(res.status).toBe(400);
+    });
+  });
+
   describe("PUT /resource/aws_s3_bucket/:id", () => {
     test("updates bucket configuration", async () => {
       await app.request("/resource/aws_s3_bucket", {
@@ -79,6 +196,74 @@ describe("mock backend server", () => {
     });
   });
 
+  describe("If-Match on /resource/aws_s3_bucket/:id", () => {
+    const create = () =>
+      app.request("/resource/aws_s3_bucket", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { bucket: "shared-bucket" } }),
+      });
+    const put = (ifMatch: string, tags: Record<string, string>) =>
+      app.request("/resource/aws_s3_bucket/shared-bucket", {
+        method: "PUT",
+        headers: { "Content-Type": "application/json", "If-Match": ifMatch },
+        body: JSON.stringify({ attributes: { bucket: "shared-bucket", tags } }),
+      });
+
+    test("returns the version as an ETag", async () => {
+      const created = await create();
+      expect(created.headers.get("ETag")).toBe('"1"');
+      expect((await created.json()).version).toBe("1");
+
+      const read = await app.request("/resource/aws_s3_bucket/shared-bucket");
+      expect(read.headers.get("ETag")).toBe('"1"');
+
+      const updated = await put('"1"', { env: "prod" });
+      expect(updated.status).toBe(200);
+      expect(updated.headers.get("ETag")).toBe('"2"');
+    });
+
+    test("rejects the second of two writers holding the same version", async () => {
+      const etag = (await create()).headers.get("ETag")!;
+
+      const [first, second] = await Promise.all([put(etag, { writer: "first" }), put(etag, { writer: "second" })]);
+
+      expect([first.status, second.status].sort()).toEqual([200, 412]);
+      const winner = first.status === 200 ? "first" : "second";
+      const read = await app.request("/resource/aws_s3_bucket/shared-bucket");
+      expect((await read.json()).attributes.tags).toEqual({ writer: winner });
+    });
+
+    test("rejects a delete at a stale version", async () => {
+      const etag = (await create()).headers.get("ETag")!;
+      await put(etag, { env: "prod" });
+
+      const stale = await app.request("/resource/aws_s3_bucket/shared-bucket", {
+        method: "DELETE",
+        headers: { "If-Match": etag },
+      });
+      expect(stale.status).toBe(412);
+
+      const current = await app.request("/resource/aws_s3_bucket/shared-bucket", {
+        method: "DELETE",
+        headers: { "If-Match": '"2"' },
+      });
+      expect(current.status).toBe(204);
+    });
+
+    test("treats drift as a change", async () => {
+      const etag = (await create()).headers.get("ETag")!;
+      await app.request("/drift/aws_s3_bucket/shared-bucket", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { tags: { owner: "console" } } }),
+      });
+
+      const res = await put(etag, { env: "prod" });
+      expect(res.status).toBe(412);
+    });
+  });
+
   describe("DELETE /resource/aws_s3_bucket/:id", () => {
     test("deletes bucket from state", async () => {
       await app.request("/resource/aws_s3_bucket", {
@@ -96,6 +281,30 @@ describe("mock backend server", () => {
     });
   });
 
+  describe("GET /tombstone/aws_s3_bucket/:id", () => {
+    test("returns when a deleted bucket was deleted", async () => {
+      await app.request("/resource/aws_s3_bucket", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { bucket: "gone-bucket" } }),
+      });
+      await app.request("/resource/aws_s3_bucket/gone-bucket", { method: "DELETE" });
+
+      const res = await app.request("/tombstone/aws_s3_bucket/gone-bucket");
+
+      expect(res.status).toBe(200);
+      const body = await res.json();
+      expect(body.id).toBe("gone-bucket");
+      expect(Number.isNaN(Date.parse(body.deleted_at))).toBe(false);
+    });
+
+    test("returns 404 for a bucket that never existed", async () => {
+      const res = await app.request("/tombstone/aws_s3_bucket/no-such-bucket");
+
+      expect(res.status).toBe(404);
+    });
+  });
+
   describe("aws_s3_bucket_policy routes", () => {
     test("CRUD operations work for bucket policy", async () => {
       // Create
@@ -263,6 +472,33 @@ describe("mock backend server", () => {
     });
   });
 
+  describe("DELETE with dependents", () => {
+    test("refuses to delete a VPC with subnets, with DependencyViolation", async () => {
+      const vpc = await app.request("/resource/aws_vpc", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { cidr_block: "10.0.0.0/16" } }),
+      });
+      const vpcId = (await vpc.json()).id;
+      const subnet = await app.request("/resource/aws_subnet", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ attributes: { vpc_id: vpcId, cidr_block: "10.0.1.0/24" } }),
+      });
+      const subnetId = (await subnet.json()).id;
+
+      const res = await app.request(`/resource/aws_vpc/${vpcId}`, { method: "DELETE" });
+
+      expect(res.status).toBe(400);
+      expect((await res.json()).code).toBe("DependencyViolation");
+      expect((await app.request(`/resource/aws_vpc/${vpcId}`)).status).toBe(200);
+
+      await app.request(`/resource/aws_subnet/${subnetId}`, { method: "DELETE" });
+      const retry = await app.request(`/resource/aws_vpc/${vpcId}`, { method: "DELETE" });
+      expect(retry.status).toBe(204);
+    });
+  });
+
   describe("error handling", () => {
     test("returns 400 for missing attributes on POST", async () => {
       const res = await app.request("/resource/aws_s3_bucket", {
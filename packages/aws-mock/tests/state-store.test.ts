@@ -2,7 +2,7 @@ import { describe, expect, test, beforeEach, afterEach } from "bun:test";
 import { mkdtemp, rm, readFile } from "node:fs/promises";
 import { join } from "node:path";
 import { tmpdir } from "node:os";
-import { StateStore } from "../src/state/store";
+import { MAX_TOMBSTONES, StateStore } from "../src/state/store";
 
 describe("StateStore", () => {
   let tempDir: string;
@@ -32,6 +32,7 @@ describe("StateStore", () => {
           bucket: "my-bucket",
           arn: "arn:aws:s3:::my-bucket",
         },
+        version: 1,
       });
     });
 
@@ -70,6 +71,7 @@ describe("StateStore", () => {
           bucket: "my-bucket",
           arn: "arn:aws:s3:::my-bucket",
         },
+        version: 1,
       });
     });
 
@@ -84,6 +86,36 @@ describe("StateStore", () => {
     });
   });
 
+  describe("readResources", () => {
+    test("returns each resource, or null when missing", async () => {
+      await store.createResource("aws_s3_bucket", "b1", { bucket: "b1" });
+      await store.createResource("aws_vpc", "vpc-1", { cidr_block: "10.0.0.0/16" });
+
+      const results = await store.readResources([
+        { type: "aws_vpc", id: "vpc-1" },
+        { type: "aws_s3_bucket", id: "missing" },
+        { type: "aws_s3_bucket", id: "b1" },
+      ]);
+
+      expect(results.map((r) => r?.id ?? null)).toEqual(["vpc-1", null, "b1"]);
+    });
+  });
+
+  describe("listResources", () => {
+    test("returns every resource of a type", async () => {
+      await store.createResource("aws_s3_bucket", "b1", { bucket: "b1" });
+      await store.createResource("aws_s3_bucket", "b2", { bucket: "b2" });
+      await store.createResource("aws_vpc", "vpc-1", { cidr_block: "10.0.0.0/16" });
+
+      const result = await store.listResources("aws_s3_bucket");
+      expect(result.map((r) => r.id).sort()).toEqual(["b1", "b2"]);
+    });
+
+    test("returns an empty list for a type with no resources", async () => {
+      expect(await store.listResources("aws_nonexistent")).toEqual([]);
+    });
+  });
+
   describe("updateResource", () => {
     test("modifies existing resource", async () => {
       await store.createResource("aws_s3_bucket", "my-bucket", {
@@ -111,11 +143,66 @@ describe("StateStore", () => {
       expect(() => JSON.parse(raw)).not.toThrow();
     });
 
+    test("bumps the version", async () => {
+      await store.createResource("aws_s3_bucket", "b1", { bucket: "b1" });
+      await store.updateResource("aws_s3_bucket", "b1", { bucket: "b1", tags: { a: "1" } });
+
+      expect(await store.resourceVersion("aws_s3_bucket", "b1")).toBe(2);
+    });
+
     test("throws when updating non-existent resource", async () => {
       expect(store.updateResource("aws_s3_bucket", "ghost", { bucket: "ghost" })).rejects.toThrow();
     });
   });
 
+  describe("driftResource", () => {
+    test("changes attributes immediately", async () => {
+      await store.createResource("aws_vpc", "vpc-1", {
+        cidr_block: "10.0.0.0/16",
+        tags: {},
+        tags_all: {},
+      });
+
+      await store.driftResource("aws_vpc", "vpc-1", { tags: { Owner: "console" } });
+
+      const result = await store.readResource("aws_vpc", "vpc-1");
+      expect(result!.attributes.cidr_block).toBe("10.0.0.0/16");
+      expect(result!.attributes.tags).toEqual({ Owner: "console" });
+      expect(result!.attributes.tags_all).toEqual({ Owner: "console" });
+    });
+
+    test("holds scheduled drift until its time", async () => {
+      let now = new Date("2024-01-01T00:00:00Z");
+      const clocked = new StateStore(join(tempDir, "clocked.json"), () => now);
+      await clocked.createResource("aws_vpc", "vpc-1", { enable_dns_support: true });
+
+      await clocked.driftResource(
+        "aws_vpc",
+        "vpc-1",
+        { enable_dns_support: false },
+        new Date("2024-01-01T00:10:00Z"),
+      );
+      expect((await clocked.readResource("aws_vpc", "vpc-1"))!.attributes.enable_dns_support).toBe(true);
+
+      now = new Date("2024-01-01T00:10:00Z");
+      expect((await clocked.readResource("aws_vpc", "vpc-1"))!.attributes.enable_dns_support).toBe(false);
+
+      const raw = JSON.parse(await readFile(join(tempDir, "clocked.json"), "utf-8"));
+      expect(raw.drift).toEqual([]);
+    });
+
+    test("bumps the version when the drift applies", async () => {
+      await store.createResource("aws_s3_bucket", "b1", { bucket: "b1" });
+      await store.driftResource("aws_s3_bucket", "b1", { tags: { owner: "console" } });
+
+      expect(await store.resourceVersion("aws_s3_bucket", "b1")).toBe(2);
+    });
+
+    test("throws when drifting non-existent resource", async () => {
+      expect(store.driftResource("aws_vpc", "ghost", { a: 1 })).rejects.toThrow();
+    });
+  });
+
   describe("deleteResource", () => {
     test("removes resource from state", async () => {
       await store.createResource("aws_s3_bucket", "my-bucket", {
@@ -140,4 +227,44 @@ describe("StateStore", () => {
       expect(store.deleteResource("aws_s3_bucket", "ghost")).rejects.toThrow();
     });
   });
+
+  describe("readTombstone", () => {
+    test("records when a resource was deleted", async () => {
+      const deletedAt = new Date("2026-01-01T00:00:00Z");
+      store = new StateStore(join(tempDir, "state.json"), () => deletedAt);
+      await store.createResource("aws_vpc", "vpc-1", { cidr_block: "10.0.0.0/16" });
+      await store.deleteResource("aws_vpc", "vpc-1");
+
+      const tombstone = await store.readTombstone("aws_vpc", "vpc-1");
+      expect(tombstone).toEqual({ deleted_at: deletedAt.toISOString() });
+    });
+
+    test("returns null for a resource never held", async () => {
+      expect(await store.readTombstone("aws_vpc", "vpc-ghost")).toBeNull();
+    });
+
+    test("forgets the deletion when the ID is created again", async () => {
+      await store.createResource("aws_s3_bucket", "b1", { bucket: "b1" });
+      await store.deleteResource("aws_s3_bucket", "b1");
+      await store.createResource("aws_s3_bucket", "b1", { bucket: "b1" });
+
+      expect(await store.readTombstone("aws_s3_bucket", "b1")).toBeNull();
+    });
+
+    test("keeps only the newest tombstones of a type", async () => {
+      let tick = Date.parse("2026-01-01T00:00:00Z");
+      store = new StateStore(join(tempDir, "state.json"), () => new Date(tick++));
+      for (let i = 0; i <= MAX_TOMBSTONES; i++) {
+        await store.createResource("aws_vpc", `vpc-${i}`, {});
+        await store.deleteResource("aws_vpc", `vpc-${i}`);
+      }
+
+      expect(await store.readTombstone("aws_vpc", "vpc-0")).toBeNull();
+      expect(await store.readTombstone("aws_vpc", "vpc-1")).not.toBeNull();
+      expect(await store.readTombstone("aws_vpc", `vpc-${MAX_TOMBSTONES}`)).not.toBeNull();
+
+      const state = JSON.parse(await readFile(join(tempDir, "state.json"), "utf-8"));
+      expect(Object.keys(state.tombstones.aws_vpc)).toHaveLength(MAX_TOMBSTONES);
+    });
+  });
 });
@@ -136,6 +136,22 @@ describe("aws_vpc handler", () => {
       const result = await store.readResource("aws_vpc", created.id);
       expect(result).toBeNull();
     });
+
+    test("refuses while subnets remain, with DependencyViolation", async () => {
+      const created = await handler.create({
+        resourceType: "aws_vpc",
+        attributes: { cidr_block: "10.0.0.0/16" },
+      });
+      await store.createResource("aws_subnet", "subnet-abc123", { vpc_id: created.id });
+
+      const deleting = handler.delete({ resourceType: "aws_vpc", attributes: {}, id: created.id });
+      await expect(deleting).rejects.toMatchObject({ code: "DependencyViolation" });
+      expect(await store.readResource("aws_vpc", created.id)).not.toBeNull();
+
+      await store.deleteResource("aws_subnet", "subnet-abc123");
+      await handler.delete({ resourceType: "aws_vpc", attributes: {}, id: created.id });
+      expect(await store.readResource("aws_vpc", created.id)).toBeNull();
+    });
   });
 
   describe("optional arguments", () => {
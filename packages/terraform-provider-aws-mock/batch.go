@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// maxBatchReadSize caps how many resources go into one batch request.
+const maxBatchReadSize = 100
+
+// ResourceRef addresses one resource in the backend.
+type ResourceRef struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type batchReadItem struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Found      bool                   `json:"found"`
+	Attributes map[string]interface{} `json:"attributes"`
+	Version    string                 `json:"version,omitempty"`
+}
+
+// errBatchUnsupported means the backend has no batch-read endpoint.
+var errBatchUnsupported = errors.New("backend does not support batch reads")
+
+// ReadResources reads many resources of many types in one request per
+// backend endpoint. Resources that don't exist map to nil, as with
+// ReadResource.
+func (c *MockClient) ReadResources(refs []ResourceRef) (map[ResourceRef]*ResourceResponse, error) {
+	byURL := make(map[string][]ResourceRef)
+	for _, ref := range refs {
+		base := c.resourceURL(ref.Type)
+		byURL[base] = append(byURL[base], ref)
+	}
+
+	results := make(map[ResourceRef]*ResourceResponse, len(refs))
+	for base, group := range byURL {
+		if err := c.readResourceBatch(base, group, results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (c *MockClient) readResourceBatch(baseURL string, refs []ResourceRef, results map[ResourceRef]*ResourceResponse) error {
+	body, err := json.Marshal(map[string]interface{}{"resources": refs})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Post(
+		fmt.Sprintf("%s/resources/batch-read", baseURL),
+		"application/json",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 || resp.StatusCode == 405 {
+		return errBatchUnsupported
+	}
+	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("batch read failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var batch struct {
+		Resources []batchReadItem `json:"resources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		results[ref] = nil
+	}
+	for _, item := range batch.Resources {
+		if !item.Found {
+			continue
+		}
+		results[ResourceRef{Type: item.Type, ID: item.ID}] = &ResourceResponse{
+			ID:         item.ID,
+			Attributes: item.Attributes,
+			Version:    item.Version,
+		}
+	}
+	return nil
+}
+
+// ReadResourceBatched reads a resource through the client's read batcher,
+// so concurrent refreshes share a round-trip. Without a batcher it is the
+// same as ReadResource.
+func (c *MockClient) ReadResourceBatched(resourceType, id string) (*ResourceResponse, error) {
+	if c.batcher == nil {
+		return c.ReadResource(resourceType, id)
+	}
+	return c.batcher.read(ResourceRef{Type: resourceType, ID: id})
+}
+
+// ListResourcesBatched lists the resources of resourceType matching filters
+// through the client's read batcher: callers within one batch window share
+// a single unfiltered list of the type and filter it themselves, so a
+// refresh that needs a list per resource costs one round trip per type.
+// Without a batcher it is the same as ListResources.
+func (c *MockClient) ListResourcesBatched(resourceType string, filters map[string]string) ([]ResourceResponse, error) {
+	if c.batcher == nil {
+		return c.ListResources(resourceType, filters)
+	}
+	all, err := c.batcher.list(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	var matched []ResourceResponse
+	for _, resource := range all {
+		if matchesFilters(resource.Attributes, filters) {
+			matched = append(matched, resource)
+		}
+	}
+	return matched, nil
+}
+
+// matchesFilters reports whether attributes equal every filter, compared as
+// the backend compares list query parameters: as strings.
+func matchesFilters(attributes map[string]interface{}, filters map[string]string) bool {
+	for key, value := range filters {
+		if attr, ok := attributes[key]; !ok || fmt.Sprint(attr) != value {
+			return false
+		}
+	}
+	return true
+}
+
+type pendingRead struct {
+	ref    ResourceRef
+	result *ResourceResponse
+	err    error
+	done   chan struct{}
+}
+
+// readBatcher collects reads issued within a short window and sends them
+// as a single ReadResources call.
+type readBatcher struct {
+	client *MockClient
+	window time.Duration
+
+	mu          sync.Mutex
+	pending     []*pendingRead
+	timer       *time.Timer
+	unsupported bool
+
+	// lists holds, per resource type, the list shared by callers until a
+	// window after it completes.
+	lists map[string]*pendingList
+}
+
+type pendingList struct {
+	result []ResourceResponse
+	err    error
+	done   chan struct{}
+}
+
+func newReadBatcher(client *MockClient, window time.Duration) *readBatcher {
+	return &readBatcher{client: client, window: window}
+}
+
+func (b *readBatcher) read(ref ResourceRef) (*ResourceResponse, error) {
+	b.mu.Lock()
+	if b.unsupported {
+		b.mu.Unlock()
+		return b.client.ReadResource(ref.Type, ref.ID)
+	}
+
+	p := &pendingRead{ref: ref, done: make(chan struct{})}
+	b.pending = append(b.pending, p)
+
+	var batch []*pendingRead
+	if len(b.pending) >= maxBatchReadSize {
+		batch = b.take()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.window, b.flush)
+	}
+	b.mu.Unlock()
+
+	if batch != nil {
+		b.send(batch)
+	}
+
+	<-p.done
+	return p.result, p.err
+}
+
+// list returns every resource of resourceType. The first caller sends the
+// request; callers arriving before it completes, or within a window after,
+// share its result.
+func (b *readBatcher) list(resourceType string) ([]ResourceResponse, error) {
+	b.mu.Lock()
+	p, shared := b.lists[resourceType]
+	if !shared {
+		p = &pendingList{done: make(chan struct{})}
+		if b.lists == nil {
+			b.lists = make(map[string]*pendingList)
+		}
+		b.lists[resourceType] = p
+	}
+	b.mu.Unlock()
+
+	if !shared {
+		p.result, p.err = b.client.ListResources(resourceType, nil)
+		close(p.done)
+		time.AfterFunc(b.window, func() {
+			b.mu.Lock()
+			if b.lists[resourceType] == p {
+				delete(b.lists, resourceType)
+			}
+			b.mu.Unlock()
+		})
+	}
+
+	<-p.done
+	return p.result, p.err
+}
+
+// take detaches the pending reads. The caller must hold b.mu.
+func (b *readBatcher) take() []*pendingRead {
+	batch := b.pending
+	b.pending = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return batch
+}
+
+func (b *readBatcher) flush() {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+
+	if len(batch) > 0 {
+		b.send(batch)
+	}
+}
+
+func (b *readBatcher) send(batch []*pendingRead) {
+	refs := make([]ResourceRef, 0, len(batch))
+	seen := make(map[ResourceRef]bool, len(batch))
+	for _, p := range batch {
+		if !seen[p.ref] {
+			seen[p.ref] = true
+			refs = append(refs, p.ref)
+		}
+	}
+
+	results, err := b.client.ReadResources(refs)
+	if err == errBatchUnsupported {
+		b.mu.Lock()
+		b.unsupported = true
+		b.mu.Unlock()
+
+		for _, p := range batch {
+			p.result, p.err = b.client.ReadResource(p.ref.Type, p.ref.ID)
+			close(p.done)
+		}
+		return
+	}
+
+	for _, p := range batch {
+		if err != nil {
+			p.err = err
+		} else {
+			p.result = results[p.ref]
+		}
+		close(p.done)
+	}
+}
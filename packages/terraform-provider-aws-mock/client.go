@@ -3,29 +3,150 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 )
 
 type MockClient struct {
 	BackendURL string
 	HTTPClient *http.Client
+
+	// Endpoints overrides BackendURL per AWS service (e.g. "s3", "ec2").
+	Endpoints map[string]string
+
+	// Region is the region passed to ConfigureProvider.
+	Region string
+
+	batcher *readBatcher
+	ips     ipAllocator
 }
 
 type ResourceResponse struct {
 	ID         string                 `json:"id"`
 	Attributes map[string]interface{} `json:"attributes"`
+
+	// Version is the backend's resource version, taken from the ETag
+	// response header when present.
+	Version string `json:"version,omitempty"`
 }
 
+// PreconditionFailedError is returned when the backend rejects an
+// If-Match write because the resource has changed since it was last read.
+type PreconditionFailedError struct {
+	ResourceType string
+	ID           string
+	Version      string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("%s %s changed outside Terraform (expected version %s)", e.ResourceType, e.ID, e.Version)
+}
+
+// APIError is a non-success response from the backend. Code carries the
+// AWS-style error code (e.g. "DependencyViolation") when the backend sends one.
+type APIError struct {
+	Operation  string
+	StatusCode int
+	Code       string
+	Message    string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s failed with status %d: %s", e.Operation, e.StatusCode, e.Body)
+}
+
+// newAPIError builds an APIError from a failed response, reading the
+// backend's {"error": ..., "code": ...} body when it has one.
+func newAPIError(operation string, resp *http.Response) *APIError {
+	respBody, _ := io.ReadAll(resp.Body)
+	apiErr := &APIError{
+		Operation:  operation,
+		StatusCode: resp.StatusCode,
+		Body:       string(respBody),
+	}
+
+	var errResp struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if json.Unmarshal(respBody, &errResp) == nil {
+		apiErr.Code = errResp.Code
+		apiErr.Message = errResp.Error
+	}
+	return apiErr
+}
+
+// hasErrorCode reports whether err is a backend error with the given code.
+// Backends that only send a message are matched on a "Code:" prefix.
+func hasErrorCode(err error, code string) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == code || strings.HasPrefix(apiErr.Message, code+":")
+}
+
+// decodeResourceResponse reads a resource body and picks up its version from
+// the ETag header.
+func decodeResourceResponse(resp *http.Response) (*ResourceResponse, error) {
+	var result ResourceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		result.Version = strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+	}
+	return &result, nil
+}
+
+// ifMatch formats a resource version for the If-Match request header.
+func ifMatch(version string) string {
+	return `"` + version + `"`
+}
+
+// ConfigureProvider sends the region to the backend and to every distinct
+// per-service endpoint, so a bad region or an unreachable endpoint override
+// fails at configure time rather than on the first call to that service.
 func (c *MockClient) ConfigureProvider(region string) error {
 	body, err := json.Marshal(map[string]string{"region": region})
 	if err != nil {
 		return err
 	}
 
+	if err := c.configureEndpoint(c.BackendURL, body); err != nil {
+		return err
+	}
+
+	services := make([]string, 0, len(c.Endpoints))
+	for service := range c.Endpoints {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	configured := map[string]bool{c.BackendURL: true}
+	for _, service := range services {
+		endpoint := c.Endpoints[service]
+		if configured[endpoint] {
+			continue
+		}
+		configured[endpoint] = true
+		if err := c.configureEndpoint(endpoint, body); err != nil {
+			return fmt.Errorf("%s endpoint %s: %w", service, endpoint, err)
+		}
+	}
+	return nil
+}
+
+func (c *MockClient) configureEndpoint(baseURL string, body []byte) error {
 	resp, err := c.HTTPClient.Post(
-		fmt.Sprintf("%s/provider/configure", c.BackendURL),
+		fmt.Sprintf("%s/provider/configure", baseURL),
 		"application/json",
 		bytes.NewReader(body),
 	)
@@ -47,6 +168,15 @@ func (c *MockClient) ConfigureProvider(region string) error {
 	return nil
 }
 
+// resourceURL returns the base URL serving resourceType, honouring any
+// per-service endpoint override.
+func (c *MockClient) resourceURL(resourceType string) string {
+	if endpoint, ok := c.Endpoints[serviceForResourceType(resourceType)]; ok {
+		return endpoint
+	}
+	return c.BackendURL
+}
+
 func (c *MockClient) CreateResource(resourceType string, attrs map[string]interface{}) (*ResourceResponse, error) {
 	body, err := json.Marshal(map[string]interface{}{"attributes": attrs})
 	if err != nil {
@@ -54,7 +184,7 @@ func (c *MockClient) CreateResource(resourceType string, attrs map[string]interf
 	}
 
 	resp, err := c.HTTPClient.Post(
-		fmt.Sprintf("%s/resource/%s", c.BackendURL, resourceType),
+		fmt.Sprintf("%s/resource/%s", c.resourceURL(resourceType), resourceType),
 		"application/json",
 		bytes.NewReader(body),
 	)
@@ -64,20 +194,15 @@ func (c *MockClient) CreateResource(resourceType string, attrs map[string]interf
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 201 {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("create failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError("create", resp)
 	}
 
-	var result ResourceResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return decodeResourceResponse(resp)
 }
 
 func (c *MockClient) ReadResource(resourceType, id string) (*ResourceResponse, error) {
 	resp, err := c.HTTPClient.Get(
-		fmt.Sprintf("%s/resource/%s/%s", c.BackendURL, resourceType, id),
+		fmt.Sprintf("%s/resource/%s/%s", c.resourceURL(resourceType), resourceType, id),
 	)
 	if err != nil {
 		return nil, err
@@ -88,31 +213,35 @@ func (c *MockClient) ReadResource(resourceType, id string) (*ResourceResponse, e
 		return nil, nil
 	}
 	if resp.StatusCode != 200 {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("read failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError("read", resp)
 	}
 
-	var result ResourceResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return decodeResourceResponse(resp)
 }
 
 func (c *MockClient) UpdateResource(resourceType, id string, attrs map[string]interface{}) (*ResourceResponse, error) {
+	return c.UpdateResourceIfMatch(resourceType, id, "", attrs)
+}
+
+// UpdateResourceIfMatch updates a resource only if the backend still holds
+// version. An empty version sends a blind write.
+func (c *MockClient) UpdateResourceIfMatch(resourceType, id, version string, attrs map[string]interface{}) (*ResourceResponse, error) {
 	body, err := json.Marshal(map[string]interface{}{"attributes": attrs})
 	if err != nil {
 		return nil, err
 	}
 
 	req, err := http.NewRequest("PUT",
-		fmt.Sprintf("%s/resource/%s/%s", c.BackendURL, resourceType, id),
+		fmt.Sprintf("%s/resource/%s/%s", c.resourceURL(resourceType), resourceType, id),
 		bytes.NewReader(body),
 	)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if version != "" {
+		req.Header.Set("If-Match", ifMatch(version))
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -120,26 +249,33 @@ func (c *MockClient) UpdateResource(resourceType, id string, attrs map[string]in
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == 412 {
+		return nil, &PreconditionFailedError{ResourceType: resourceType, ID: id, Version: version}
+	}
 	if resp.StatusCode != 200 {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("update failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError("update", resp)
 	}
 
-	var result ResourceResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return decodeResourceResponse(resp)
 }
 
 func (c *MockClient) DeleteResource(resourceType, id string) error {
+	return c.DeleteResourceIfMatch(resourceType, id, "")
+}
+
+// DeleteResourceIfMatch deletes a resource only if the backend still holds
+// version. An empty version sends a blind delete.
+func (c *MockClient) DeleteResourceIfMatch(resourceType, id, version string) error {
 	req, err := http.NewRequest("DELETE",
-		fmt.Sprintf("%s/resource/%s/%s", c.BackendURL, resourceType, id),
+		fmt.Sprintf("%s/resource/%s/%s", c.resourceURL(resourceType), resourceType, id),
 		nil,
 	)
 	if err != nil {
 		return err
 	}
+	if version != "" {
+		req.Header.Set("If-Match", ifMatch(version))
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -147,9 +283,110 @@ func (c *MockClient) DeleteResource(resourceType, id string) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == 412 {
+		return &PreconditionFailedError{ResourceType: resourceType, ID: id, Version: version}
+	}
 	if resp.StatusCode != 204 {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("delete failed with status %d: %s", resp.StatusCode, string(respBody))
+		return newAPIError("delete", resp)
 	}
 	return nil
 }
+
+// DriftResource changes a resource's attributes in the backend as if
+// someone had edited it outside Terraform. A zero applyAt drifts it now;
+// otherwise the backend holds the change until applyAt.
+func (c *MockClient) DriftResource(resourceType, id string, attrs map[string]interface{}, applyAt time.Time) error {
+	payload := map[string]interface{}{"attributes": attrs}
+	if !applyAt.IsZero() {
+		payload["apply_at"] = applyAt.UTC().Format(time.RFC3339)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Post(
+		fmt.Sprintf("%s/drift/%s/%s", c.resourceURL(resourceType), resourceType, id),
+		"application/json",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 202 {
+		return newAPIError("drift", resp)
+	}
+	return nil
+}
+
+// Tombstone is the backend's record of a deleted resource.
+type Tombstone struct {
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// ReadTombstone returns when the backend deleted a resource, or nil if it
+// has no record of deleting it: the ID never existed, was created again, or
+// the backend predates tombstones.
+func (c *MockClient) ReadTombstone(resourceType, id string) (*Tombstone, error) {
+	resp, err := c.HTTPClient.Get(
+		fmt.Sprintf("%s/tombstone/%s/%s", c.resourceURL(resourceType), resourceType, id),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, newAPIError("tombstone", resp)
+	}
+
+	var result Tombstone
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListResources returns the resources of resourceType whose attributes match
+// every filter. A backend without the listing endpoint, or without
+// resourceType, is an error rather than an empty list: callers count what
+// the list returns to enforce limits and refuse conflicting deletes.
+func (c *MockClient) ListResources(resourceType string, filters map[string]string) ([]ResourceResponse, error) {
+	query := url.Values{}
+	for key, value := range filters {
+		query.Set(key, value)
+	}
+
+	listURL := fmt.Sprintf("%s/resource/%s", c.resourceURL(resourceType), resourceType)
+	if len(query) > 0 {
+		listURL += "?" + query.Encode()
+	}
+
+	resp, err := c.HTTPClient.Get(listURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, newAPIError("list", resp)
+	}
+
+	var result struct {
+		Resources []ResourceResponse `json:"resources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Resources, nil
+}
+
+// ListBucketObjects returns the aws_s3_object resources stored in bucket.
+func (c *MockClient) ListBucketObjects(bucket string) ([]ResourceResponse, error) {
+	return c.ListResources("aws_s3_object", map[string]string{"bucket": bucket})
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestCreateResourceCallsPost(t *testing.T) {
@@ -245,6 +248,9 @@ func TestProviderConfigure(t *testing.T) {
 	if p.Schema["region"] == nil {
 		t.Fatal("provider should have region schema")
 	}
+	if p.Schema["endpoints"] == nil {
+		t.Fatal("provider should have endpoints schema")
+	}
 	if !p.Schema["region"].Required {
 		t.Error("region should be Required")
 	}
@@ -467,3 +473,363 @@ func TestDeleteEC2ResourceFromBackend(t *testing.T) {
 		})
 	}
 }
+
+// --- Per-service endpoint overrides ---
+
+func TestServiceForResourceType(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		expected     string
+	}{
+		{"aws_s3_bucket", "s3"},
+		{"aws_s3_bucket_policy", "s3"},
+		{"aws_vpc", "ec2"},
+		{"aws_subnet", "ec2"},
+		{"aws_security_group", "ec2"},
+		{"aws_instance", "ec2"},
+		{"aws_route_table", "ec2"},
+		{"aws_route53_zone", "route53"},
+		{"aws_cloudwatch_log_group", "logs"},
+		{"aws_cloudwatch_metric_alarm", "cloudwatch"},
+		{"aws_db_instance", "rds"},
+		{"aws_iam_role", "iam"},
+		{"aws_sqs_queue", "sqs"},
+		{"aws_glue_job", "glue"},
+	}
+
+	for _, tt := range tests {
+		if got := serviceForResourceType(tt.resourceType); got != tt.expected {
+			t.Errorf("serviceForResourceType(%s) = %s, want %s", tt.resourceType, got, tt.expected)
+		}
+	}
+}
+
+func TestEndpointOverrideRoutesByService(t *testing.T) {
+	var defaultPaths, s3Paths []string
+
+	defaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defaultPaths = append(defaultPaths, r.URL.Path)
+		w.WriteHeader(204)
+	}))
+	defer defaultServer.Close()
+
+	s3Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s3Paths = append(s3Paths, r.URL.Path)
+		w.WriteHeader(204)
+	}))
+	defer s3Server.Close()
+
+	client := &MockClient{
+		BackendURL: defaultServer.URL,
+		HTTPClient: defaultServer.Client(),
+		Endpoints:  map[string]string{"s3": s3Server.URL},
+	}
+
+	if err := client.DeleteResource("aws_s3_bucket", "my-bucket"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.DeleteResource("aws_vpc", "vpc-abc123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(s3Paths) != 1 || s3Paths[0] != "/resource/aws_s3_bucket/my-bucket" {
+		t.Errorf("expected aws_s3_bucket on the s3 endpoint, got %v", s3Paths)
+	}
+	if len(defaultPaths) != 1 || defaultPaths[0] != "/resource/aws_vpc/vpc-abc123" {
+		t.Errorf("expected aws_vpc on backend_url, got %v", defaultPaths)
+	}
+}
+
+func TestConfigureProviderChecksEndpointOverrides(t *testing.T) {
+	var configured []string
+	backend := func(name string, status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/provider/configure" {
+				configured = append(configured, name)
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"region": "us-east-1"})
+		}))
+	}
+	defaultServer := backend("default", 200)
+	defer defaultServer.Close()
+	s3Server := backend("s3", 200)
+	defer s3Server.Close()
+	brokenServer := backend("broken", 404)
+	defer brokenServer.Close()
+
+	client := &MockClient{
+		BackendURL: defaultServer.URL,
+		HTTPClient: defaultServer.Client(),
+		Endpoints:  map[string]string{"s3": s3Server.URL, "sqs": s3Server.URL, "ec2": defaultServer.URL},
+	}
+	if err := client.ConfigureProvider("us-east-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(configured, ",") != "default,s3" {
+		t.Errorf("configured %v, want each distinct endpoint once", configured)
+	}
+
+	client.Endpoints["sqs"] = brokenServer.URL
+	err := client.ConfigureProvider("us-east-1")
+	if err == nil || !strings.Contains(err.Error(), "sqs endpoint") {
+		t.Errorf("expected the broken sqs endpoint to fail configure, got %v", err)
+	}
+}
+
+func TestExpandEndpoints(t *testing.T) {
+	endpoints := expandEndpoints([]interface{}{
+		map[string]interface{}{
+			"s3":  "http://localhost:9000/",
+			"ec2": "",
+		},
+	})
+
+	if endpoints["s3"] != "http://localhost:9000" {
+		t.Errorf("expected trailing slash trimmed, got %q", endpoints["s3"])
+	}
+	if _, ok := endpoints["ec2"]; ok {
+		t.Error("empty endpoints should fall back to backend_url")
+	}
+	if len(expandEndpoints(nil)) != 0 {
+		t.Error("missing endpoints block should expand to an empty map")
+	}
+}
+
+// --- Optimistic concurrency ---
+
+func TestReadResourceReturnsETagVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `W/"7"`)
+		json.NewEncoder(w).Encode(ResourceResponse{ID: "vpc-abc123", Attributes: map[string]interface{}{}})
+	}))
+	defer server.Close()
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	result, err := client.ReadResource("aws_vpc", "vpc-abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Version != "7" {
+		t.Errorf("expected version 7 from ETag, got %q", result.Version)
+	}
+}
+
+func TestUpdateAndDeleteSendIfMatch(t *testing.T) {
+	var ifMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifMatch = append(ifMatch, r.Header.Get("If-Match"))
+		if r.Method == "DELETE" {
+			w.WriteHeader(204)
+			return
+		}
+		w.Header().Set("ETag", `"4"`)
+		json.NewEncoder(w).Encode(ResourceResponse{ID: "vpc-abc123", Attributes: map[string]interface{}{}})
+	}))
+	defer server.Close()
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+
+	result, err := client.UpdateResourceIfMatch("aws_vpc", "vpc-abc123", "3", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Version != "4" {
+		t.Errorf("expected new version 4, got %q", result.Version)
+	}
+	if err := client.DeleteResourceIfMatch("aws_vpc", "vpc-abc123", "4"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.DeleteResource("aws_vpc", "vpc-abc123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{`"3"`, `"4"`, ""}
+	for i, want := range expected {
+		if ifMatch[i] != want {
+			t.Errorf("request %d: If-Match = %q, want %q", i, ifMatch[i], want)
+		}
+	}
+}
+
+func TestPreconditionFailedReturnsTypedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(412)
+	}))
+	defer server.Close()
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+
+	_, err := client.UpdateResourceIfMatch("aws_vpc", "vpc-abc123", "3", map[string]interface{}{})
+	if _, ok := err.(*PreconditionFailedError); !ok {
+		t.Errorf("expected PreconditionFailedError from update, got %v", err)
+	}
+	err = client.DeleteResourceIfMatch("aws_vpc", "vpc-abc123", "3")
+	if _, ok := err.(*PreconditionFailedError); !ok {
+		t.Errorf("expected PreconditionFailedError from delete, got %v", err)
+	}
+}
+
+// --- Batch reads ---
+
+func TestReadResourcesPostsBatch(t *testing.T) {
+	var method, path string
+	var body struct {
+		Resources []ResourceRef `json:"resources"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resources": []map[string]interface{}{
+				{"type": "aws_vpc", "id": "vpc-abc123", "found": true, "version": "2", "attributes": map[string]interface{}{"cidr_block": "10.0.0.0/16"}},
+				{"type": "aws_subnet", "id": "subnet-gone", "found": false},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+
+	vpc := ResourceRef{Type: "aws_vpc", ID: "vpc-abc123"}
+	subnet := ResourceRef{Type: "aws_subnet", ID: "subnet-gone"}
+	results, err := client.ReadResources([]ResourceRef{vpc, subnet})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if method != "POST" || path != "/resources/batch-read" {
+		t.Errorf("expected POST /resources/batch-read, got %s %s", method, path)
+	}
+	if len(body.Resources) != 2 {
+		t.Errorf("expected 2 refs in request, got %d", len(body.Resources))
+	}
+	if results[vpc] == nil || results[vpc].Attributes["cidr_block"] != "10.0.0.0/16" || results[vpc].Version != "2" {
+		t.Errorf("unexpected vpc result: %+v", results[vpc])
+	}
+	if r, ok := results[subnet]; !ok || r != nil {
+		t.Errorf("missing resources should map to nil, got %+v (present=%v)", r, ok)
+	}
+}
+
+func TestReadBatcherCoalescesConcurrentReads(t *testing.T) {
+	var mu sync.Mutex
+	var batchCalls, singleCalls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path != "/resources/batch-read" {
+			singleCalls++
+			w.WriteHeader(500)
+			return
+		}
+		batchCalls++
+		var body struct {
+			Resources []ResourceRef `json:"resources"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		items := make([]map[string]interface{}, 0, len(body.Resources))
+		for _, ref := range body.Resources {
+			items = append(items, map[string]interface{}{"type": ref.Type, "id": ref.ID, "found": true, "attributes": map[string]interface{}{}})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"resources": items})
+	}))
+	defer server.Close()
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	client.batcher = newReadBatcher(client, 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("vpc-%d", i)
+			result, err := client.ReadResourceBatched("aws_vpc", id)
+			if err != nil {
+				errs <- err
+			} else if result == nil || result.ID != id {
+				errs <- fmt.Errorf("wrong result for %s: %+v", id, result)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if singleCalls != 0 {
+		t.Errorf("expected no single reads, got %d", singleCalls)
+	}
+	if batchCalls != 1 {
+		t.Errorf("expected reads to coalesce into 1 batch, got %d", batchCalls)
+	}
+}
+
+func TestReadBatcherFallsBackWithoutBatchEndpoint(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/resources/batch-read" {
+			w.WriteHeader(404)
+			return
+		}
+		json.NewEncoder(w).Encode(ResourceResponse{ID: "vpc-abc123", Attributes: map[string]interface{}{}})
+	}))
+	defer server.Close()
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	client.batcher = newReadBatcher(client, time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		result, err := client.ReadResourceBatched("aws_vpc", "vpc-abc123")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil || result.ID != "vpc-abc123" {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+	}
+
+	expected := []string{"/resources/batch-read", "/resource/aws_vpc/vpc-abc123", "/resource/aws_vpc/vpc-abc123"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected one batch probe then single reads, got %v", paths)
+	}
+}
+
+// --- Backend error codes ---
+
+func TestDeleteResourceReturnsAPIErrorCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(map[string]string{
+			"code":  "DependencyViolation",
+			"error": "The vpc 'vpc-abc123' has dependencies and cannot be deleted.",
+		})
+	}))
+	defer server.Close()
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	err := client.DeleteResource("aws_vpc", "vpc-abc123")
+	if !hasErrorCode(err, "DependencyViolation") {
+		t.Fatalf("expected DependencyViolation, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "delete failed with status 400") {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+}
+
+func TestHasErrorCodeMatchesMessagePrefix(t *testing.T) {
+	err := &APIError{Operation: "delete", StatusCode: 400, Message: "DependencyViolation: subnet-1 depends on vpc-1"}
+	if !hasErrorCode(err, "DependencyViolation") {
+		t.Error("expected code to be read from the message prefix")
+	}
+	if hasErrorCode(err, "BucketNotEmpty") {
+		t.Error("unexpected match for a different code")
+	}
+}
@@ -0,0 +1,71 @@
+// Command generate-schema writes the schema_<type>.go files of the given
+// resource types from the provider schema JSON. Run it through go generate
+// in the provider's directory.
+//
+//	go run ./cmd/generate-schema [-schema file] [-dir dir] aws_vpc aws_subnet ...
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"terraform-provider-aws-mock/internal/providerschema"
+	"terraform-provider-aws-mock/internal/schemagen"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("generate-schema: ")
+
+	schemaPath := flag.String("schema", "schema/aws-provider-schema.json", "provider schema JSON, from terraform providers schema -json")
+	dir := flag.String("dir", ".", "directory holding the schema files")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: generate-schema [-schema file] [-dir dir] resource_type...")
+	}
+
+	raw, err := os.ReadFile(*schemaPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	schemas, err := providerschema.Parse(raw)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, resourceType := range flag.Args() {
+		if err := generate(*dir, resourceType, schemas); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// generate rewrites resourceType's schema file, leaving it untouched when
+// nothing changed.
+func generate(dir, resourceType string, schemas map[string]providerschema.Resource) error {
+	resource, ok := schemas[resourceType]
+	if !ok {
+		return fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+
+	path := filepath.Join(dir, schemagen.FileName(resourceType))
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	out, err := schemagen.Generate(resourceType, resource.Block, existing)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(out, existing) {
+		return nil
+	}
+	return os.WriteFile(path, out, 0o644)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// computedDependencies maps, per resource type, arguments to the computed
+// attributes the backend recomputes from them on update. Without this a plan
+// that changes e.g. bucket shows the old arn instead of (known after apply).
+// Arguments that force replacement, such as a VPC's cidr_block, need no
+// entry: the SDK plans every computed attribute of a replacement unknown.
+var computedDependencies = map[string]map[string][]string{
+	"aws_s3_bucket": {
+		"bucket":        {"arn", "bucket_domain_name", "bucket_regional_domain_name", "website_domain", "website_endpoint"},
+		"bucket_prefix": {"arn", "bucket", "bucket_domain_name", "bucket_regional_domain_name", "website_domain", "website_endpoint"},
+	},
+	"aws_vpc": {
+		"assign_generated_ipv6_cidr_block": {"ipv6_association_id", "ipv6_cidr_block"},
+		"ipv6_cidr_block":                  {"ipv6_association_id"},
+	},
+	"aws_subnet": {
+		"availability_zone": {"availability_zone_id"},
+		"ipv6_cidr_block":   {"ipv6_cidr_block_association_id"},
+	},
+	"aws_iam_role": {
+		"name":        {"arn", "unique_id"},
+		"name_prefix": {"arn", "name", "unique_id"},
+		"path":        {"arn"},
+	},
+	"aws_iam_policy": {
+		"name":        {"arn", "policy_id"},
+		"name_prefix": {"arn", "name", "policy_id"},
+		"path":        {"arn"},
+	},
+	"aws_iam_instance_profile": {
+		"name":        {"arn", "unique_id"},
+		"name_prefix": {"arn", "name", "unique_id"},
+		"path":        {"arn"},
+	},
+}
+
+// computedDependenciesFor returns the dependencies for a resource, adding
+// tags -> tags_all for every resource, dynamic ones included, that has both.
+func computedDependenciesFor(resourceType string, s map[string]*schema.Schema) map[string][]string {
+	deps := make(map[string][]string)
+	for argument, computed := range computedDependencies[resourceType] {
+		deps[argument] = computed
+	}
+	if _, ok := s["tags"]; ok {
+		if _, ok := s["tags_all"]; ok {
+			deps["tags"] = append(deps["tags"], "tags_all")
+		}
+	}
+	return deps
+}
+
+// addComputedDiffs gives every resource with dependent computed attributes
+// a CustomizeDiff that marks them unknown when their inputs change.
+func addComputedDiffs(resources map[string]*schema.Resource) {
+	for resourceType, r := range resources {
+		deps := computedDependenciesFor(resourceType, r.Schema)
+		if len(deps) == 0 {
+			continue
+		}
+		diff := customizeComputedDiff(r.Schema, deps)
+		if r.CustomizeDiff != nil {
+			diff = customdiff.All(r.CustomizeDiff, diff)
+		}
+		r.CustomizeDiff = diff
+	}
+}
+
+// customizeComputedDiff calls SetNewComputed on the attributes that depend
+// on a changed argument. Attributes set in configuration are left alone, as
+// their planned value is the configured one; so are new resources, whose
+// computed attributes are all unknown already.
+func customizeComputedDiff(s map[string]*schema.Schema, deps map[string][]string) schema.CustomizeDiffFunc {
+	arguments := make([]string, 0, len(deps))
+	for argument := range deps {
+		arguments = append(arguments, argument)
+	}
+	sort.Strings(arguments)
+
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if d.Id() == "" {
+			return nil
+		}
+
+		config := d.GetRawConfig()
+		configured := func(key string) bool {
+			if config.IsNull() || !config.Type().IsObjectType() || !config.Type().HasAttribute(key) {
+				return false
+			}
+			return !config.GetAttr(key).IsNull()
+		}
+
+		for _, argument := range arguments {
+			if !d.HasChange(argument) {
+				continue
+			}
+			for _, key := range deps[argument] {
+				field, ok := s[key]
+				if !ok || !field.Computed || configured(key) {
+					continue
+				}
+				if err := d.SetNewComputed(key); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
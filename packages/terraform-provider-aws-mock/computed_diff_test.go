@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// planResource plans an update from prior to config and returns the
+// planned state.
+func planResource(t *testing.T, resourceType string, prior, config map[string]cty.Value) cty.Value {
+	t.Helper()
+	p := Provider()
+	r, ok := p.ResourcesMap[resourceType]
+	if !ok {
+		t.Skipf("%s is not in the embedded schema", resourceType)
+	}
+	server := schema.NewGRPCProviderServer(p)
+	ty := r.CoreConfigSchema().ImpliedType()
+
+	encode := func(v cty.Value) *tfprotov5.DynamicValue {
+		raw, err := msgpack.Marshal(v, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &tfprotov5.DynamicValue{MsgPack: raw}
+	}
+
+	priorVal := resourceObject(r, prior)
+	configVal := resourceObject(r, config)
+	resp, err := server.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         resourceType,
+		PriorState:       encode(priorVal),
+		ProposedNewState: encode(proposedNewState(r, priorVal, configVal)),
+		Config:           encode(configVal),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Fatalf("plan: %s: %s", d.Summary, d.Detail)
+	}
+
+	planned, err := msgpack.Unmarshal(resp.PlannedState.MsgPack, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return planned
+}
+
+func stringMap(values map[string]string) cty.Value {
+	if len(values) == 0 {
+		return cty.MapValEmpty(cty.String)
+	}
+	m := make(map[string]cty.Value, len(values))
+	for k, v := range values {
+		m[k] = cty.StringVal(v)
+	}
+	return cty.MapVal(m)
+}
+
+func bucketState(bucket string) map[string]cty.Value {
+	return map[string]cty.Value{
+		"id":                          cty.StringVal(bucket),
+		"bucket":                      cty.StringVal(bucket),
+		"arn":                         cty.StringVal("arn:aws:s3:::" + bucket),
+		"bucket_domain_name":          cty.StringVal(bucket + ".s3.amazonaws.com"),
+		"bucket_regional_domain_name": cty.StringVal(bucket + ".s3.us-east-1.amazonaws.com"),
+		"hosted_zone_id":              cty.StringVal("Z3AQBSTGFYJSTF"),
+		"tags_all":                    stringMap(nil),
+	}
+}
+
+func TestComputedDiffBucketRename(t *testing.T) {
+	planned := planResource(t, "aws_s3_bucket", bucketState("old"), map[string]cty.Value{
+		"bucket": cty.StringVal("new"),
+	})
+
+	for _, key := range []string{"arn", "bucket_domain_name", "bucket_regional_domain_name"} {
+		if planned.GetAttr(key).IsKnown() {
+			t.Errorf("%s should be unknown after a rename, planned %#v", key, planned.GetAttr(key))
+		}
+	}
+	if !planned.GetAttr("hosted_zone_id").IsKnown() {
+		t.Error("hosted_zone_id does not depend on bucket and should stay known")
+	}
+}
+
+func TestComputedDiffUnchangedKeepsValues(t *testing.T) {
+	planned := planResource(t, "aws_s3_bucket", bucketState("same"), map[string]cty.Value{
+		"bucket": cty.StringVal("same"),
+	})
+	if got := planned.GetAttr("arn"); !got.IsKnown() || got.AsString() != "arn:aws:s3:::same" {
+		t.Errorf("arn should be unchanged, planned %#v", got)
+	}
+}
+
+func TestComputedDiffTagsAll(t *testing.T) {
+	prior := map[string]cty.Value{
+		"id":         cty.StringVal("vpc-1"),
+		"cidr_block": cty.StringVal("10.0.0.0/16"),
+		"arn":        cty.StringVal("arn:aws:ec2:us-east-1:123456789012:vpc/vpc-1"),
+		"tags":       stringMap(map[string]string{"env": "dev"}),
+		"tags_all":   stringMap(map[string]string{"env": "dev"}),
+	}
+
+	planned := planResource(t, "aws_vpc", prior, map[string]cty.Value{
+		"cidr_block": cty.StringVal("10.0.0.0/16"),
+		"tags":       stringMap(map[string]string{"env": "prod"}),
+	})
+	if planned.GetAttr("tags_all").IsKnown() {
+		t.Error("tags_all should be unknown when tags change")
+	}
+	if !planned.GetAttr("arn").IsKnown() {
+		t.Error("arn does not depend on tags and should stay known")
+	}
+
+	// A configured tags_all plans as configured.
+	planned = planResource(t, "aws_vpc", prior, map[string]cty.Value{
+		"cidr_block": cty.StringVal("10.0.0.0/16"),
+		"tags":       stringMap(map[string]string{"env": "prod"}),
+		"tags_all":   stringMap(map[string]string{"env": "prod"}),
+	})
+	if !planned.GetAttr("tags_all").IsKnown() {
+		t.Error("a configured tags_all should not be marked unknown")
+	}
+}
+
+// A new cidr_block replaces the VPC, which is what leaves arn, owner_id
+// and default_security_group_id (known after apply): Terraform plans the
+// replacement from a null prior, where they are all unknown.
+func TestComputedDiffVpcCidrReplacement(t *testing.T) {
+	p := Provider()
+	r := p.ResourcesMap["aws_vpc"]
+	server := schema.NewGRPCProviderServer(p)
+	ty := r.CoreConfigSchema().ImpliedType()
+
+	prior := resourceObject(r, map[string]cty.Value{
+		"id":                        cty.StringVal("vpc-1"),
+		"cidr_block":                cty.StringVal("10.0.0.0/16"),
+		"arn":                       cty.StringVal("arn:aws:ec2:us-east-1:123456789012:vpc/vpc-1"),
+		"owner_id":                  cty.StringVal("123456789012"),
+		"default_security_group_id": cty.StringVal("sg-1"),
+	})
+	config := resourceObject(r, map[string]cty.Value{"cidr_block": cty.StringVal("10.1.0.0/16")})
+	plan := func(prior cty.Value) *tfprotov5.PlanResourceChangeResponse {
+		t.Helper()
+		resp, err := server.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+			TypeName:         "aws_vpc",
+			PriorState:       mustMsgpack(t, prior, ty),
+			ProposedNewState: mustMsgpack(t, proposedNewState(r, prior, config), ty),
+			Config:           mustMsgpack(t, config, ty),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range resp.Diagnostics {
+			t.Fatalf("plan: %s: %s", d.Summary, d.Detail)
+		}
+		return resp
+	}
+
+	replaced := false
+	for _, path := range plan(prior).RequiresReplace {
+		if len(path.Steps()) == 1 && path.Steps()[0] == tftypes.AttributeName("cidr_block") {
+			replaced = true
+		}
+	}
+	if !replaced {
+		t.Fatal("a new cidr_block should replace the VPC")
+	}
+
+	planned, err := msgpack.Unmarshal(plan(cty.NullVal(ty)).PlannedState.MsgPack, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"arn", "owner_id", "default_security_group_id"} {
+		if planned.GetAttr(key).IsKnown() {
+			t.Errorf("%s of the replacement VPC should be unknown, planned %#v", key, planned.GetAttr(key))
+		}
+	}
+}
+
+func TestComputedDiffDynamicResourceTags(t *testing.T) {
+	p := Provider()
+	r, ok := p.ResourcesMap["aws_sqs_queue"]
+	if !ok || r.Schema["tags_all"] == nil {
+		t.Skip("aws_sqs_queue with tags_all is not in the embedded schema")
+	}
+
+	planned := planResource(t, "aws_sqs_queue", map[string]cty.Value{
+		"id":       cty.StringVal("queue"),
+		"name":     cty.StringVal("queue"),
+		"tags":     stringMap(map[string]string{"team": "a"}),
+		"tags_all": stringMap(map[string]string{"team": "a"}),
+	}, map[string]cty.Value{
+		"name": cty.StringVal("queue"),
+		"tags": stringMap(map[string]string{"team": "b"}),
+	})
+	if planned.GetAttr("tags_all").IsKnown() {
+		t.Error("tags_all should be unknown when tags change on a dynamic resource")
+	}
+}
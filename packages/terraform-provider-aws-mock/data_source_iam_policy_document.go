@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceIamPolicyDocument() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIamPolicyDocumentRead,
+		Schema:      dataSourceIamPolicyDocumentSchema(),
+	}
+}
+
+// iamPolicyDoc and iamPolicyStatement give policy JSON a fixed key order.
+// Element values are interface{} so statements decoded from source and
+// override documents round-trip unchanged.
+type iamPolicyDoc struct {
+	Version    string                `json:",omitempty"`
+	Id         string                `json:",omitempty"`
+	Statements []*iamPolicyStatement `json:"Statement,omitempty"`
+}
+
+type iamPolicyStatement struct {
+	Sid           string      `json:",omitempty"`
+	Effect        string      `json:",omitempty"`
+	Actions       interface{} `json:"Action,omitempty"`
+	NotActions    interface{} `json:"NotAction,omitempty"`
+	Resources     interface{} `json:"Resource,omitempty"`
+	NotResources  interface{} `json:"NotResource,omitempty"`
+	Principals    interface{} `json:"Principal,omitempty"`
+	NotPrincipals interface{} `json:"NotPrincipal,omitempty"`
+	Conditions    interface{} `json:"Condition,omitempty"`
+}
+
+// UnmarshalJSON accepts a single Statement object as well as an array.
+func (doc *iamPolicyDoc) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Version   string
+		Id        string
+		Statement json.RawMessage
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	doc.Version, doc.Id = raw.Version, raw.Id
+	if len(raw.Statement) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Statement, &doc.Statements); err == nil {
+		return nil
+	}
+	var single iamPolicyStatement
+	if err := json.Unmarshal(raw.Statement, &single); err != nil {
+		return err
+	}
+	doc.Statements = []*iamPolicyStatement{&single}
+	return nil
+}
+
+// merge adds statements to doc. A statement whose Sid matches one already in
+// doc replaces it in place; the rest are appended.
+func (doc *iamPolicyDoc) merge(statements []*iamPolicyStatement) {
+	for _, statement := range statements {
+		replaced := false
+		if statement.Sid != "" {
+			for i, existing := range doc.Statements {
+				if existing.Sid == statement.Sid {
+					doc.Statements[i] = statement
+					replaced = true
+					break
+				}
+			}
+		}
+		if !replaced {
+			doc.Statements = append(doc.Statements, statement)
+		}
+	}
+}
+
+// policyStringOrList renders a set of policy values the way the real
+// provider does: a lone value as a string, several as a reverse-sorted list.
+func policyStringOrList(values []string) interface{} {
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	}
+	sorted := append([]string(nil), values...)
+	sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
+	return sorted
+}
+
+func setStrings(v interface{}) []string {
+	set, ok := v.(*schema.Set)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, set.Len())
+	for _, item := range set.List() {
+		out = append(out, item.(string))
+	}
+	return out
+}
+
+// expandPolicyPrincipals groups principals blocks by type. A wildcard
+// principal of type "*" renders as the bare string "*".
+func expandPolicyPrincipals(v interface{}) interface{} {
+	set, ok := v.(*schema.Set)
+	if !ok || set.Len() == 0 {
+		return nil
+	}
+	byType := make(map[string][]string)
+	for _, raw := range set.List() {
+		principal := raw.(map[string]interface{})
+		principalType := principal["type"].(string)
+		identifiers := setStrings(principal["identifiers"])
+		if principalType == "*" && len(identifiers) == 1 && identifiers[0] == "*" && set.Len() == 1 {
+			return "*"
+		}
+		byType[principalType] = append(byType[principalType], identifiers...)
+	}
+	out := make(map[string]interface{}, len(byType))
+	for principalType, identifiers := range byType {
+		out[principalType] = policyStringOrList(identifiers)
+	}
+	return out
+}
+
+// expandPolicyConditions builds the Condition map, merging the values of
+// blocks that share a test and variable.
+func expandPolicyConditions(v interface{}) interface{} {
+	set, ok := v.(*schema.Set)
+	if !ok || set.Len() == 0 {
+		return nil
+	}
+	values := make(map[string]map[string][]string)
+	for _, raw := range set.List() {
+		condition := raw.(map[string]interface{})
+		test := condition["test"].(string)
+		variable := condition["variable"].(string)
+		if values[test] == nil {
+			values[test] = make(map[string][]string)
+		}
+		for _, value := range condition["values"].([]interface{}) {
+			s, _ := value.(string)
+			values[test][variable] = append(values[test][variable], s)
+		}
+	}
+	out := make(map[string]interface{}, len(values))
+	for test, variables := range values {
+		rendered := make(map[string]interface{}, len(variables))
+		for variable, vals := range variables {
+			rendered[variable] = policyStringOrList(vals)
+		}
+		out[test] = rendered
+	}
+	return out
+}
+
+func expandPolicyStatement(raw map[string]interface{}) *iamPolicyStatement {
+	return &iamPolicyStatement{
+		Sid:           raw["sid"].(string),
+		Effect:        raw["effect"].(string),
+		Actions:       policyStringOrList(setStrings(raw["actions"])),
+		NotActions:    policyStringOrList(setStrings(raw["not_actions"])),
+		Resources:     policyStringOrList(setStrings(raw["resources"])),
+		NotResources:  policyStringOrList(setStrings(raw["not_resources"])),
+		Principals:    expandPolicyPrincipals(raw["principals"]),
+		NotPrincipals: expandPolicyPrincipals(raw["not_principals"]),
+		Conditions:    expandPolicyConditions(raw["condition"]),
+	}
+}
+
+// buildIamPolicyDocument merges source_policy_documents, then the statement
+// blocks, then override_policy_documents, each later layer replacing
+// statements with a matching Sid.
+func buildIamPolicyDocument(d *schema.ResourceData) (*iamPolicyDoc, error) {
+	doc := &iamPolicyDoc{
+		Version: d.Get("version").(string),
+		Id:      d.Get("policy_id").(string),
+	}
+
+	seenSids := make(map[string]bool)
+	for i, raw := range d.Get("source_policy_documents").([]interface{}) {
+		source, _ := raw.(string)
+		if source == "" {
+			continue
+		}
+		var sourceDoc iamPolicyDoc
+		if err := json.Unmarshal([]byte(source), &sourceDoc); err != nil {
+			return nil, fmt.Errorf("source_policy_documents.%d: %w", i, err)
+		}
+		for _, statement := range sourceDoc.Statements {
+			if statement.Sid != "" {
+				if seenSids[statement.Sid] {
+					return nil, fmt.Errorf("duplicate Sid (%s) in source_policy_documents; Sids must be unique", statement.Sid)
+				}
+				seenSids[statement.Sid] = true
+			}
+		}
+		doc.merge(sourceDoc.Statements)
+	}
+
+	var statements []*iamPolicyStatement
+	blockSids := make(map[string]bool)
+	for i, raw := range d.Get("statement").([]interface{}) {
+		block, _ := raw.(map[string]interface{})
+		if block == nil {
+			continue
+		}
+		statement := expandPolicyStatement(block)
+		if statement.Sid != "" {
+			if blockSids[statement.Sid] {
+				return nil, fmt.Errorf("statement.%d: duplicate Sid (%s); Sids must be unique", i, statement.Sid)
+			}
+			blockSids[statement.Sid] = true
+		}
+		statements = append(statements, statement)
+	}
+	doc.merge(statements)
+
+	for i, raw := range d.Get("override_policy_documents").([]interface{}) {
+		override, _ := raw.(string)
+		if override == "" {
+			continue
+		}
+		var overrideDoc iamPolicyDoc
+		if err := json.Unmarshal([]byte(override), &overrideDoc); err != nil {
+			return nil, fmt.Errorf("override_policy_documents.%d: %w", i, err)
+		}
+		doc.merge(overrideDoc.Statements)
+	}
+
+	return doc, nil
+}
+
+func dataSourceIamPolicyDocumentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	doc, err := buildIamPolicyDocument(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pretty, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	minified, err := json.Marshal(doc)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(int(crc32.ChecksumIEEE(pretty))))
+	d.Set("json", string(pretty))
+	d.Set("minified_json", string(minified))
+	return nil
+}
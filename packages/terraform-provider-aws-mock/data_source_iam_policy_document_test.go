@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func readPolicyDocument(t *testing.T, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+	ds := Provider().DataSourcesMap["aws_iam_policy_document"]
+	d := schema.TestResourceDataRaw(t, ds.Schema, raw)
+	if diags := ds.ReadContext(context.Background(), d, nil); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	return d
+}
+
+func TestProviderDeclaresIamPolicyDocument(t *testing.T) {
+	if Provider().DataSourcesMap["aws_iam_policy_document"] == nil {
+		t.Fatal("aws_iam_policy_document data source missing from provider")
+	}
+}
+
+func TestIamPolicyDocumentRendersStatements(t *testing.T) {
+	d := readPolicyDocument(t, map[string]interface{}{
+		"policy_id": "example",
+		"statement": []interface{}{
+			map[string]interface{}{
+				"sid":       "ReadBucket",
+				"actions":   []interface{}{"s3:ListBucket", "s3:GetObject"},
+				"resources": []interface{}{"arn:aws:s3:::example/*"},
+				"principals": []interface{}{
+					map[string]interface{}{"type": "AWS", "identifiers": []interface{}{"arn:aws:iam::123456789012:root"}},
+				},
+				"condition": []interface{}{
+					map[string]interface{}{"test": "StringEquals", "variable": "aws:SourceVpc", "values": []interface{}{"vpc-1"}},
+				},
+			},
+			map[string]interface{}{
+				"effect":     "Deny",
+				"actions":    []interface{}{"s3:*"},
+				"resources":  []interface{}{"*"},
+				"principals": []interface{}{map[string]interface{}{"type": "*", "identifiers": []interface{}{"*"}}},
+			},
+		},
+	})
+
+	want := `{
+  "Version": "2012-10-17",
+  "Id": "example",
+  "Statement": [
+    {
+      "Sid": "ReadBucket",
+      "Effect": "Allow",
+      "Action": [
+        "s3:ListBucket",
+        "s3:GetObject"
+      ],
+      "Resource": "arn:aws:s3:::example/*",
+      "Principal": {
+        "AWS": "arn:aws:iam::123456789012:root"
+      },
+      "Condition": {
+        "StringEquals": {
+          "aws:SourceVpc": "vpc-1"
+        }
+      }
+    },
+    {
+      "Effect": "Deny",
+      "Action": "s3:*",
+      "Resource": "*",
+      "Principal": "*"
+    }
+  ]
+}`
+	if got := d.Get("json").(string); got != want {
+		t.Errorf("unexpected json:\n%s\nwant:\n%s", got, want)
+	}
+	if strings.ContainsAny(d.Get("minified_json").(string), "\n ") {
+		t.Errorf("minified_json should have no whitespace: %s", d.Get("minified_json"))
+	}
+	if d.Id() == "" {
+		t.Error("expected an ID derived from the document")
+	}
+}
+
+func TestIamPolicyDocumentIsDeterministic(t *testing.T) {
+	raw := map[string]interface{}{
+		"statement": []interface{}{
+			map[string]interface{}{
+				"actions":   []interface{}{"s3:PutObject", "s3:GetObject", "s3:DeleteObject"},
+				"resources": []interface{}{"b", "a"},
+			},
+		},
+	}
+	first := readPolicyDocument(t, raw)
+	for i := 0; i < 5; i++ {
+		again := readPolicyDocument(t, raw)
+		if again.Get("json") != first.Get("json") || again.Id() != first.Id() {
+			t.Fatal("expected identical output for identical input")
+		}
+	}
+}
+
+func TestIamPolicyDocumentMergesSourceAndOverride(t *testing.T) {
+	source := `{"Version":"2012-10-17","Statement":[
+		{"Sid":"Keep","Effect":"Allow","Action":"s3:GetObject","Resource":"*"},
+		{"Sid":"Replace","Effect":"Allow","Action":"s3:PutObject","Resource":"*"}]}`
+	override := `{"Version":"2012-10-17","Statement":{"Sid":"Keep","Effect":"Deny","Action":"s3:GetObject","Resource":"*"}}`
+
+	d := readPolicyDocument(t, map[string]interface{}{
+		"source_policy_documents":   []interface{}{source},
+		"override_policy_documents": []interface{}{override},
+		"statement": []interface{}{
+			map[string]interface{}{"sid": "Replace", "actions": []interface{}{"s3:DeleteObject"}, "resources": []interface{}{"*"}},
+			map[string]interface{}{"sid": "Extra", "actions": []interface{}{"s3:ListBucket"}, "resources": []interface{}{"*"}},
+		},
+	})
+
+	var doc struct {
+		Statement []struct {
+			Sid    string
+			Effect string
+			Action interface{}
+		}
+	}
+	if err := json.Unmarshal([]byte(d.Get("json").(string)), &doc); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(doc.Statement) != 3 {
+		t.Fatalf("expected 3 statements, got %d: %s", len(doc.Statement), d.Get("json"))
+	}
+	got := make(map[string]string)
+	for _, s := range doc.Statement {
+		got[s.Sid] = s.Effect + " " + s.Action.(string)
+	}
+	if got["Keep"] != "Deny s3:GetObject" {
+		t.Errorf("override should replace Keep, got %s", got["Keep"])
+	}
+	if got["Replace"] != "Allow s3:DeleteObject" {
+		t.Errorf("statement block should replace the source's Replace, got %s", got["Replace"])
+	}
+	if doc.Statement[0].Sid != "Keep" || doc.Statement[2].Sid != "Extra" {
+		t.Errorf("expected replaced statements to keep their position, got %+v", doc.Statement)
+	}
+}
+
+func TestIamPolicyDocumentRejectsDuplicateSourceSids(t *testing.T) {
+	source := `{"Statement":[{"Sid":"A","Effect":"Allow","Action":"s3:*","Resource":"*"}]}`
+	ds := Provider().DataSourcesMap["aws_iam_policy_document"]
+	d := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"source_policy_documents": []interface{}{source, source},
+	})
+	if diags := ds.ReadContext(context.Background(), d, nil); !diags.HasError() {
+		t.Error("expected an error for duplicate Sids across source documents")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"hash/crc32"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceMockPolicySimulation evaluates a policy locally, either one
+// given inline or the bucket policy stored in the backend, and reports
+// whether a principal may perform an action on a resource.
+func dataSourceMockPolicySimulation() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceMockPolicySimulationRead,
+		Schema:      dataSourceMockPolicySimulationSchema(),
+	}
+}
+
+// simulationContext builds the request context from the context blocks and
+// fills in the global keys derived from the principal.
+func simulationContext(d *schema.ResourceData, principal string) map[string][]string {
+	values := make(map[string][]string)
+	for _, raw := range d.Get("context").(*schema.Set).List() {
+		entry := raw.(map[string]interface{})
+		key := entry["key"].(string)
+		for _, v := range entry["values"].([]interface{}) {
+			s, _ := v.(string)
+			values[key] = append(values[key], s)
+		}
+	}
+
+	if _, ok := values["aws:PrincipalArn"]; !ok && strings.HasPrefix(principal, "arn:") {
+		values["aws:PrincipalArn"] = []string{principal}
+	}
+	if _, ok := values["aws:PrincipalAccount"]; !ok {
+		if account := principalAccount(principal); account != "" {
+			values["aws:PrincipalAccount"] = []string{account}
+		}
+	}
+	return values
+}
+
+func dataSourceMockPolicySimulationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	policy := d.Get("policy").(string)
+	resource := d.Get("resource").(string)
+
+	if bucket := d.Get("bucket").(string); bucket != "" {
+		client := meta.(*MockClient)
+		result, err := client.ReadResource("aws_s3_bucket_policy", bucket)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if result == nil {
+			return diag.Errorf("NoSuchBucketPolicy: bucket %s has no bucket policy", bucket)
+		}
+		policy = stringAttribute(result, "policy")
+		if resource == "" {
+			resource = "arn:aws:s3:::" + bucket
+		}
+	}
+	if resource == "" {
+		return diag.Errorf("resource is required when simulating an inline policy")
+	}
+
+	principal := d.Get("principal").(string)
+	evaluation, err := evaluatePolicy(policy, policyRequest{
+		Principal: principal,
+		Action:    d.Get("action").(string),
+		Resource:  resource,
+		Context:   simulationContext(d, principal),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	id := strings.Join([]string{policy, principal, d.Get("action").(string), resource}, "\n")
+	d.SetId(strconv.Itoa(int(crc32.ChecksumIEEE([]byte(id)))))
+	d.Set("resource", resource)
+	d.Set("decision", evaluation.Decision)
+	d.Set("allowed", evaluation.Decision == decisionAllowed)
+	d.Set("matched_statements", evaluation.MatchedStatements)
+	return nil
+}
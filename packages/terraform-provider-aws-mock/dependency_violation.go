@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const errCodeDependencyViolation = "DependencyViolation"
+
+// deleteRetryingDependencyViolation deletes a resource, retrying with
+// backoff while the backend reports DependencyViolation. Like the real AWS
+// provider, it gives up once the resource's delete timeout expires, which
+// surfaces missing depends_on ordering the same way production does.
+func deleteRetryingDependencyViolation(ctx context.Context, d *schema.ResourceData, client *MockClient, resourceType string) diag.Diagnostics {
+	err := retry.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *retry.RetryError {
+		err := client.DeleteResourceIfMatch(resourceType, d.Id(), resourceVersion(ctx))
+		if hasErrorCode(err, errCodeDependencyViolation) {
+			return retry.RetryableError(err)
+		}
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		return nil
+	})
+
+	if hasErrorCode(err, errCodeDependencyViolation) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("deleting %s (%s): %s", resourceType, d.Id(), errCodeDependencyViolation),
+			Detail: fmt.Sprintf(
+				"%s %s still has dependent resources and cannot be deleted. "+
+					"Destroy the dependents first, or add depends_on so Terraform orders the deletes correctly.\n\n%s",
+				resourceType, d.Id(), err,
+			),
+		}}
+	}
+	if err != nil {
+		return diagFromClientError(err)
+	}
+	return nil
+}
+
+// dependencyViolationTimeouts mirrors the real provider's delete timeout for
+// resources that wait out DependencyViolation.
+func dependencyViolationTimeouts(deleteTimeout time.Duration) *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Delete: schema.DefaultTimeout(deleteTimeout),
+	}
+}
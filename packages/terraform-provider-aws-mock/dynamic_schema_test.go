@@ -1,11 +1,6 @@
 package main
 
-import (
-	"encoding/json"
-	"testing"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-)
+import "testing"
 
 func TestDynamicResourceCount(t *testing.T) {
 	resources := buildAllDynamicResources()
@@ -39,88 +34,6 @@ func TestDynamicResourceHasCRUD(t *testing.T) {
 	}
 }
 
-func TestConvertPrimitiveTypes(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected schema.ValueType
-	}{
-		{`"string"`, schema.TypeString},
-		{`"number"`, schema.TypeInt},
-		{`"bool"`, schema.TypeBool},
-	}
-
-	for _, tt := range tests {
-		typ, elem := convertType(json.RawMessage(tt.input))
-		if typ != tt.expected {
-			t.Errorf("convertType(%s) = %v, want %v", tt.input, typ, tt.expected)
-		}
-		if elem != nil {
-			t.Errorf("convertType(%s) elem should be nil, got %v", tt.input, elem)
-		}
-	}
-}
-
-func TestConvertCollectionTypes(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected schema.ValueType
-		elemType schema.ValueType
-	}{
-		{"list of string", `["list", "string"]`, schema.TypeList, schema.TypeString},
-		{"set of string", `["set", "string"]`, schema.TypeSet, schema.TypeString},
-		{"map of string", `["map", "string"]`, schema.TypeMap, schema.TypeString},
-		{"list of number", `["list", "number"]`, schema.TypeList, schema.TypeInt},
-		{"set of bool", `["set", "bool"]`, schema.TypeSet, schema.TypeBool},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			typ, elem := convertType(json.RawMessage(tt.input))
-			if typ != tt.expected {
-				t.Errorf("type = %v, want %v", typ, tt.expected)
-			}
-			s, ok := elem.(*schema.Schema)
-			if !ok {
-				t.Fatalf("elem should be *schema.Schema, got %T", elem)
-			}
-			if s.Type != tt.elemType {
-				t.Errorf("elem type = %v, want %v", s.Type, tt.elemType)
-			}
-		})
-	}
-}
-
-func TestConvertObjectInCollection(t *testing.T) {
-	input := `["set", ["object", {"name": "string", "count": "number"}]]`
-
-	typ, elem := convertType(json.RawMessage(input))
-	if typ != schema.TypeSet {
-		t.Fatalf("type = %v, want TypeSet", typ)
-	}
-
-	res, ok := elem.(*schema.Resource)
-	if !ok {
-		t.Fatalf("elem should be *schema.Resource, got %T", elem)
-	}
-
-	nameField, ok := res.Schema["name"]
-	if !ok {
-		t.Fatal("missing 'name' field in object schema")
-	}
-	if nameField.Type != schema.TypeString {
-		t.Errorf("name type = %v, want TypeString", nameField.Type)
-	}
-
-	countField, ok := res.Schema["count"]
-	if !ok {
-		t.Fatal("missing 'count' field in object schema")
-	}
-	if countField.Type != schema.TypeInt {
-		t.Errorf("count type = %v, want TypeInt", countField.Type)
-	}
-}
-
 func TestHandWrittenOverridesDynamic(t *testing.T) {
 	p := Provider()
 
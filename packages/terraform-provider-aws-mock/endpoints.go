@@ -0,0 +1,150 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// endpointServices lists the service names accepted in the provider's
+// `endpoints` block.
+var endpointServices = []string{
+	"acm",
+	"apigateway",
+	"autoscaling",
+	"cloudfront",
+	"cloudwatch",
+	"dynamodb",
+	"ec2",
+	"ecr",
+	"ecs",
+	"eks",
+	"elasticache",
+	"elb",
+	"iam",
+	"kms",
+	"lambda",
+	"logs",
+	"rds",
+	"route53",
+	"s3",
+	"secretsmanager",
+	"sns",
+	"sqs",
+	"ssm",
+	"sts",
+}
+
+// resourceServicePrefixes maps resource type prefixes whose service cannot be
+// read off the first name segment (e.g. aws_vpc is an EC2 resource).
+var resourceServicePrefixes = map[string]string{
+	"aws_acm":               "acm",
+	"aws_alb":               "elb",
+	"aws_ami":               "ec2",
+	"aws_api_gateway":       "apigateway",
+	"aws_apigatewayv2":      "apigateway",
+	"aws_autoscaling":       "autoscaling",
+	"aws_cloudfront":        "cloudfront",
+	"aws_cloudwatch":        "cloudwatch",
+	"aws_cloudwatch_log":    "logs",
+	"aws_customer_gateway":  "ec2",
+	"aws_db":                "rds",
+	"aws_default":           "ec2",
+	"aws_dynamodb":          "dynamodb",
+	"aws_ebs":               "ec2",
+	"aws_ec2":               "ec2",
+	"aws_ecr":               "ecr",
+	"aws_ecs":               "ecs",
+	"aws_egress_only":       "ec2",
+	"aws_eip":               "ec2",
+	"aws_eks":               "eks",
+	"aws_elasticache":       "elasticache",
+	"aws_elb":               "elb",
+	"aws_flow_log":          "ec2",
+	"aws_iam":               "iam",
+	"aws_instance":          "ec2",
+	"aws_internet_gateway":  "ec2",
+	"aws_key_pair":          "ec2",
+	"aws_kms":               "kms",
+	"aws_lambda":            "lambda",
+	"aws_launch_template":   "ec2",
+	"aws_lb":                "elb",
+	"aws_main_route_table":  "ec2",
+	"aws_nat_gateway":       "ec2",
+	"aws_network":           "ec2",
+	"aws_placement_group":   "ec2",
+	"aws_rds":               "rds",
+	"aws_route":             "ec2",
+	"aws_route53":           "route53",
+	"aws_s3":                "s3",
+	"aws_secretsmanager":    "secretsmanager",
+	"aws_security_group":    "ec2",
+	"aws_sns":               "sns",
+	"aws_spot":              "ec2",
+	"aws_sqs":               "sqs",
+	"aws_ssm":               "ssm",
+	"aws_sts":               "sts",
+	"aws_subnet":            "ec2",
+	"aws_transit_gateway":   "ec2",
+	"aws_volume_attachment": "ec2",
+	"aws_vpc":               "ec2",
+	"aws_vpn":               "ec2",
+}
+
+// serviceForResourceType works out which AWS service owns a resource type,
+// preferring the longest matching entry in resourceServicePrefixes and
+// falling back to the first segment of the name after "aws_".
+func serviceForResourceType(resourceType string) string {
+	best := ""
+	for prefix := range resourceServicePrefixes {
+		if resourceType != prefix && !strings.HasPrefix(resourceType, prefix+"_") {
+			continue
+		}
+		if len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best != "" {
+		return resourceServicePrefixes[best]
+	}
+
+	name := strings.TrimPrefix(resourceType, "aws_")
+	if i := strings.Index(name, "_"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func providerEndpointsSchema() *schema.Schema {
+	services := make(map[string]*schema.Schema, len(endpointServices))
+	for _, service := range endpointServices {
+		services[service] = &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Use this to override the default backend URL for " + service + " resources",
+		}
+	}
+
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem:        &schema.Resource{Schema: services},
+		Description: "Per-service backend URLs; services without an entry use backend_url",
+	}
+}
+
+// expandEndpoints flattens the `endpoints` block into a service -> URL map,
+// dropping services left empty.
+func expandEndpoints(raw []interface{}) map[string]string {
+	endpoints := make(map[string]string)
+	if len(raw) == 0 || raw[0] == nil {
+		return endpoints
+	}
+	for service, v := range raw[0].(map[string]interface{}) {
+		if url, ok := v.(string); ok && url != "" {
+			endpoints[service] = strings.TrimSuffix(url, "/")
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// kmsSecretsEphemeralResource decrypts ciphertext blobs produced by
+// aws_kms_ciphertext without the plaintext ever being written to plan or
+// state.
+type kmsSecretsEphemeralResource struct {
+	ephemeralClient
+}
+
+func newKmsSecretsEphemeralResource() ephemeral.EphemeralResource {
+	return &kmsSecretsEphemeralResource{}
+}
+
+type kmsSecretsModel struct {
+	Plaintext types.Map         `tfsdk:"plaintext"`
+	Secrets   []kmsSecretsEntry `tfsdk:"secret"`
+}
+
+type kmsSecretsEntry struct {
+	Context             types.Map    `tfsdk:"context"`
+	EncryptionAlgorithm types.String `tfsdk:"encryption_algorithm"`
+	GrantTokens         types.List   `tfsdk:"grant_tokens"`
+	KeyID               types.String `tfsdk:"key_id"`
+	Name                types.String `tfsdk:"name"`
+	Payload             types.String `tfsdk:"payload"`
+}
+
+func (r *kmsSecretsEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = "aws_kms_secrets"
+}
+
+func (r *kmsSecretsEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"plaintext": schema.MapAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"secret": schema.ListNestedBlock{
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"context": schema.MapAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+						"encryption_algorithm": schema.StringAttribute{Optional: true},
+						"grant_tokens": schema.ListAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+						"key_id":  schema.StringAttribute{Optional: true},
+						"name":    schema.StringAttribute{Required: true},
+						"payload": schema.StringAttribute{Required: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *kmsSecretsEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data kmsSecretsModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, err := r.client()
+	if err != nil {
+		resp.Diagnostics.AddError("Provider not configured", err.Error())
+		return
+	}
+
+	plaintext := make(map[string]string, len(data.Secrets))
+	for _, secret := range data.Secrets {
+		name := secret.Name.ValueString()
+
+		var encryptionContext map[string]string
+		resp.Diagnostics.Append(secret.Context.ElementsAs(ctx, &encryptionContext, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		value, err := decryptMockKMS(client, secret.Payload.ValueString(), secret.KeyID.ValueString(), encryptionContext)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("decrypting KMS secret (%s)", name), err.Error())
+			return
+		}
+		plaintext[name] = value
+	}
+
+	values, diags := types.MapValueFrom(ctx, types.StringType, plaintext)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Plaintext = values
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
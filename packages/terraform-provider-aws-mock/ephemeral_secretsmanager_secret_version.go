@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const secretStageCurrent = "AWSCURRENT"
+
+// secretsmanagerSecretVersionEphemeralResource reads a secret's value
+// without it ever being written to plan or state.
+type secretsmanagerSecretVersionEphemeralResource struct {
+	ephemeralClient
+}
+
+func newSecretsmanagerSecretVersionEphemeralResource() ephemeral.EphemeralResource {
+	return &secretsmanagerSecretVersionEphemeralResource{}
+}
+
+type secretsmanagerSecretVersionModel struct {
+	ARN           types.String `tfsdk:"arn"`
+	CreatedDate   types.String `tfsdk:"created_date"`
+	SecretBinary  types.String `tfsdk:"secret_binary"`
+	SecretID      types.String `tfsdk:"secret_id"`
+	SecretString  types.String `tfsdk:"secret_string"`
+	VersionID     types.String `tfsdk:"version_id"`
+	VersionStage  types.String `tfsdk:"version_stage"`
+	VersionStages types.List   `tfsdk:"version_stages"`
+}
+
+func (r *secretsmanagerSecretVersionEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = "aws_secretsmanager_secret_version"
+}
+
+func (r *secretsmanagerSecretVersionEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"arn":           schema.StringAttribute{Computed: true},
+			"created_date":  schema.StringAttribute{Computed: true},
+			"secret_binary": schema.StringAttribute{Computed: true, Sensitive: true},
+			"secret_id":     schema.StringAttribute{Required: true},
+			"secret_string": schema.StringAttribute{Computed: true, Sensitive: true},
+			"version_id":    schema.StringAttribute{Optional: true, Computed: true},
+			"version_stage": schema.StringAttribute{Optional: true, Computed: true},
+			"version_stages": schema.ListAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *secretsmanagerSecretVersionEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data secretsmanagerSecretVersionModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, err := r.client()
+	if err != nil {
+		resp.Diagnostics.AddError("Provider not configured", err.Error())
+		return
+	}
+
+	stage := data.VersionStage.ValueString()
+	if stage == "" && data.VersionID.ValueString() == "" {
+		stage = secretStageCurrent
+	}
+	secret, version, err := readSecretVersion(client, data.SecretID.ValueString(), data.VersionID.ValueString(), stage)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading Secrets Manager secret version (%s)", data.SecretID.ValueString()), err.Error())
+		return
+	}
+
+	stages := secretVersionStages(version)
+	if len(stages) == 0 && stage == secretStageCurrent {
+		stages = []string{secretStageCurrent}
+	}
+	stageValues, diags := types.ListValueFrom(ctx, types.StringType, stages)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ARN = types.StringValue(stringAttribute(secret, "arn"))
+	data.CreatedDate = types.StringValue(stringAttribute(version, "created_date"))
+	data.SecretBinary = types.StringValue(stringAttribute(version, "secret_binary"))
+	data.SecretString = types.StringValue(stringAttribute(version, "secret_string"))
+	data.VersionID = types.StringValue(secretVersionID(version))
+	data.VersionStage = types.StringValue(stage)
+	data.VersionStages = stageValues
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+// readSecretVersion finds a secret by name or ARN and picks one of its
+// versions by version ID or staging label. The backend defaults
+// version_stages to empty, so a secret's only unlabelled version counts as
+// AWSCURRENT, which is where Secrets Manager puts a new secret's value.
+func readSecretVersion(client *MockClient, secretID, versionID, stage string) (*ResourceResponse, *ResourceResponse, error) {
+	name := secretID
+	if strings.HasPrefix(secretID, "arn:") {
+		a, err := parseARN(secretID)
+		if err != nil || !strings.HasPrefix(a.Resource, "secret:") {
+			return nil, nil, fmt.Errorf("ValidationException: %q is not a Secrets Manager secret ARN", secretID)
+		}
+		name = strings.TrimPrefix(a.Resource, "secret:")
+	}
+
+	secret, err := client.ReadResource("aws_secretsmanager_secret", name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if secret == nil {
+		return nil, nil, fmt.Errorf("ResourceNotFoundException: Secrets Manager can't find the specified secret %s", secretID)
+	}
+
+	// Versions may reference their secret by name or by ARN.
+	var versions []ResourceResponse
+	references := []string{name}
+	if arn := stringAttribute(secret, "arn"); arn != "" {
+		references = append(references, arn)
+	}
+	for _, reference := range references {
+		found, err := client.ListResources("aws_secretsmanager_secret_version", map[string]string{"secret_id": reference})
+		if err != nil {
+			return nil, nil, err
+		}
+		versions = append(versions, found...)
+	}
+
+	var unlabelled []*ResourceResponse
+	for i := range versions {
+		version := &versions[i]
+		if versionID != "" && secretVersionID(version) != versionID {
+			continue
+		}
+		stages := secretVersionStages(version)
+		if stage == "" || slices.Contains(stages, stage) {
+			return secret, version, nil
+		}
+		if len(stages) == 0 {
+			unlabelled = append(unlabelled, version)
+		}
+	}
+
+	if stage == secretStageCurrent && len(unlabelled) == 1 {
+		return secret, unlabelled[0], nil
+	}
+	if stage == secretStageCurrent && len(unlabelled) > 1 {
+		return nil, nil, fmt.Errorf("ResourceNotFoundException: secret %s has %d versions without version_stages, so none is %s; "+
+			"set version_id, or version_stages on the aws_secretsmanager_secret_version resources", secretID, len(unlabelled), stage)
+	}
+	if versionID != "" {
+		return nil, nil, fmt.Errorf("ResourceNotFoundException: secret %s has no version %s", secretID, versionID)
+	}
+	return nil, nil, fmt.Errorf("ResourceNotFoundException: secret %s has no version labelled %s", secretID, stage)
+}
+
+// secretVersionID returns a version's version_id, falling back to its
+// backend ID.
+func secretVersionID(version *ResourceResponse) string {
+	if id := stringAttribute(version, "version_id"); id != "" {
+		return id
+	}
+	return version.ID
+}
+
+func secretVersionStages(version *ResourceResponse) []string {
+	raw, _ := version.Attributes["version_stages"].([]interface{})
+	stages := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if s, ok := v.(string); ok {
+			stages = append(stages, s)
+		}
+	}
+	return stages
+}
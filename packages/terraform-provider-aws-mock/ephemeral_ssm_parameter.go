@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ssmParameterEphemeralResource reads a parameter's value without it ever
+// being written to plan or state.
+type ssmParameterEphemeralResource struct {
+	ephemeralClient
+}
+
+func newSsmParameterEphemeralResource() ephemeral.EphemeralResource {
+	return &ssmParameterEphemeralResource{}
+}
+
+type ssmParameterModel struct {
+	ARN            types.String `tfsdk:"arn"`
+	Name           types.String `tfsdk:"name"`
+	Type           types.String `tfsdk:"type"`
+	Value          types.String `tfsdk:"value"`
+	Version        types.Int64  `tfsdk:"version"`
+	WithDecryption types.Bool   `tfsdk:"with_decryption"`
+}
+
+func (r *ssmParameterEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = "aws_ssm_parameter"
+}
+
+func (r *ssmParameterEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"arn":             schema.StringAttribute{Required: true},
+			"name":            schema.StringAttribute{Computed: true},
+			"type":            schema.StringAttribute{Computed: true},
+			"value":           schema.StringAttribute{Computed: true, Sensitive: true},
+			"version":         schema.Int64Attribute{Computed: true},
+			"with_decryption": schema.BoolAttribute{Optional: true, Computed: true},
+		},
+	}
+}
+
+func (r *ssmParameterEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data ssmParameterModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, err := r.client()
+	if err != nil {
+		resp.Diagnostics.AddError("Provider not configured", err.Error())
+		return
+	}
+
+	withDecryption := true
+	if !data.WithDecryption.IsNull() && !data.WithDecryption.IsUnknown() {
+		withDecryption = data.WithDecryption.ValueBool()
+	}
+
+	parameter, err := readSSMParameter(client, data.ARN.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading SSM parameter (%s)", data.ARN.ValueString()), err.Error())
+		return
+	}
+
+	value, err := ssmParameterValue(client, parameter, withDecryption)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading SSM parameter (%s)", data.ARN.ValueString()), err.Error())
+		return
+	}
+
+	version := int64(1)
+	if v, ok := parameter.Attributes["version"].(float64); ok && v > 0 {
+		version = int64(v)
+	}
+
+	data.Name = types.StringValue(ssmParameterName(parameter))
+	data.Type = types.StringValue(stringAttribute(parameter, "type"))
+	data.Value = types.StringValue(value)
+	data.Version = types.Int64Value(version)
+	data.WithDecryption = types.BoolValue(withDecryption)
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+// readSSMParameter looks a parameter up by ARN. The backend keys parameters
+// by name, and a hierarchical name's leading slash is folded into the ARN's
+// "parameter/" separator, so both spellings are tried.
+func readSSMParameter(client *MockClient, arn string) (*ResourceResponse, error) {
+	a, err := parseARN(arn)
+	if err != nil || a.Service != "ssm" || !strings.HasPrefix(a.Resource, "parameter/") {
+		return nil, fmt.Errorf("ValidationException: %q is not an SSM parameter ARN", arn)
+	}
+	name := strings.TrimPrefix(a.Resource, "parameter")
+
+	for _, candidate := range []string{name[1:], name} {
+		parameter, err := client.ReadResource("aws_ssm_parameter", candidate)
+		if err != nil {
+			return nil, err
+		}
+		if parameter != nil {
+			return parameter, nil
+		}
+	}
+	return nil, fmt.Errorf("ParameterNotFound: parameter %s does not exist", name[1:])
+}
+
+func ssmParameterName(parameter *ResourceResponse) string {
+	if name := stringAttribute(parameter, "name"); name != "" {
+		return name
+	}
+	return parameter.ID
+}
+
+// ssmParameterValue returns a parameter's value. Without decryption a
+// SecureString comes back as ciphertext, as GetParameter returns it.
+func ssmParameterValue(client *MockClient, parameter *ResourceResponse, withDecryption bool) (string, error) {
+	value := stringAttribute(parameter, "value")
+	if value == "" {
+		value = stringAttribute(parameter, "insecure_value")
+	}
+	if withDecryption || stringAttribute(parameter, "type") != "SecureString" {
+		return value, nil
+	}
+
+	keyID := stringAttribute(parameter, "key_id")
+	if keyID == "" {
+		keyID = "alias/aws/ssm"
+	}
+	return encryptMockKMS(client, keyID, value, map[string]string{"PARAMETER_ARN": stringAttribute(parameter, "arn")})
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestReadSecretVersionByNameArnAndStage(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	arn := "arn:aws:secretsmanager:us-east-1:123456789012:secret:db"
+	b.put("aws_secretsmanager_secret", "db", map[string]interface{}{"name": "db", "arn": arn})
+	b.put("aws_secretsmanager_secret_version", "v1", map[string]interface{}{
+		"secret_id": "db", "secret_string": "old", "version_stages": []interface{}{"AWSPREVIOUS"},
+	})
+	b.put("aws_secretsmanager_secret_version", "v2", map[string]interface{}{
+		"secret_id": arn, "secret_string": "new", "version_stages": []interface{}{"AWSCURRENT"},
+	})
+
+	for _, secretID := range []string{"db", arn} {
+		_, version, err := readSecretVersion(client, secretID, "", secretStageCurrent)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", secretID, err)
+		}
+		if got := stringAttribute(version, "secret_string"); got != "new" {
+			t.Errorf("%s: AWSCURRENT secret_string = %q, want new", secretID, got)
+		}
+	}
+
+	_, version, err := readSecretVersion(client, "db", "", "AWSPREVIOUS")
+	if err != nil || stringAttribute(version, "secret_string") != "old" {
+		t.Errorf("AWSPREVIOUS lookup = %v, %v", version, err)
+	}
+	_, version, err = readSecretVersion(client, "db", "v1", "")
+	if err != nil || stringAttribute(version, "secret_string") != "old" {
+		t.Errorf("version_id lookup = %v, %v", version, err)
+	}
+	if _, _, err := readSecretVersion(client, "db", "v9", ""); err == nil {
+		t.Error("expected an error for an unknown version_id")
+	}
+	if _, _, err := readSecretVersion(client, "missing", "", secretStageCurrent); err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+		t.Errorf("expected ResourceNotFoundException for a missing secret, got %v", err)
+	}
+}
+
+func TestReadSecretVersionUnlabelledIsCurrent(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	b.put("aws_secretsmanager_secret", "api", map[string]interface{}{"name": "api"})
+	b.put("aws_secretsmanager_secret_version", "v1", map[string]interface{}{
+		"secret_id": "api", "secret_string": "s3cr3t", "version_stages": []interface{}{},
+	})
+
+	_, version, err := readSecretVersion(client, "api", "", secretStageCurrent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stringAttribute(version, "secret_string") != "s3cr3t" {
+		t.Errorf("unexpected version %v", version.Attributes)
+	}
+
+	b.put("aws_secretsmanager_secret_version", "v2", map[string]interface{}{"secret_id": "api", "secret_string": "other"})
+	if _, _, err := readSecretVersion(client, "api", "", secretStageCurrent); err == nil {
+		t.Error("expected an error when several unlabelled versions could be current")
+	}
+}
+
+func TestReadSSMParameter(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	b.put("aws_ssm_parameter", "db-password", map[string]interface{}{
+		"name": "db-password", "type": "SecureString", "value": "hunter2",
+		"arn": "arn:aws:ssm:us-east-1:123456789012:parameter/db-password",
+	})
+	b.put("aws_kms_key", "alias-target", map[string]interface{}{})
+	b.put("aws_kms_alias", "alias/aws/ssm", map[string]interface{}{"name": "alias/aws/ssm", "target_key_id": "alias-target"})
+
+	parameter, err := readSSMParameter(client, "arn:aws:ssm:us-east-1:123456789012:parameter/db-password")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ssmParameterName(parameter) != "db-password" {
+		t.Errorf("name = %s", ssmParameterName(parameter))
+	}
+	if value, _ := ssmParameterValue(client, parameter, true); value != "hunter2" {
+		t.Errorf("decrypted value = %q", value)
+	}
+
+	ciphertext, err := ssmParameterValue(client, parameter, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ciphertext == "hunter2" {
+		t.Error("a SecureString read without decryption must not return the plaintext")
+	}
+	plaintext, err := decryptMockKMS(client, ciphertext, "alias/aws/ssm",
+		map[string]string{"PARAMETER_ARN": stringAttribute(parameter, "arn")})
+	if err != nil || plaintext != "hunter2" {
+		t.Errorf("ciphertext should decrypt under the parameter's key: %q, %v", plaintext, err)
+	}
+
+	if _, err := readSSMParameter(client, "arn:aws:ssm:us-east-1:123456789012:parameter/missing"); err == nil || !strings.Contains(err.Error(), "ParameterNotFound") {
+		t.Errorf("expected ParameterNotFound, got %v", err)
+	}
+	if _, err := readSSMParameter(client, "arn:aws:s3:::bucket"); err == nil {
+		t.Error("expected an error for a non-SSM ARN")
+	}
+}
+
+func TestMockKMSRoundTrip(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	b.put("aws_kms_key", "key-1", map[string]interface{}{"is_enabled": true})
+	b.put("aws_kms_key", "key-2", map[string]interface{}{"is_enabled": true})
+	b.put("aws_kms_alias", "alias/app", map[string]interface{}{
+		"name": "alias/app", "target_key_id": "arn:aws:kms:us-east-1:123456789012:key/key-1",
+	})
+
+	keyID, err := resolveKMSKeyID(client, "alias/app")
+	if err != nil || keyID != "key-1" {
+		t.Fatalf("resolveKMSKeyID(alias/app) = %q, %v", keyID, err)
+	}
+
+	context := map[string]string{"purpose": "db"}
+	blob, err := encryptMockKMS(client, "alias/app", "hunter2", context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "hunter2") || strings.Contains(string(raw), base64.StdEncoding.EncodeToString([]byte("hunter2"))) {
+		t.Errorf("the blob should not carry the plaintext: %s", raw)
+	}
+	if again, _ := encryptMockKMS(client, "key-1", "hunter2", context); again == blob {
+		t.Error("encrypting twice should give different blobs")
+	}
+
+	if got, err := decryptMockKMS(client, blob, "", context); err != nil || got != "hunter2" {
+		t.Errorf("decrypt = %q, %v", got, err)
+	}
+	if got, err := decryptMockKMS(client, blob, "alias/app", context); err != nil || got != "hunter2" {
+		t.Errorf("decrypt with the alias = %q, %v", got, err)
+	}
+	if _, err := decryptMockKMS(client, blob, "key-2", context); err == nil || !strings.Contains(err.Error(), "IncorrectKeyException") {
+		t.Errorf("expected IncorrectKeyException, got %v", err)
+	}
+	if _, err := decryptMockKMS(client, blob, "", nil); err == nil || !strings.Contains(err.Error(), "InvalidCiphertextException") {
+		t.Errorf("expected InvalidCiphertextException for a missing context, got %v", err)
+	}
+	if _, err := decryptMockKMS(client, "bm90LWFuLWVudmVsb3Bl", "", nil); err == nil {
+		t.Error("expected an error for a blob that is not a mock envelope")
+	}
+
+	b.put("aws_kms_key", "key-1", map[string]interface{}{"is_enabled": false})
+	if _, err := decryptMockKMS(client, blob, "", context); err == nil || !strings.Contains(err.Error(), "DisabledException") {
+		t.Errorf("expected DisabledException, got %v", err)
+	}
+}
+
+func TestEphemeralSSMParameterThroughMux(t *testing.T) {
+	b := &memoryBackend{resources: make(map[string]map[string]map[string]interface{})}
+	b.put("aws_ssm_parameter", "token", map[string]interface{}{"name": "token", "type": "String", "value": "abc", "version": 3.0})
+	server := configuredMuxServer(t, b)
+	ctx := context.Background()
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"aws_kms_secrets", "aws_secretsmanager_secret_version", "aws_ssm_parameter"} {
+		if _, ok := schemaResp.EphemeralResourceSchemas[name]; !ok {
+			t.Errorf("ephemeral resource %s not served", name)
+		}
+	}
+
+	objectType := schemaResp.EphemeralResourceSchemas["aws_ssm_parameter"].ValueType().(tftypes.Object)
+	values := make(map[string]tftypes.Value)
+	for name, attrType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+	values["arn"] = tftypes.NewValue(tftypes.String, "arn:aws:ssm:us-east-1:123456789012:parameter/token")
+	config, err := tfprotov5.NewDynamicValue(objectType, tftypes.NewValue(objectType, values))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.OpenEphemeralResource(ctx, &tfprotov5.OpenEphemeralResourceRequest{
+		TypeName: "aws_ssm_parameter",
+		Config:   &config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Fatalf("opening ephemeral resource: %s: %s", d.Summary, d.Detail)
+	}
+
+	result, err := resp.Result.Unmarshal(objectType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	result.As(&attrs)
+	var value string
+	attrs["value"].As(&value)
+	if value != "abc" {
+		t.Errorf("value = %q, want abc", value)
+	}
+}
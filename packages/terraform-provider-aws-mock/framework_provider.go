@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	fwschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// providerAddress is the registry address the mock stands in for, so that
+// configurations written for the real provider work unchanged.
+const providerAddress = "registry.terraform.io/hashicorp/aws"
+
+// newMuxServer serves the SDKv2 provider and the plugin framework provider
+// as one. The framework side carries what SDKv2 cannot serve, such as
+// provider-defined functions, ephemeral resources and list resources;
+// managed resources and data sources stay on the SDKv2 side.
+func newMuxServer(ctx context.Context) (tfprotov5.ProviderServer, error) {
+	sdkProvider := Provider()
+
+	providerSchema, err := frameworkProviderSchema(ctx, sdkProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both halves receive the same provider configuration. The SDKv2 side
+	// builds the MockClient and shares it rather than the framework side
+	// dialing the backend a second time.
+	clients := &sharedClient{}
+	configure := sdkProvider.ConfigureContextFunc
+	sdkProvider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		meta, diags := configure(ctx, d)
+		if client, ok := meta.(*MockClient); ok {
+			clients.set(client)
+		}
+		return meta, diags
+	}
+
+	listResources, err := newListResources(ctx, sdkProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	mux, err := tf5muxserver.NewMuxServer(ctx,
+		func() tfprotov5.ProviderServer { return withResourceVersions(sdkProvider.GRPCProvider()) },
+		providerserver.NewProtocol5(&frameworkProvider{schema: providerSchema, clients: clients, listResources: listResources}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return mux.ProviderServer(), nil
+}
+
+// sharedClient hands the MockClient configured by the SDKv2 provider to the
+// framework provider.
+type sharedClient struct {
+	mu     sync.Mutex
+	client *MockClient
+}
+
+func (s *sharedClient) set(client *MockClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+}
+
+// get returns the configured client, or an error if the provider has not
+// been configured yet.
+func (s *sharedClient) get() (*MockClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil {
+		return nil, fmt.Errorf("the provider has not been configured with a backend")
+	}
+	return s.client, nil
+}
+
+// frameworkProvider is the plugin framework half of the muxed provider.
+type frameworkProvider struct {
+	schema        fwschema.Schema
+	clients       *sharedClient
+	listResources []func() list.ListResource
+}
+
+var (
+	_ provider.Provider                       = (*frameworkProvider)(nil)
+	_ provider.ProviderWithEphemeralResources = (*frameworkProvider)(nil)
+	_ provider.ProviderWithFunctions          = (*frameworkProvider)(nil)
+	_ provider.ProviderWithListResources      = (*frameworkProvider)(nil)
+)
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "aws"
+}
+
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = p.schema
+}
+
+// Configure passes on the shared client rather than building one: the SDKv2
+// provider validates the configuration and builds the MockClient. The
+// client is looked up when an ephemeral resource opens or a list runs, so
+// it doesn't matter which half the mux configures first.
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	resp.EphemeralResourceData = p.clients
+	resp.ListResourceData = p.clients
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return nil
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newKmsSecretsEphemeralResource,
+		newSecretsmanagerSecretVersionEphemeralResource,
+		newSsmParameterEphemeralResource,
+	}
+}
+
+func (p *frameworkProvider) ListResources(ctx context.Context) []func() list.ListResource {
+	return p.listResources
+}
+
+func (p *frameworkProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		newArnBuildFunction,
+		newArnParseFunction,
+		newTrimIamRolePathFunction,
+	}
+}
+
+// frameworkProviderSchema mirrors the SDKv2 provider's configuration schema.
+// The mux server rejects servers whose provider schemas differ, so rather
+// than declaring it twice it is converted from what SDKv2 reports over the
+// protocol.
+func frameworkProviderSchema(ctx context.Context, sdkProvider *schema.Provider) (fwschema.Schema, error) {
+	configOnly := &schema.Provider{Schema: sdkProvider.Schema}
+	resp, err := configOnly.GRPCProvider().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		return fwschema.Schema{}, err
+	}
+	if resp.Provider == nil || resp.Provider.Block == nil {
+		return fwschema.Schema{}, nil
+	}
+
+	attributes, blocks, err := frameworkProviderBlock(resp.Provider.Block)
+	if err != nil {
+		return fwschema.Schema{}, err
+	}
+	return fwschema.Schema{Attributes: attributes, Blocks: blocks}, nil
+}
+
+func frameworkProviderBlock(block *tfprotov5.SchemaBlock) (map[string]fwschema.Attribute, map[string]fwschema.Block, error) {
+	attributes := make(map[string]fwschema.Attribute, len(block.Attributes))
+	for _, a := range block.Attributes {
+		description, markdown := splitDescription(a.Description, a.DescriptionKind)
+
+		switch {
+		case a.Type.Is(tftypes.String):
+			attributes[a.Name] = fwschema.StringAttribute{
+				Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+				Description: description, MarkdownDescription: markdown,
+			}
+		case a.Type.Is(tftypes.Number):
+			attributes[a.Name] = fwschema.NumberAttribute{
+				Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+				Description: description, MarkdownDescription: markdown,
+			}
+		case a.Type.Is(tftypes.Bool):
+			attributes[a.Name] = fwschema.BoolAttribute{
+				Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+				Description: description, MarkdownDescription: markdown,
+			}
+		default:
+			return nil, nil, fmt.Errorf("provider attribute %s has unsupported type %s", a.Name, a.Type)
+		}
+	}
+
+	blocks := make(map[string]fwschema.Block, len(block.BlockTypes))
+	for _, b := range block.BlockTypes {
+		if b.Nesting != tfprotov5.SchemaNestedBlockNestingModeList {
+			return nil, nil, fmt.Errorf("provider block %s has unsupported nesting %s", b.TypeName, b.Nesting)
+		}
+		nestedAttributes, nestedBlocks, err := frameworkProviderBlock(b.Block)
+		if err != nil {
+			return nil, nil, err
+		}
+		description, markdown := splitDescription(b.Block.Description, b.Block.DescriptionKind)
+		// MaxItems is left off: the mux ignores it when comparing schemas and
+		// the SDKv2 provider enforces it during validation.
+		blocks[b.TypeName] = fwschema.ListNestedBlock{
+			NestedObject: fwschema.NestedBlockObject{Attributes: nestedAttributes, Blocks: nestedBlocks},
+			Description:  description, MarkdownDescription: markdown,
+		}
+	}
+	return attributes, blocks, nil
+}
+
+// splitDescription routes a protocol description to the framework's plain or
+// Markdown field according to its kind.
+func splitDescription(text string, kind tfprotov5.StringKind) (description, markdown string) {
+	if kind == tfprotov5.StringKindMarkdown {
+		return "", text
+	}
+	return text, ""
+}
+
+// ephemeralClient is embedded by ephemeral resources to receive the shared
+// client through Configure.
+type ephemeralClient struct {
+	clients *sharedClient
+}
+
+func (e *ephemeralClient) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	clients, ok := req.ProviderData.(*sharedClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("expected *sharedClient, got %T", req.ProviderData))
+		return
+	}
+	e.clients = clients
+}
+
+// client returns the configured MockClient.
+func (e *ephemeralClient) client() (*MockClient, error) {
+	if e.clients == nil {
+		return nil, fmt.Errorf("the provider has not been configured with a backend")
+	}
+	return e.clients.get()
+}
@@ -0,0 +1,7 @@
+package main
+
+// The hand-written resources' schema files are generated from the provider
+// schema JSON with the type mapping the dynamic resources use. Run go
+// generate after updating schema/aws-provider-schema.json, and mark
+// customized entries schemagen:keep so they survive.
+//go:generate go run ./cmd/generate-schema aws_eip aws_iam_instance_profile aws_iam_policy aws_iam_role aws_iam_role_policy_attachment aws_instance aws_kms_ciphertext aws_main_route_table_association aws_nat_gateway aws_route aws_route_table aws_route_table_association aws_s3_bucket aws_s3_bucket_policy aws_s3_object aws_security_group aws_subnet aws_vpc
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"terraform-provider-aws-mock/internal/providerschema"
+	"terraform-provider-aws-mock/internal/schemagen"
+)
+
+// generatedTypes returns the resource types the go:generate directive in
+// generate.go names.
+func generatedTypes(t *testing.T) []string {
+	t.Helper()
+	src, err := os.ReadFile("generate.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(src), "\n") {
+		if args, ok := strings.CutPrefix(line, "//go:generate go run ./cmd/generate-schema "); ok {
+			return strings.Fields(args)
+		}
+	}
+	t.Fatal("generate.go has no generate-schema directive")
+	return nil
+}
+
+func TestGeneratedSchemasUpToDate(t *testing.T) {
+	schemas, err := providerschema.Parse(rawProviderSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, resourceType := range generatedTypes(t) {
+		t.Run(resourceType, func(t *testing.T) {
+			resource, ok := schemas[resourceType]
+			if !ok {
+				t.Skipf("%s is not in the embedded schema", resourceType)
+			}
+
+			existing, err := os.ReadFile(schemagen.FileName(resourceType))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := schemagen.Generate(resourceType, resource.Block, existing)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != string(existing) {
+				t.Errorf("%s is out of date; run go generate", schemagen.FileName(resourceType))
+			}
+		})
+	}
+}
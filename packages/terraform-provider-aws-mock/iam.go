@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	errCodeNoSuchEntity   = "NoSuchEntity"
+	errCodeDeleteConflict = "DeleteConflict"
+)
+
+// iamName picks the name of a new IAM entity from name, then name_prefix,
+// falling back to a generated "terraform-" name like the real provider.
+func iamName(d *schema.ResourceData) string {
+	if name, ok := d.GetOk("name"); ok {
+		return name.(string)
+	}
+	if prefix, ok := d.GetOk("name_prefix"); ok {
+		return id.PrefixedUniqueId(prefix.(string))
+	}
+	return id.UniqueId()
+}
+
+// iamNameFromARN returns the entity name at the end of an IAM ARN, or s
+// unchanged when it is already a name.
+func iamNameFromARN(s string) string {
+	if !strings.HasPrefix(s, "arn:") {
+		return s
+	}
+	return s[strings.LastIndex(s, "/")+1:]
+}
+
+// isAWSManagedPolicy reports whether arn names a policy owned by AWS rather
+// than the account, which the mock accepts without a lookup.
+func isAWSManagedPolicy(arn string) bool {
+	return strings.HasPrefix(arn, "arn:aws:iam::aws:policy/")
+}
+
+// readIamRole fails with NoSuchEntity when the named role doesn't exist.
+func readIamRole(client *MockClient, name, argument string) (*ResourceResponse, diag.Diagnostics) {
+	role, err := client.ReadResource("aws_iam_role", iamNameFromARN(name))
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	if role == nil {
+		return nil, diag.Errorf("%s: The role with name %s cannot be found (referenced by %s)", errCodeNoSuchEntity, name, argument)
+	}
+	return role, nil
+}
+
+// readIamPolicy fails with NoSuchEntity when a customer managed policy
+// doesn't exist. AWS managed policies always exist.
+func readIamPolicy(client *MockClient, arn string) diag.Diagnostics {
+	if isAWSManagedPolicy(arn) {
+		return nil
+	}
+	policy, err := client.ReadResource("aws_iam_policy", iamNameFromARN(arn))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if policy == nil || (stringAttribute(policy, "arn") != "" && stringAttribute(policy, "arn") != arn) {
+		return diag.Errorf("%s: Policy %s does not exist or is not attachable", errCodeNoSuchEntity, arn)
+	}
+	return nil
+}
+
+// validateInstanceProfile checks the iam_instance_profile an instance is
+// launched with.
+func validateInstanceProfile(client *MockClient, profile string) diag.Diagnostics {
+	if profile == "" {
+		return nil
+	}
+	name := iamNameFromARN(profile)
+	result, err := client.ReadResource("aws_iam_instance_profile", name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if result == nil {
+		return diag.Errorf("InvalidParameterValue: Value (%s) for parameter iamInstanceProfile.name is invalid. "+
+			"Invalid IAM Instance Profile name", name)
+	}
+	return nil
+}
+
+// deleteConflictDiag reports an IAM delete refused because other entities
+// still depend on the one being deleted.
+func deleteConflictDiag(resourceType, id, reason string) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("deleting %s (%s): %s", resourceType, id, errCodeDeleteConflict),
+		Detail:   reason,
+	}}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProviderDeclaresIamResources(t *testing.T) {
+	p := Provider()
+	for _, name := range []string{
+		"aws_iam_role", "aws_iam_policy", "aws_iam_role_policy_attachment", "aws_iam_instance_profile",
+	} {
+		res := p.ResourcesMap[name]
+		if res == nil {
+			t.Errorf("%s resource missing from provider", name)
+			continue
+		}
+		if res.CreateContext == nil || res.ReadContext == nil || res.DeleteContext == nil {
+			t.Errorf("%s should have create, read and delete", name)
+		}
+	}
+
+	attachment := p.ResourcesMap["aws_iam_role_policy_attachment"]
+	if attachment.UpdateContext != nil {
+		t.Error("aws_iam_role_policy_attachment should be replaced, not updated")
+	}
+	for _, name := range []string{"role", "policy_arn"} {
+		if !attachment.Schema[name].ForceNew {
+			t.Errorf("aws_iam_role_policy_attachment %s should force a new resource", name)
+		}
+	}
+
+	role := p.ResourcesMap["aws_iam_role"].Schema["assume_role_policy"]
+	if role.ValidateFunc == nil || role.DiffSuppressFunc == nil {
+		t.Error("assume_role_policy should validate the trust policy and suppress equivalent JSON diffs")
+	}
+}
+
+func TestIamRoleRejectsInvalidTrustPolicy(t *testing.T) {
+	res := Provider().ResourcesMap["aws_iam_role"]
+	_, errs := res.Schema["assume_role_policy"].ValidateFunc(`{"Statement":{"Effect":"Allow","Action":"sts:AssumeRole"}}`, "assume_role_policy")
+	if len(errs) == 0 || !strings.Contains(errs[0].Error(), "Principal") {
+		t.Errorf("expected a missing Principal error, got %v", errs)
+	}
+}
+
+func TestIamNameFromPrefix(t *testing.T) {
+	res := Provider().ResourcesMap["aws_iam_role"]
+	d := res.TestResourceData()
+	d.Set("name_prefix", "app-")
+	if name := iamName(d); !strings.HasPrefix(name, "app-") || len(name) <= len("app-") {
+		t.Errorf("expected a generated name with prefix app-, got %s", name)
+	}
+}
+
+func TestRolePolicyAttachmentRequiresExistingEntities(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	b.put("aws_iam_role", "app", map[string]interface{}{"name": "app"})
+	b.put("aws_iam_policy", "read", map[string]interface{}{"arn": "arn:aws:iam::123456789012:policy/read"})
+
+	if _, err := createResource(t, "aws_iam_role_policy_attachment", client, map[string]interface{}{
+		"role": "app", "policy_arn": "arn:aws:iam::123456789012:policy/read",
+	}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := createResource(t, "aws_iam_role_policy_attachment", client, map[string]interface{}{
+		"role": "app", "policy_arn": "arn:aws:iam::aws:policy/ReadOnlyAccess",
+	}); err != nil {
+		t.Errorf("AWS managed policies should attach without a lookup: %v", err)
+	}
+
+	_, err := createResource(t, "aws_iam_role_policy_attachment", client, map[string]interface{}{
+		"role": "missing", "policy_arn": "arn:aws:iam::123456789012:policy/read",
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), errCodeNoSuchEntity) {
+		t.Errorf("expected NoSuchEntity for a missing role, got %v", err)
+	}
+
+	_, err = createResource(t, "aws_iam_role_policy_attachment", client, map[string]interface{}{
+		"role": "app", "policy_arn": "arn:aws:iam::123456789012:policy/missing",
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), errCodeNoSuchEntity) {
+		t.Errorf("expected NoSuchEntity for a missing policy, got %v", err)
+	}
+}
+
+func TestIamRoleDeleteConflicts(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	b.put("aws_iam_role", "app", map[string]interface{}{"name": "app"})
+	b.put("aws_iam_role_policy_attachment", "att-1", map[string]interface{}{"role": "app", "policy_arn": "arn:aws:iam::aws:policy/ReadOnlyAccess"})
+
+	res := Provider().ResourcesMap["aws_iam_role"]
+	d := res.TestResourceData()
+	d.SetId("app")
+
+	diags := res.DeleteContext(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, errCodeDeleteConflict) {
+		t.Fatalf("expected DeleteConflict with a policy attached, got %v", diags)
+	}
+
+	d.Set("force_detach_policies", true)
+	if diags := res.DeleteContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("force_detach_policies should detach and delete: %v", diags)
+	}
+	if b.get("aws_iam_role_policy_attachment", "att-1") != nil || b.get("aws_iam_role", "app") != nil {
+		t.Error("expected the attachment and role to be deleted")
+	}
+
+	b.put("aws_iam_role", "web", map[string]interface{}{"name": "web"})
+	b.put("aws_iam_instance_profile", "web-profile", map[string]interface{}{"role": "web"})
+	d.SetId("web")
+	diags = res.DeleteContext(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "instance profile") {
+		t.Errorf("expected DeleteConflict for a role in an instance profile, got %v", diags)
+	}
+}
+
+func TestIamPolicyDeleteConflictWhenAttached(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	arn := "arn:aws:iam::123456789012:policy/read"
+	b.put("aws_iam_policy", "read", map[string]interface{}{"arn": arn})
+	b.put("aws_iam_role_policy_attachment", "att-1", map[string]interface{}{"role": "app", "policy_arn": arn})
+
+	res := Provider().ResourcesMap["aws_iam_policy"]
+	d := res.TestResourceData()
+	d.SetId("read")
+	if diags := res.ReadContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected read error: %v", diags)
+	}
+	if d.Get("attachment_count").(int) != 1 {
+		t.Errorf("expected attachment_count 1, got %d", d.Get("attachment_count"))
+	}
+
+	diags := res.DeleteContext(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, errCodeDeleteConflict) {
+		t.Errorf("expected DeleteConflict, got %v", diags)
+	}
+}
+
+func TestInstanceProfileLinkage(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	b.put("aws_iam_role", "web", map[string]interface{}{"name": "web"})
+
+	if _, err := createResource(t, "aws_iam_instance_profile", client, map[string]interface{}{"name": "web", "role": "missing"}); err == nil {
+		t.Error("expected an error for an instance profile with a missing role")
+	}
+	if _, err := createResource(t, "aws_iam_instance_profile", client, map[string]interface{}{"name": "web", "role": "web"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := createResource(t, "aws_instance", client, map[string]interface{}{"iam_instance_profile": "web"}); err != nil {
+		t.Errorf("instance with an existing profile should launch: %v", err)
+	}
+	_, err := createResource(t, "aws_instance", client, map[string]interface{}{"iam_instance_profile": "nope"})
+	if err == nil || !strings.Contains(err.Error(), "Invalid IAM Instance Profile name") {
+		t.Errorf("expected an invalid instance profile error, got %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ipAllocator hands out private IPs per subnet. Callers pass the addresses
+// taken in the backend, from takenPrivateIPs; the allocator adds the ones
+// it handed out itself, whose instances the backend may not hold yet, so
+// that resources created in one apply never share an address.
+type ipAllocator struct {
+	mu   sync.Mutex
+	used map[string]map[string]bool
+}
+
+// subnet returns the addresses handed out in subnetID. The caller holds mu.
+func (a *ipAllocator) subnet(subnetID string) map[string]bool {
+	if a.used == nil {
+		a.used = make(map[string]map[string]bool)
+	}
+	if a.used[subnetID] == nil {
+		a.used[subnetID] = make(map[string]bool)
+	}
+	return a.used[subnetID]
+}
+
+// allocate picks the lowest free host address in cidr that isn't taken,
+// skipping the five addresses AWS reserves in every subnet: the network
+// address, the VPC router (+1), DNS (+2), one held for future use (+3) and
+// the broadcast address. The same subnet contents always give the same
+// address, so plans can be snapshotted.
+func (a *ipAllocator) allocate(subnetID, cidr string, taken map[string]bool) (string, error) {
+	first, last, err := usableHostRange(cidr)
+	if err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	used := a.subnet(subnetID)
+
+	for addr := first; addr <= last; addr++ {
+		ip := uint32ToIP(addr).String()
+		if !used[ip] && !taken[ip] {
+			used[ip] = true
+			return ip, nil
+		}
+	}
+	return "", fmt.Errorf("InsufficientFreeAddressesInSubnet: no free addresses left in %s (%s)", subnetID, cidr)
+}
+
+// reserve records an address chosen by the user, refusing one that is
+// taken or already handed out.
+func (a *ipAllocator) reserve(subnetID, ip string, taken map[string]bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	used := a.subnet(subnetID)
+	if used[ip] || taken[ip] {
+		return fmt.Errorf("InvalidIPAddress.InUse: Address %s is in use in subnet %s", ip, subnetID)
+	}
+	used[ip] = true
+	return nil
+}
+
+// release frees an address once its instance is gone, or was never created.
+func (a *ipAllocator) release(subnetID, ip string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.subnet(subnetID), ip)
+}
+
+// privateIPHolders are the resource types given addresses by ipAllocator.
+var privateIPHolders = []string{"aws_instance", "aws_nat_gateway"}
+
+// takenPrivateIPs returns the private IPs the backend's instances and NAT
+// gateways hold in subnetID, other than the one of the resource with ID
+// self.
+func takenPrivateIPs(client *MockClient, subnetID, self string) (map[string]bool, error) {
+	taken := make(map[string]bool)
+	for _, resourceType := range privateIPHolders {
+		holders, err := client.ListResources(resourceType, map[string]string{"subnet_id": subnetID})
+		if err != nil {
+			return nil, err
+		}
+		for i := range holders {
+			if ip := stringAttribute(&holders[i], "private_ip"); ip != "" && holders[i].ID != self {
+				taken[ip] = true
+			}
+		}
+	}
+	return taken, nil
+}
+
+// usableHostRange returns the first and last assignable IPv4 addresses of
+// cidr as integers.
+func usableHostRange(cidr string) (uint32, uint32, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid subnet cidr_block %q: %w", cidr, err)
+	}
+	ip4 := network.IP.To4()
+	if ip4 == nil {
+		return 0, 0, fmt.Errorf("subnet cidr_block %q is not IPv4", cidr)
+	}
+	ones, bits := network.Mask.Size()
+	if bits-ones < 3 {
+		return 0, 0, fmt.Errorf("subnet cidr_block %q is too small to hold instances", cidr)
+	}
+
+	base := binary.BigEndian.Uint32(ip4)
+	broadcast := base | ^binary.BigEndian.Uint32(net.IP(network.Mask).To4())
+	return base + 4, broadcast - 1, nil
+}
+
+// validatePrivateIP checks a user-chosen private_ip against the subnet.
+func validatePrivateIP(ip, cidr string) error {
+	parsed := net.ParseIP(ip).To4()
+	if parsed == nil {
+		return fmt.Errorf("InvalidParameterValue: private_ip %q is not a valid IPv4 address", ip)
+	}
+	first, last, err := usableHostRange(cidr)
+	if err != nil {
+		return err
+	}
+	addr := binary.BigEndian.Uint32(parsed)
+	if addr < first || addr > last {
+		return fmt.Errorf("InvalidParameterValue: private_ip %s is not a usable address in subnet %s; "+
+			"the first four addresses and the last address are reserved", ip, cidr)
+	}
+	return nil
+}
+
+func uint32ToIP(v uint32) net.IP {
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, v)
+	return ip
+}
+
+// privateDNSName follows EC2's naming: ip-10-0-1-4.ec2.internal in
+// us-east-1 and ip-10-0-1-4.<region>.compute.internal elsewhere.
+func privateDNSName(ip, region string) string {
+	host := "ip-" + strings.ReplaceAll(ip, ".", "-")
+	if region == "" || region == "us-east-1" {
+		return host + ".ec2.internal"
+	}
+	return fmt.Sprintf("%s.%s.compute.internal", host, region)
+}
+
+// publicDNSName follows EC2's naming for public hostnames.
+func publicDNSName(ip, region string) string {
+	host := "ec2-" + strings.ReplaceAll(ip, ".", "-")
+	if region == "" || region == "us-east-1" {
+		return host + ".compute-1.amazonaws.com"
+	}
+	return fmt.Sprintf("%s.%s.compute.amazonaws.com", host, region)
+}
+
+// randomPublicIP returns an address from the 3.0.0.0/8 block AWS uses for
+// EC2 public IPs, for Elastic IPs, which have nothing to derive one from.
+func randomPublicIP() string {
+	return fmt.Sprintf("3.%d.%d.%d", rand.IntN(256), rand.IntN(256), 1+rand.IntN(254))
+}
+
+// releaseNetwork frees the private IP network handed out in subnetID, for
+// when the resource it was meant for could not be created.
+func releaseNetwork(client *MockClient, subnetID string, network map[string]interface{}) {
+	if ip, _ := network["private_ip"].(string); ip != "" {
+		client.ips.release(subnetID, ip)
+	}
+}
+
+// instanceNetworkAttributes derives an instance's network fields from its
+// subnet: private_ip comes from the subnet's CIDR, availability_zone is
+// inherited, and a public IP is assigned only when associate_public_ip_address
+// or the subnet's map_public_ip_on_launch asks for one. It returns nil when
+// the instance has no subnet_id.
+func instanceNetworkAttributes(d *schema.ResourceData, client *MockClient) (map[string]interface{}, diag.Diagnostics) {
+	subnetID := d.Get("subnet_id").(string)
+	if subnetID == "" {
+		return nil, nil
+	}
+
+	subnet, err := client.ReadResource("aws_subnet", subnetID)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	if subnet == nil {
+		return nil, diag.Errorf("InvalidSubnetID.NotFound: The subnet ID '%s' does not exist", subnetID)
+	}
+
+	cidr, _ := subnet.Attributes["cidr_block"].(string)
+	subnetAZ, _ := subnet.Attributes["availability_zone"].(string)
+	mapPublicIP, _ := subnet.Attributes["map_public_ip_on_launch"].(bool)
+
+	network := make(map[string]interface{})
+
+	if az, ok := d.GetOk("availability_zone"); ok && subnetAZ != "" && az.(string) != subnetAZ {
+		return nil, diag.Errorf("InvalidParameterValue: availability_zone %s does not match subnet %s, which is in %s",
+			az, subnetID, subnetAZ)
+	}
+	if subnetAZ != "" {
+		network["availability_zone"] = subnetAZ
+	}
+
+	if cidr != "" {
+		taken, err := takenPrivateIPs(client, subnetID, d.Id())
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		privateIP := d.Get("private_ip").(string)
+		if privateIP != "" {
+			if err := validatePrivateIP(privateIP, cidr); err != nil {
+				return nil, diag.FromErr(err)
+			}
+			if err := client.ips.reserve(subnetID, privateIP, taken); err != nil {
+				return nil, diag.FromErr(err)
+			}
+		} else if privateIP, err = client.ips.allocate(subnetID, cidr, taken); err != nil {
+			return nil, diag.FromErr(err)
+		}
+		network["private_ip"] = privateIP
+		network["private_dns"] = privateDNSName(privateIP, client.Region)
+	}
+
+	// Only an explicit setting overrides the subnet, so read the
+	// configuration, where unset is null rather than false.
+	associatePublicIP := mapPublicIP
+	if v, diags := d.GetRawConfigAt(cty.GetAttrPath("associate_public_ip_address")); !diags.HasError() && v.IsKnown() && !v.IsNull() {
+		associatePublicIP = v.True()
+	}
+	network["associate_public_ip_address"] = associatePublicIP
+	if associatePublicIP {
+		// Derived from the private address, so it is as stable as that.
+		privateIP, _ := network["private_ip"].(string)
+		publicIP := newValueSynthesizer("", client.Region).publicIP(subnetID + "/" + privateIP)
+		network["public_ip"] = publicIP
+		network["public_dns"] = publicDNSName(publicIP, client.Region)
+	} else {
+		network["public_ip"] = ""
+		network["public_dns"] = ""
+	}
+
+	return network, nil
+}
+
+// networkAttributesApplied reports whether the backend kept every derived
+// network value.
+func networkAttributesApplied(attrs, network map[string]interface{}) bool {
+	for key, want := range network {
+		if attrs[key] != want {
+			return false
+		}
+	}
+	return true
+}
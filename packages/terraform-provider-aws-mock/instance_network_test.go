@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUsableHostRangeSkipsReservedAddresses(t *testing.T) {
+	first, last, err := usableHostRange("10.0.1.0/24")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := uint32ToIP(first).String(); got != "10.0.1.4" {
+		t.Errorf("first usable = %s, want 10.0.1.4", got)
+	}
+	if got := uint32ToIP(last).String(); got != "10.0.1.254" {
+		t.Errorf("last usable = %s, want 10.0.1.254", got)
+	}
+}
+
+func TestIPAllocatorExhaustsSubnet(t *testing.T) {
+	var alloc ipAllocator
+
+	// A /29 has 8 addresses, 5 of them reserved; the rest go lowest first.
+	for _, want := range []string{"10.0.0.4", "10.0.0.5", "10.0.0.6"} {
+		ip, err := alloc.allocate("subnet-1", "10.0.0.0/29", nil)
+		if err != nil {
+			t.Fatalf("allocating %s failed: %v", want, err)
+		}
+		if ip != want {
+			t.Errorf("allocated %s, want %s", ip, want)
+		}
+	}
+
+	if _, err := alloc.allocate("subnet-1", "10.0.0.0/29", nil); err == nil {
+		t.Error("expected an error once the subnet is full")
+	}
+	if _, err := alloc.allocate("subnet-2", "10.0.0.0/29", nil); err != nil {
+		t.Errorf("other subnets should allocate independently: %v", err)
+	}
+}
+
+func TestIPAllocatorSkipsTakenAddresses(t *testing.T) {
+	var alloc ipAllocator
+	taken := map[string]bool{"10.0.0.4": true, "10.0.0.6": true}
+
+	for i := 0; i < 5; i++ {
+		alloc.release("subnet-1", "10.0.0.5")
+		ip, err := alloc.allocate("subnet-1", "10.0.0.0/29", taken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ip != "10.0.0.5" {
+			t.Fatalf("allocated %s, want the only free address 10.0.0.5", ip)
+		}
+	}
+
+	if err := alloc.reserve("subnet-1", "10.0.0.5", nil); err == nil {
+		t.Error("reserving an address handed out already should fail")
+	}
+	if err := alloc.reserve("subnet-1", "10.0.0.6", taken); err == nil || !strings.Contains(err.Error(), "InvalidIPAddress.InUse") {
+		t.Errorf("reserving a taken address: got %v, want InvalidIPAddress.InUse", err)
+	}
+	alloc.release("subnet-1", "10.0.0.5")
+	if err := alloc.reserve("subnet-1", "10.0.0.5", taken); err != nil {
+		t.Errorf("a released address should be free again: %v", err)
+	}
+}
+
+func TestInstanceCreateFailureReleasesPrivateIP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/resource/aws_subnet/subnet-abc123":
+			json.NewEncoder(w).Encode(ResourceResponse{ID: "subnet-abc123", Attributes: map[string]interface{}{
+				"cidr_block": "10.0.1.0/24",
+			}})
+		case r.Method == "GET":
+			json.NewEncoder(w).Encode(map[string]interface{}{"resources": []ResourceResponse{}})
+		default:
+			w.WriteHeader(500)
+		}
+	}))
+	defer server.Close()
+
+	res := Provider().ResourcesMap["aws_instance"]
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client(), Region: "us-east-1"}
+	for i := 0; i < 2; i++ {
+		d := res.TestResourceData()
+		d.Set("subnet_id", "subnet-abc123")
+		if diags := res.CreateContext(context.Background(), d, client); !diags.HasError() {
+			t.Fatal("expected the create to fail")
+		}
+	}
+
+	if ip, err := client.ips.allocate("subnet-abc123", "10.0.1.0/24", nil); err != nil || ip != "10.0.1.4" {
+		t.Errorf("next allocation = %s, %v; want 10.0.1.4, released by the failed creates", ip, err)
+	}
+}
+
+func TestValidatePrivateIP(t *testing.T) {
+	tests := []struct {
+		ip    string
+		valid bool
+	}{
+		{"10.0.1.4", true},
+		{"10.0.1.254", true},
+		{"10.0.1.0", false},
+		{"10.0.1.1", false},
+		{"10.0.1.3", false},
+		{"10.0.1.255", false},
+		{"10.0.2.10", false},
+		{"not-an-ip", false},
+	}
+	for _, tt := range tests {
+		err := validatePrivateIP(tt.ip, "10.0.1.0/24")
+		if (err == nil) != tt.valid {
+			t.Errorf("validatePrivateIP(%s) error = %v, want valid=%v", tt.ip, err, tt.valid)
+		}
+	}
+}
+
+func TestDNSNamesFollowRegion(t *testing.T) {
+	if got := privateDNSName("10.0.1.4", "us-east-1"); got != "ip-10-0-1-4.ec2.internal" {
+		t.Errorf("unexpected us-east-1 private DNS: %s", got)
+	}
+	if got := privateDNSName("10.0.1.4", "eu-west-1"); got != "ip-10-0-1-4.eu-west-1.compute.internal" {
+		t.Errorf("unexpected eu-west-1 private DNS: %s", got)
+	}
+	if got := publicDNSName("3.1.2.3", "eu-west-1"); got != "ec2-3-1-2-3.eu-west-1.compute.amazonaws.com" {
+		t.Errorf("unexpected eu-west-1 public DNS: %s", got)
+	}
+}
+
+// instanceBackend serves a subnet and echoes instance writes, optionally
+// overwriting private_ip the way a backend with its own synthesizer would.
+func instanceBackend(t *testing.T, mapPublicIP, overwrite bool, methods *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == "/resource/aws_subnet/subnet-abc123" {
+			json.NewEncoder(w).Encode(ResourceResponse{ID: "subnet-abc123", Attributes: map[string]interface{}{
+				"cidr_block":              "10.0.1.0/24",
+				"availability_zone":       "us-east-1b",
+				"map_public_ip_on_launch": mapPublicIP,
+			}})
+			return
+		}
+
+		// Another instance in the subnet already holds 10.0.1.20.
+		if r.Method == "GET" && (r.URL.Path == "/resource/aws_instance" || r.URL.Path == "/resource/aws_nat_gateway") {
+			var resources []ResourceResponse
+			if r.URL.Path == "/resource/aws_instance" && r.URL.Query().Get("subnet_id") == "subnet-abc123" {
+				resources = append(resources, ResourceResponse{ID: "i-other", Attributes: map[string]interface{}{
+					"subnet_id":  "subnet-abc123",
+					"private_ip": "10.0.1.20",
+				}})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"resources": resources})
+			return
+		}
+
+		*methods = append(*methods, r.Method)
+		var body struct {
+			Attributes map[string]interface{} `json:"attributes"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		attrs := body.Attributes
+		if r.Method == "POST" {
+			attrs["primary_network_interface_id"] = "eni-abc123"
+			if overwrite {
+				attrs["private_ip"] = "172.31.9.9"
+			}
+			w.WriteHeader(201)
+		}
+		json.NewEncoder(w).Encode(ResourceResponse{ID: "i-abc123", Attributes: attrs})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestInstanceCreateDerivesNetworkFromSubnet(t *testing.T) {
+	var methods []string
+	server := instanceBackend(t, false, false, &methods)
+
+	res := Provider().ResourcesMap["aws_instance"]
+	d := res.TestResourceData()
+	d.Set("subnet_id", "subnet-abc123")
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client(), Region: "us-east-1"}
+	if diags := res.CreateContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	privateIP := d.Get("private_ip").(string)
+	if err := validatePrivateIP(privateIP, "10.0.1.0/24"); err != nil {
+		t.Errorf("private_ip %s not allocated from subnet: %v", privateIP, err)
+	}
+	if d.Get("private_dns").(string) != privateDNSName(privateIP, "us-east-1") {
+		t.Errorf("private_dns %s does not match private_ip %s", d.Get("private_dns"), privateIP)
+	}
+	if d.Get("availability_zone").(string) != "us-east-1b" {
+		t.Errorf("expected availability_zone inherited from subnet, got %s", d.Get("availability_zone"))
+	}
+	if d.Get("public_ip").(string) != "" {
+		t.Errorf("expected no public IP, got %s", d.Get("public_ip"))
+	}
+	if strings.Join(methods, ",") != "POST" {
+		t.Errorf("expected a single create, got %v", methods)
+	}
+}
+
+func TestInstanceCreatePublicIPFromSubnetDefault(t *testing.T) {
+	var methods []string
+	server := instanceBackend(t, true, false, &methods)
+
+	res := Provider().ResourcesMap["aws_instance"]
+	d := res.TestResourceData()
+	d.Set("subnet_id", "subnet-abc123")
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client(), Region: "us-east-1"}
+	if diags := res.CreateContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if d.Get("public_ip").(string) == "" || d.Get("public_dns").(string) == "" {
+		t.Error("expected a public IP when the subnet maps public IPs on launch")
+	}
+}
+
+func TestInstanceCreateWritesBackNetworkFields(t *testing.T) {
+	var methods []string
+	server := instanceBackend(t, false, true, &methods)
+
+	res := Provider().ResourcesMap["aws_instance"]
+	d := res.TestResourceData()
+	d.Set("subnet_id", "subnet-abc123")
+	d.Set("private_ip", "10.0.1.50")
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client(), Region: "us-east-1"}
+	if diags := res.CreateContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if strings.Join(methods, ",") != "POST,PUT" {
+		t.Errorf("expected create then write-back update, got %v", methods)
+	}
+	if d.Get("private_ip").(string) != "10.0.1.50" {
+		t.Errorf("expected private_ip 10.0.1.50, got %s", d.Get("private_ip"))
+	}
+}
+
+func TestInstanceCreateRejectsMismatchedAvailabilityZone(t *testing.T) {
+	var methods []string
+	server := instanceBackend(t, false, false, &methods)
+
+	res := Provider().ResourcesMap["aws_instance"]
+	d := res.TestResourceData()
+	d.Set("subnet_id", "subnet-abc123")
+	d.Set("availability_zone", "us-east-1a")
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	if diags := res.CreateContext(context.Background(), d, client); !diags.HasError() {
+		t.Fatal("expected an error for an availability_zone that differs from the subnet")
+	}
+	if len(methods) != 0 {
+		t.Errorf("nothing should be created, got %v", methods)
+	}
+}
+
+func TestInstanceCreateRejectsPrivateIPInUse(t *testing.T) {
+	var methods []string
+	server := instanceBackend(t, false, false, &methods)
+
+	res := Provider().ResourcesMap["aws_instance"]
+	d := res.TestResourceData()
+	d.Set("subnet_id", "subnet-abc123")
+	d.Set("private_ip", "10.0.1.20")
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client(), Region: "us-east-1"}
+	diags := res.CreateContext(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "InvalidIPAddress.InUse") {
+		t.Errorf("expected InvalidIPAddress.InUse, got %v", diags)
+	}
+	if len(methods) != 0 {
+		t.Errorf("nothing should be created, got %v", methods)
+	}
+}
+
+func TestInstanceCreateSkipsAddressesOfExistingInstances(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	b.put("aws_subnet", "subnet-a", map[string]interface{}{"cidr_block": "10.0.0.0/29"})
+	b.put("aws_instance", "i-1", map[string]interface{}{"subnet_id": "subnet-a", "private_ip": "10.0.0.4"})
+	b.put("aws_instance", "i-2", map[string]interface{}{"subnet_id": "subnet-a", "private_ip": "10.0.0.6"})
+
+	// A fresh client stands in for a later terraform run, which knows
+	// nothing of the addresses handed out before.
+	d, err := createResource(t, "aws_instance", client, map[string]interface{}{"subnet_id": "subnet-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Get("private_ip"); got != "10.0.0.5" {
+		t.Errorf("private_ip = %v, want the only free address 10.0.0.5", got)
+	}
+}
@@ -0,0 +1,278 @@
+// Package providerschema reads the AWS provider's schema JSON, as printed by
+// terraform providers schema -json, and converts it to SDKv2 schemas. It is
+// the one place JSON types map to SDK types: the provider builds its
+// dynamic resources with it, and the schema file generator renders the
+// hand-written resources' schema files from it.
+package providerschema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ProviderAddress is the provider whose resource schemas Parse returns.
+const ProviderAddress = "registry.terraform.io/hashicorp/aws"
+
+// --- JSON schema types ---
+
+type file struct {
+	ProviderSchemas map[string]providerEntry `json:"provider_schemas"`
+}
+
+type providerEntry struct {
+	ResourceSchemas map[string]Resource `json:"resource_schemas"`
+}
+
+type Resource struct {
+	Block Block `json:"block"`
+}
+
+type Block struct {
+	Attributes map[string]Attribute `json:"attributes"`
+	BlockTypes map[string]BlockType `json:"block_types"`
+}
+
+type Attribute struct {
+	Type      json.RawMessage `json:"type"`
+	Optional  bool            `json:"optional"`
+	Required  bool            `json:"required"`
+	Computed  bool            `json:"computed"`
+	Sensitive bool            `json:"sensitive"`
+	WriteOnly bool            `json:"write_only"`
+}
+
+type BlockType struct {
+	NestingMode string `json:"nesting_mode"`
+	Block       Block  `json:"block"`
+	MinItems    int    `json:"min_items"`
+	MaxItems    int    `json:"max_items"`
+}
+
+// --- Parse ---
+
+// Parse returns the resource schemas of the AWS provider in raw.
+func Parse(raw []byte) (map[string]Resource, error) {
+	var f file
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("parsing provider schema: %w", err)
+	}
+	entry, ok := f.ProviderSchemas[ProviderAddress]
+	if !ok {
+		return nil, fmt.Errorf("missing %s in provider schema", ProviderAddress)
+	}
+	return entry.ResourceSchemas, nil
+}
+
+// --- Type conversion ---
+
+// ConvertType returns the SDK type, and Elem, of a JSON attribute type.
+// Numbers become TypeInt.
+func ConvertType(raw json.RawMessage) (schema.ValueType, interface{}) {
+	// Try string first (primitive)
+	var str string
+	if json.Unmarshal(raw, &str) == nil {
+		switch str {
+		case "string":
+			return schema.TypeString, nil
+		case "number":
+			return schema.TypeInt, nil
+		case "bool":
+			return schema.TypeBool, nil
+		default:
+			return schema.TypeString, nil
+		}
+	}
+
+	// Array form: ["list", "string"], ["set", ["object", {...}]], etc.
+	var arr []json.RawMessage
+	if json.Unmarshal(raw, &arr) != nil || len(arr) < 2 {
+		return schema.TypeString, nil
+	}
+
+	var kind string
+	if json.Unmarshal(arr[0], &kind) != nil {
+		return schema.TypeString, nil
+	}
+
+	switch kind {
+	case "object":
+		// Bare ["object", {...}] — map to TypeList + MaxItems:1 with nested Resource
+		return schema.TypeList, convertObjectFields(arr[1])
+
+	case "list", "set", "map":
+		sdkType := schema.TypeList
+		if kind == "set" {
+			sdkType = schema.TypeSet
+		} else if kind == "map" {
+			sdkType = schema.TypeMap
+		}
+		elem := convertElem(arr[1])
+		return sdkType, elem
+
+	default:
+		return schema.TypeString, nil
+	}
+}
+
+// convertElem returns the Elem value for a collection's inner type.
+func convertElem(raw json.RawMessage) interface{} {
+	// Primitive inner type?
+	var inner string
+	if json.Unmarshal(raw, &inner) == nil {
+		switch inner {
+		case "string":
+			return &schema.Schema{Type: schema.TypeString}
+		case "number":
+			return &schema.Schema{Type: schema.TypeInt}
+		case "bool":
+			return &schema.Schema{Type: schema.TypeBool}
+		default:
+			return &schema.Schema{Type: schema.TypeString}
+		}
+	}
+
+	// Array inner type: ["object", {...}] or nested collection
+	var arr []json.RawMessage
+	if json.Unmarshal(raw, &arr) != nil || len(arr) < 2 {
+		return &schema.Schema{Type: schema.TypeString}
+	}
+
+	var kind string
+	if json.Unmarshal(arr[0], &kind) != nil {
+		return &schema.Schema{Type: schema.TypeString}
+	}
+
+	if kind == "object" {
+		return convertObjectFields(arr[1])
+	}
+
+	// Nested collection (e.g. ["list", "string"]) — flatten to TypeString
+	return &schema.Schema{Type: schema.TypeString}
+}
+
+// convertObjectFields turns a JSON object map into a *schema.Resource.
+func convertObjectFields(raw json.RawMessage) *schema.Resource {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil {
+		return &schema.Resource{Schema: map[string]*schema.Schema{}}
+	}
+
+	s := make(map[string]*schema.Schema, len(fields))
+	for name, fieldType := range fields {
+		ft, elem := ConvertType(fieldType)
+		field := &schema.Schema{
+			Type:     ft,
+			Optional: true,
+			Computed: true,
+		}
+		if elem != nil {
+			field.Elem = elem
+			if ft == schema.TypeList {
+				// Check if this is a bare object (not a collection) — use MaxItems:1
+				if _, isResource := elem.(*schema.Resource); isResource {
+					field.MaxItems = 1
+				}
+			}
+		}
+		s[name] = field
+	}
+	return &schema.Resource{Schema: s}
+}
+
+// --- Block conversion ---
+
+// ConvertBlock converts a resource's block to an SDK schema map, leaving
+// out id and timeouts, which the SDK declares itself.
+func ConvertBlock(block Block) map[string]*schema.Schema {
+	result := make(map[string]*schema.Schema)
+
+	// Attributes
+	for name, attr := range block.Attributes {
+		if name == "id" {
+			continue
+		}
+
+		sdkType, elem := ConvertType(attr.Type)
+
+		s := &schema.Schema{
+			Type:      sdkType,
+			Optional:  attr.Optional,
+			Required:  attr.Required,
+			Computed:  attr.Computed,
+			Sensitive: attr.Sensitive,
+		}
+
+		// The SDK only allows write-only on primitive attributes
+		if attr.WriteOnly && elem == nil && !attr.Computed {
+			s.WriteOnly = true
+		}
+
+		if elem != nil {
+			s.Elem = elem
+			// Bare ["object", {...}] at attribute level
+			if sdkType == schema.TypeList {
+				if _, isResource := elem.(*schema.Resource); isResource {
+					s.MaxItems = 1
+				}
+			}
+		}
+
+		result[name] = s
+	}
+
+	// Block types
+	for name, bt := range block.BlockTypes {
+		if name == "timeouts" {
+			continue
+		}
+
+		innerSchema := ConvertBlock(bt.Block)
+		demoteWriteOnly(innerSchema)
+
+		s := &schema.Schema{
+			Elem: &schema.Resource{Schema: innerSchema},
+		}
+
+		switch bt.NestingMode {
+		case "set":
+			s.Type = schema.TypeSet
+		case "single":
+			s.Type = schema.TypeList
+			s.MaxItems = 1
+		default: // "list"
+			s.Type = schema.TypeList
+		}
+
+		if bt.MaxItems > 0 {
+			s.MaxItems = bt.MaxItems
+		}
+		if bt.MinItems > 0 {
+			s.MinItems = bt.MinItems
+		}
+
+		if bt.MinItems >= 1 {
+			s.Required = true
+		} else {
+			s.Optional = true
+			s.Computed = true
+		}
+
+		result[name] = s
+	}
+
+	return result
+}
+
+// demoteWriteOnly turns write-only attributes in a nested block back into
+// sensitive ones, since the SDK only allows write-only attributes at the top
+// level of the blocks ConvertBlock produces.
+func demoteWriteOnly(s map[string]*schema.Schema) {
+	for _, field := range s {
+		if field.WriteOnly {
+			field.WriteOnly = false
+			field.Sensitive = true
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package providerschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestConvertPrimitiveTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected schema.ValueType
+	}{
+		{`"string"`, schema.TypeString},
+		{`"number"`, schema.TypeInt},
+		{`"bool"`, schema.TypeBool},
+	}
+
+	for _, tt := range tests {
+		typ, elem := ConvertType(json.RawMessage(tt.input))
+		if typ != tt.expected {
+			t.Errorf("ConvertType(%s) = %v, want %v", tt.input, typ, tt.expected)
+		}
+		if elem != nil {
+			t.Errorf("ConvertType(%s) elem should be nil, got %v", tt.input, elem)
+		}
+	}
+}
+
+func TestConvertCollectionTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected schema.ValueType
+		elemType schema.ValueType
+	}{
+		{"list of string", `["list", "string"]`, schema.TypeList, schema.TypeString},
+		{"set of string", `["set", "string"]`, schema.TypeSet, schema.TypeString},
+		{"map of string", `["map", "string"]`, schema.TypeMap, schema.TypeString},
+		{"list of number", `["list", "number"]`, schema.TypeList, schema.TypeInt},
+		{"set of bool", `["set", "bool"]`, schema.TypeSet, schema.TypeBool},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, elem := ConvertType(json.RawMessage(tt.input))
+			if typ != tt.expected {
+				t.Errorf("type = %v, want %v", typ, tt.expected)
+			}
+			s, ok := elem.(*schema.Schema)
+			if !ok {
+				t.Fatalf("elem should be *schema.Schema, got %T", elem)
+			}
+			if s.Type != tt.elemType {
+				t.Errorf("elem type = %v, want %v", s.Type, tt.elemType)
+			}
+		})
+	}
+}
+
+func TestConvertObjectInCollection(t *testing.T) {
+	input := `["set", ["object", {"name": "string", "count": "number"}]]`
+
+	typ, elem := ConvertType(json.RawMessage(input))
+	if typ != schema.TypeSet {
+		t.Fatalf("type = %v, want TypeSet", typ)
+	}
+
+	res, ok := elem.(*schema.Resource)
+	if !ok {
+		t.Fatalf("elem should be *schema.Resource, got %T", elem)
+	}
+
+	nameField, ok := res.Schema["name"]
+	if !ok {
+		t.Fatal("missing 'name' field in object schema")
+	}
+	if nameField.Type != schema.TypeString {
+		t.Errorf("name type = %v, want TypeString", nameField.Type)
+	}
+
+	countField, ok := res.Schema["count"]
+	if !ok {
+		t.Fatal("missing 'count' field in object schema")
+	}
+	if countField.Type != schema.TypeInt {
+		t.Errorf("count type = %v, want TypeInt", countField.Type)
+	}
+}
+
+func TestConvertBlockHonoursWriteOnly(t *testing.T) {
+	s := ConvertBlock(Block{
+		Attributes: map[string]Attribute{
+			"password_wo": {Type: []byte(`"string"`), Optional: true, Sensitive: true, WriteOnly: true},
+			"tokens_wo":   {Type: []byte(`["list","string"]`), Optional: true, WriteOnly: true},
+		},
+		BlockTypes: map[string]BlockType{
+			"auth": {NestingMode: "list", Block: Block{Attributes: map[string]Attribute{
+				"secret_wo": {Type: []byte(`"string"`), Optional: true, WriteOnly: true},
+			}}},
+		},
+	})
+
+	if !s["password_wo"].WriteOnly {
+		t.Error("password_wo should be write-only")
+	}
+	if s["tokens_wo"].WriteOnly {
+		t.Error("the SDK does not allow write-only lists")
+	}
+	nested := s["auth"].Elem.(*schema.Resource).Schema["secret_wo"]
+	if nested.WriteOnly || !nested.Sensitive {
+		t.Error("write-only attributes in computed blocks should fall back to sensitive")
+	}
+	if err := schema.InternalMap(s).InternalValidate(nil); err != nil {
+		t.Errorf("converted schema is invalid: %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+// Package schemagen renders the schema_<type>.go files of hand-written
+// resources from the provider schema JSON, using the same type mapping as
+// the provider's dynamic resources.
+//
+// Customizations the JSON can't express, such as ForceNew, validators or
+// diff suppression, survive regeneration by marking their entry with a
+// "schemagen:keep" comment: the marked entry is copied from the existing
+// file as it is, at any depth, even when the JSON lacks the attribute.
+//
+//	"assume_role_policy": { // schemagen:keep
+//		Type:             schema.TypeString,
+//		Required:         true,
+//		DiffSuppressFunc: suppressEquivalentPolicyDiffs,
+//	},
+package schemagen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-aws-mock/internal/providerschema"
+)
+
+// keepMarker marks an entry the generator must leave as it is.
+const keepMarker = "schemagen:keep"
+
+const header = `// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+`
+
+// FileName returns the schema file of resourceType, e.g. schema_vpc.go for
+// aws_vpc.
+func FileName(resourceType string) string {
+	return "schema_" + strings.TrimPrefix(resourceType, "aws_") + ".go"
+}
+
+// FuncName returns the function returning resourceType's schema, e.g.
+// resourceVpcSchema for aws_vpc.
+func FuncName(resourceType string) string {
+	var name strings.Builder
+	name.WriteString("resource")
+	for _, word := range strings.Split(strings.TrimPrefix(resourceType, "aws_"), "_") {
+		if word != "" {
+			name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	name.WriteString("Schema")
+	return name.String()
+}
+
+// Generate renders the schema file of resourceType from its block in the
+// provider schema. existing is the current file, or nil if there is none;
+// its schemagen:keep entries are carried over.
+func Generate(resourceType string, block providerschema.Block, existing []byte) ([]byte, error) {
+	kept := map[string]string{}
+	if existing != nil {
+		var err error
+		if kept, err = keptEntries(existing, FuncName(resourceType)); err != nil {
+			return nil, fmt.Errorf("%s: %w", FileName(resourceType), err)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	fmt.Fprintf(&buf, "func %s() map[string]*schema.Schema {\n", FuncName(resourceType))
+	buf.WriteString("return map[string]*schema.Schema{\n")
+	writeSchemaMap(&buf, providerschema.ConvertBlock(block), kept, "")
+	buf.WriteString("}\n}\n")
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting %s: %w", FileName(resourceType), err)
+	}
+	return out, nil
+}
+
+// writeSchemaMap writes the entries of s, in name order, with the entries
+// kept under prefix in their place.
+func writeSchemaMap(buf *bytes.Buffer, s map[string]*schema.Schema, kept map[string]string, prefix string) {
+	names := slices.Collect(maps.Keys(s))
+	for path := range kept {
+		name, found := strings.CutPrefix(path, prefix)
+		if found && !strings.Contains(name, ".") && s[name] == nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if entry, ok := kept[prefix+name]; ok {
+			buf.WriteString(entry + ",\n")
+			continue
+		}
+		fmt.Fprintf(buf, "%q: {\n", name)
+		writeSchema(buf, s[name], kept, prefix+name+".")
+		buf.WriteString("},\n")
+	}
+}
+
+func writeSchema(buf *bytes.Buffer, s *schema.Schema, kept map[string]string, prefix string) {
+	fmt.Fprintf(buf, "Type: schema.%s,\n", s.Type)
+	if s.MaxItems > 0 {
+		fmt.Fprintf(buf, "MaxItems: %d,\n", s.MaxItems)
+	}
+	if s.MinItems > 0 {
+		fmt.Fprintf(buf, "MinItems: %d,\n", s.MinItems)
+	}
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"Required", s.Required},
+		{"Optional", s.Optional},
+		{"Computed", s.Computed},
+		{"Sensitive", s.Sensitive},
+		{"WriteOnly", s.WriteOnly},
+	} {
+		if flag.set {
+			fmt.Fprintf(buf, "%s: true,\n", flag.name)
+		}
+	}
+
+	switch elem := s.Elem.(type) {
+	case *schema.Schema:
+		fmt.Fprintf(buf, "Elem: &schema.Schema{Type: schema.%s},\n", elem.Type)
+	case *schema.Resource:
+		buf.WriteString("Elem: &schema.Resource{\nSchema: map[string]*schema.Schema{\n")
+		writeSchemaMap(buf, elem.Schema, kept, prefix)
+		buf.WriteString("},\n},\n")
+	}
+}
+
+// keptEntries returns the source of the entries marked schemagen:keep in the
+// schema file src, by dotted attribute path.
+func keptEntries(src []byte, funcName string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var body *ast.CompositeLit
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != funcName || fn.Body == nil {
+			continue
+		}
+		for _, stmt := range fn.Body.List {
+			if ret, ok := stmt.(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+				body, _ = ret.Results[0].(*ast.CompositeLit)
+			}
+		}
+	}
+	if body == nil {
+		return nil, fmt.Errorf("no %s returning a schema map literal", funcName)
+	}
+
+	kept := map[string]string{}
+	collectKept(fset, src, file.Comments, body, "", kept)
+	return kept, nil
+}
+
+// collectKept records the marked entries of a schema map literal, and
+// searches the nested blocks of the unmarked ones.
+func collectKept(fset *token.FileSet, src []byte, comments []*ast.CommentGroup, lit *ast.CompositeLit, prefix string, kept map[string]string) {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.BasicLit)
+		if !ok || key.Kind != token.STRING {
+			continue
+		}
+		name, err := strconv.Unquote(key.Value)
+		if err != nil {
+			continue
+		}
+
+		if marked(fset, comments, kv) {
+			start := fset.Position(kv.Pos()).Offset
+			end := fset.Position(kv.End()).Offset
+			kept[prefix+name] = string(src[start:end])
+			continue
+		}
+		if nested := nestedSchemaMap(kv.Value); nested != nil {
+			collectKept(fset, src, comments, nested, prefix+name+".", kept)
+		}
+	}
+}
+
+// marked reports whether a keep marker sits on the entry's first line.
+func marked(fset *token.FileSet, comments []*ast.CommentGroup, kv *ast.KeyValueExpr) bool {
+	line := fset.Position(kv.Pos()).Line
+	for _, group := range comments {
+		if group.Pos() < kv.Pos() || group.Pos() > kv.End() {
+			continue
+		}
+		for _, c := range group.List {
+			if fset.Position(c.Pos()).Line == line && strings.Contains(c.Text, keepMarker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nestedSchemaMap returns the Schema map literal in a block attribute's
+// Elem: &schema.Resource{Schema: ...}, or nil.
+func nestedSchemaMap(value ast.Expr) *ast.CompositeLit {
+	field, ok := value.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	for _, elt := range field.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok || !isIdent(kv.Key, "Elem") {
+			continue
+		}
+		unary, ok := kv.Value.(*ast.UnaryExpr)
+		if !ok {
+			return nil
+		}
+		resource, ok := unary.X.(*ast.CompositeLit)
+		if !ok {
+			return nil
+		}
+		for _, elt := range resource.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok && isIdent(kv.Key, "Schema") {
+				m, _ := kv.Value.(*ast.CompositeLit)
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
@@ -0,0 +1,102 @@
+package schemagen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"terraform-provider-aws-mock/internal/providerschema"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func widgetBlock(t *testing.T) providerschema.Block {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", "schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := providerschema.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schemas["aws_widget"].Block
+}
+
+// checkGolden compares got with testdata/<name>.golden.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("%s mismatch (-want +got):\n%s", path, diff)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	got, err := Generate("aws_widget", widgetBlock(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "widget", got)
+}
+
+// TestGenerateKeepsMarkedEntries regenerates a file whose marked entries,
+// at the top level, in a block and missing from the JSON, are kept, while
+// the unmarked ForceNew on size is overwritten.
+func TestGenerateKeepsMarkedEntries(t *testing.T) {
+	existing, err := os.ReadFile(filepath.Join("testdata", "widget_existing.input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate("aws_widget", widgetBlock(t), existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "widget_kept", got)
+
+	again, err := Generate("aws_widget", widgetBlock(t), got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(got), string(again)); diff != "" {
+		t.Errorf("regenerating changed the file (-first +second):\n%s", diff)
+	}
+}
+
+func TestGenerateRejectsUnparsableFile(t *testing.T) {
+	if _, err := Generate("aws_widget", widgetBlock(t), []byte("package main\n\nfunc resourceWidgetSchema(")); err == nil {
+		t.Error("expected an error for an existing file that doesn't parse")
+	}
+	if _, err := Generate("aws_widget", widgetBlock(t), []byte("package main\n")); err == nil {
+		t.Error("expected an error for an existing file without the schema function")
+	}
+}
+
+func TestNames(t *testing.T) {
+	for _, tt := range []struct {
+		resourceType, file, fn string
+	}{
+		{"aws_vpc", "schema_vpc.go", "resourceVpcSchema"},
+		{"aws_s3_bucket_policy", "schema_s3_bucket_policy.go", "resourceS3BucketPolicySchema"},
+		{"aws_main_route_table_association", "schema_main_route_table_association.go", "resourceMainRouteTableAssociationSchema"},
+	} {
+		if got := FileName(tt.resourceType); got != tt.file {
+			t.Errorf("FileName(%s) = %s, want %s", tt.resourceType, got, tt.file)
+		}
+		if got := FuncName(tt.resourceType); got != tt.fn {
+			t.Errorf("FuncName(%s) = %s, want %s", tt.resourceType, got, tt.fn)
+		}
+	}
+}
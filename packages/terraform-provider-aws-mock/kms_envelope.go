@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// mockKMSEnvelope is the mock's stand-in for a KMS ciphertext blob: the
+// payload sealed with AES-GCM under a secret derived from the key, with the
+// key and the encryption context alongside in the clear, as KMS keeps them
+// in its own blobs. The secret is only as private as this source, so the
+// envelope keeps plaintext out of state and logs rather than protecting it.
+type mockKMSEnvelope struct {
+	KeyID      string            `json:"key_id"`
+	Context    map[string]string `json:"context,omitempty"`
+	Nonce      []byte            `json:"nonce"`
+	Ciphertext []byte            `json:"ciphertext"`
+}
+
+// encryptMockKMS returns a base64 ciphertext blob for plaintext under keyID,
+// which may be a key ID, key ARN, alias name or alias ARN of an enabled key.
+func encryptMockKMS(client *MockClient, keyID, plaintext string, context map[string]string) (string, error) {
+	resolved, err := resolveKMSKeyID(client, keyID)
+	if err != nil {
+		return "", err
+	}
+	aead, err := mockKMSCipher(resolved)
+	if err != nil {
+		return "", err
+	}
+
+	envelope := mockKMSEnvelope{KeyID: resolved, Context: context, Nonce: make([]byte, aead.NonceSize())}
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return "", err
+	}
+	aad, err := envelope.additionalData()
+	if err != nil {
+		return "", err
+	}
+	envelope.Ciphertext = aead.Seal(nil, envelope.Nonce, []byte(plaintext), aad)
+
+	raw, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// decryptMockKMS opens a ciphertext blob produced by encryptMockKMS. keyID,
+// when set, must name the key the blob was encrypted under, and context
+// must match the encryption context exactly, as with KMS Decrypt.
+func decryptMockKMS(client *MockClient, blob, keyID string, context map[string]string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return "", fmt.Errorf("InvalidCiphertextException: ciphertext is not valid base64: %w", err)
+	}
+	var envelope mockKMSEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil || envelope.KeyID == "" {
+		return "", fmt.Errorf("InvalidCiphertextException: ciphertext was not produced by this mock's KMS")
+	}
+
+	envelopeKeyID, err := resolveKMSKeyID(client, envelope.KeyID)
+	if err != nil {
+		return "", err
+	}
+	if keyID != "" {
+		requested, err := resolveKMSKeyID(client, keyID)
+		if err != nil {
+			return "", err
+		}
+		if requested != envelopeKeyID {
+			return "", fmt.Errorf("IncorrectKeyException: ciphertext was encrypted under key %s, not %s", envelope.KeyID, keyID)
+		}
+	}
+	if !maps.Equal(envelope.Context, context) {
+		return "", fmt.Errorf("InvalidCiphertextException: encryption context does not match the one used to encrypt")
+	}
+
+	aead, err := mockKMSCipher(envelopeKeyID)
+	if err != nil {
+		return "", err
+	}
+	aad, err := envelope.additionalData()
+	if err != nil {
+		return "", err
+	}
+	if len(envelope.Nonce) != aead.NonceSize() {
+		return "", fmt.Errorf("InvalidCiphertextException: ciphertext payload is corrupt")
+	}
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Ciphertext, aad)
+	if err != nil {
+		return "", fmt.Errorf("InvalidCiphertextException: ciphertext payload is corrupt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// mockKMSCipher returns the AES-GCM cipher of the key with backend ID keyID.
+// Its secret is derived from the ID, so every provider process agrees on it
+// without the backend having to keep key material.
+func mockKMSCipher(keyID string) (cipher.AEAD, error) {
+	secret := sha256.Sum256([]byte("aws-mock kms key\x00" + keyID))
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the key and encryption context to the sealed
+// payload, so a blob edited to claim another key or context fails to open.
+func (e *mockKMSEnvelope) additionalData() ([]byte, error) {
+	return json.Marshal(struct {
+		KeyID   string            `json:"key_id"`
+		Context map[string]string `json:"context,omitempty"`
+	}{e.KeyID, e.Context})
+}
+
+// resolveKMSKeyID turns a key ID, key ARN, alias name or alias ARN into the
+// backend ID of an enabled aws_kms_key.
+func resolveKMSKeyID(client *MockClient, keyID string) (string, error) {
+	if strings.HasPrefix(keyID, "arn:") {
+		a, err := parseARN(keyID)
+		if err != nil {
+			return "", fmt.Errorf("NotFoundException: %w", err)
+		}
+		keyID = strings.TrimPrefix(a.Resource, "key/")
+	}
+
+	if strings.HasPrefix(keyID, "alias/") {
+		aliases, err := client.ListResources("aws_kms_alias", map[string]string{"name": keyID})
+		if err != nil {
+			return "", err
+		}
+		if len(aliases) == 0 {
+			return "", fmt.Errorf("NotFoundException: alias %s is not found", keyID)
+		}
+		target := stringAttribute(&aliases[0], "target_key_id")
+		if target == "" || strings.HasPrefix(target, "alias/") {
+			return "", fmt.Errorf("NotFoundException: alias %s does not point at a key", keyID)
+		}
+		return resolveKMSKeyID(client, target)
+	}
+
+	key, err := client.ReadResource("aws_kms_key", keyID)
+	if err != nil {
+		return "", err
+	}
+	if key == nil {
+		return "", fmt.Errorf("NotFoundException: key %s does not exist", keyID)
+	}
+	if enabled, ok := key.Attributes["is_enabled"].(bool); ok && !enabled {
+		return "", fmt.Errorf("DisabledException: key %s is disabled", keyID)
+	}
+	return key.ID, nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	listschema "github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// newListResources returns a list resource for every managed resource of
+// the SDKv2 provider that has an identity, for terraform query to discover what exists in the
+// backend. The managed resources stay on the SDKv2 side, so each list
+// resource carries the protocol schemas SDKv2 reports for its type.
+func newListResources(ctx context.Context, sdkProvider *schema.Provider) ([]func() list.ListResource, error) {
+	server := sdkProvider.GRPCProvider()
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		return nil, err
+	}
+	identities, err := server.GetResourceIdentitySchemas(ctx, &tfprotov5.GetResourceIdentitySchemasRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var listResources []func() list.ListResource
+	for _, typeName := range slices.Sorted(maps.Keys(sdkProvider.ResourcesMap)) {
+		identity, ok := identities.IdentitySchemas[typeName]
+		if !ok {
+			continue
+		}
+		r := &mockListResource{
+			typeName:       typeName,
+			resource:       sdkProvider.ResourcesMap[typeName],
+			protoSchema:    schemas.ResourceSchemas[typeName],
+			identitySchema: identity,
+		}
+		listResources = append(listResources, func() list.ListResource { return r })
+	}
+	return listResources, nil
+}
+
+// mockListResource lists the backend's resources of one type, optionally
+// narrowed by attribute values.
+type mockListResource struct {
+	typeName       string
+	resource       *schema.Resource
+	protoSchema    *tfprotov5.Schema
+	identitySchema *tfprotov5.ResourceIdentitySchema
+	clients        *sharedClient
+}
+
+var (
+	_ list.ListResourceWithConfigure    = (*mockListResource)(nil)
+	_ list.ListResourceWithRawV5Schemas = (*mockListResource)(nil)
+)
+
+type mockListModel struct {
+	Filter map[string]string `tfsdk:"filter"`
+}
+
+func (r *mockListResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = r.typeName
+}
+
+func (r *mockListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = listschema.Schema{
+		Attributes: map[string]listschema.Attribute{
+			"filter": listschema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Attribute values every listed resource must have, e.g. { vpc_id = \"vpc-0123\" }",
+			},
+		},
+	}
+}
+
+func (r *mockListResource) RawV5Schemas(ctx context.Context, req list.RawV5SchemaRequest, resp *list.RawV5SchemaResponse) {
+	resp.ProtoV5Schema = r.protoSchema
+	resp.ProtoV5IdentitySchema = r.identitySchema
+}
+
+func (r *mockListResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	clients, ok := req.ProviderData.(*sharedClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("expected *sharedClient, got %T", req.ProviderData))
+		return
+	}
+	r.clients = clients
+}
+
+// client returns the configured MockClient.
+func (r *mockListResource) client() (*MockClient, error) {
+	if r.clients == nil {
+		return nil, fmt.Errorf("the provider has not been configured with a backend")
+	}
+	return r.clients.get()
+}
+
+func (r *mockListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config mockListModel
+	if diags := req.Config.Get(ctx, &config); diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	client, err := r.client()
+	if err != nil {
+		stream.Results = listError("Provider not configured", err)
+		return
+	}
+	found, err := client.ListResources(r.typeName, config.Filter)
+	if err != nil {
+		stream.Results = listError(fmt.Sprintf("Error listing %s", r.typeName), err)
+		return
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for i, item := range found {
+			if req.Limit > 0 && int64(i) >= req.Limit {
+				return
+			}
+
+			result := req.NewListResult(ctx)
+			result.DisplayName = listDisplayName(item)
+			result.Diagnostics.Append(result.Identity.SetAttribute(ctx, path.Root(resourceIdentityKey), item.ID)...)
+			if req.IncludeResource {
+				value, err := r.resourceValue(ctx, client, item.ID, req.ResourceSchema.Type().TerraformType(ctx))
+				if err != nil {
+					result.Diagnostics.AddError(fmt.Sprintf("Error reading %s %s", r.typeName, item.ID), err.Error())
+				} else {
+					result.Resource.Raw = value
+				}
+			}
+
+			if !push(result) {
+				return
+			}
+		}
+	}
+}
+
+// resourceValue reads a listed resource through its SDKv2 Read, so the
+// result has the same attributes a refresh would store.
+func (r *mockListResource) resourceValue(ctx context.Context, client *MockClient, id string, typ tftypes.Type) (tftypes.Value, error) {
+	d := r.resource.Data(&terraform.InstanceState{ID: id})
+	if diags := r.resource.ReadContext(ctx, d, client); diags.HasError() {
+		return tftypes.Value{}, fmt.Errorf("%s", diags[0].Summary)
+	}
+	state := d.State()
+	if state == nil {
+		return tftypes.Value{}, fmt.Errorf("the resource no longer exists")
+	}
+
+	ty := r.resource.CoreConfigSchema().ImpliedType()
+	value, err := state.AttrsAsObjectValue(ty)
+	if err != nil {
+		return tftypes.Value{}, err
+	}
+	packed, err := msgpack.Marshal(value, ty)
+	if err != nil {
+		return tftypes.Value{}, err
+	}
+	return (&tfprotov5.DynamicValue{MsgPack: packed}).Unmarshal(typ)
+}
+
+// listDisplayName names a listed resource after its Name tag or name
+// attribute, falling back to its ID.
+func listDisplayName(item ResourceResponse) string {
+	if tags, ok := item.Attributes["tags"].(map[string]interface{}); ok {
+		if name, _ := tags["Name"].(string); name != "" {
+			return name
+		}
+	}
+	if name, _ := item.Attributes["name"].(string); name != "" {
+		return name
+	}
+	return item.ID
+}
+
+func listError(summary string, err error) iter.Seq[list.ListResult] {
+	return list.ListResultsStreamDiagnostics(diag.Diagnostics{diag.NewErrorDiagnostic(summary, err.Error())})
+}
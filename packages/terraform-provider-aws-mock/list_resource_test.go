@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourcesRecordIdentity(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	b.put("aws_vpc", "vpc-1", map[string]interface{}{"cidr_block": "10.0.0.0/16"})
+	vpc := Provider().ResourcesMap["aws_vpc"]
+
+	if vpc.Importer == nil {
+		t.Fatal("aws_vpc should be importable")
+	}
+	d := vpc.Data(&terraform.InstanceState{ID: "vpc-1"})
+	if diags := vpc.ReadContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	identity, err := d.Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := identity.Get("id"); got != "vpc-1" {
+		t.Errorf("identity id = %v, want vpc-1", got)
+	}
+}
+
+func TestStateOnlyResourcesAreNotListedOrImported(t *testing.T) {
+	p := Provider()
+	listResources, err := newListResources(context.Background(), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := make(map[string]bool)
+	for _, newResource := range listResources {
+		listed[newResource().(*mockListResource).typeName] = true
+	}
+
+	for resourceType := range stateOnlyResources {
+		r := p.ResourcesMap[resourceType]
+		if r.Identity != nil || r.Importer != nil {
+			t.Errorf("%s has no backend collection and should not be importable", resourceType)
+		}
+		if listed[resourceType] {
+			t.Errorf("%s has no backend collection and should have no list resource", resourceType)
+		}
+	}
+	if !listed["aws_vpc"] {
+		t.Error("aws_vpc should have a list resource")
+	}
+}
+
+func TestListResourceThroughMux(t *testing.T) {
+	b := &memoryBackend{resources: make(map[string]map[string]map[string]interface{})}
+	b.put("aws_vpc", "vpc-1", map[string]interface{}{"cidr_block": "10.0.0.0/16", "tags": map[string]interface{}{"Name": "main"}})
+	b.put("aws_vpc", "vpc-2", map[string]interface{}{"cidr_block": "10.1.0.0/16"})
+	server := configuredMuxServer(t, b)
+	ctx := context.Background()
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listSchema, ok := schemaResp.ListResourceSchemas["aws_vpc"]
+	if !ok {
+		t.Fatal("list resource aws_vpc not served")
+	}
+
+	configType := listSchema.ValueType()
+	config, err := tfprotov5.NewDynamicValue(configType, tftypes.NewValue(configType, map[string]tftypes.Value{
+		"filter": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+			"cidr_block": tftypes.NewValue(tftypes.String, "10.0.0.0/16"),
+		}),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := server.(tfprotov5.ProviderServerWithListResource).ListResource(ctx, &tfprotov5.ListResourceRequest{
+		TypeName:        "aws_vpc",
+		Config:          &config,
+		IncludeResource: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identityResp, err := server.GetResourceIdentitySchemas(ctx, &tfprotov5.GetResourceIdentitySchemasRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	identityType := identityResp.IdentitySchemas["aws_vpc"].ValueType()
+	resourceType := schemaResp.ResourceSchemas["aws_vpc"].ValueType()
+
+	var results []tfprotov5.ListResourceResult
+	for result := range stream.Results {
+		for _, d := range result.Diagnostics {
+			t.Fatalf("listing: %s: %s", d.Summary, d.Detail)
+		}
+		results = append(results, result)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want only the VPC matching the filter", len(results))
+	}
+	if results[0].DisplayName != "main" {
+		t.Errorf("display name = %q, want the Name tag", results[0].DisplayName)
+	}
+
+	identity, err := results[0].Identity.IdentityData.Unmarshal(identityType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var identityAttrs map[string]tftypes.Value
+	identity.As(&identityAttrs)
+	var id string
+	identityAttrs["id"].As(&id)
+	if id != "vpc-1" {
+		t.Errorf("identity id = %q, want vpc-1", id)
+	}
+
+	resource, err := results[0].Resource.Unmarshal(resourceType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	resource.As(&attrs)
+	var cidr string
+	attrs["cidr_block"].As(&cidr)
+	if cidr != "10.0.0.0/16" {
+		t.Errorf("cidr_block = %q, want the listed VPC's", cidr)
+	}
+}
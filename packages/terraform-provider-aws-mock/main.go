@@ -2,23 +2,62 @@ package main
 
 import (
 	"context"
-	"net/http"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 )
 
 func Provider() *schema.Provider {
 	resources := buildAllDynamicResources()
+	addWriteOnlyAttributes(resources)
+	overlayErr := applyResourceOverlays(resources)
 
 	// Hand-written overrides
-	resources["aws_s3_bucket"] = resourceS3Bucket()
-	resources["aws_s3_bucket_policy"] = resourceS3BucketPolicy()
-	resources["aws_vpc"] = resourceVpc()
-	resources["aws_subnet"] = resourceSubnet()
-	resources["aws_security_group"] = resourceSecurityGroup()
-	resources["aws_instance"] = resourceInstance()
+	overrides := map[string]*schema.Resource{
+		"aws_s3_bucket":                    resourceS3Bucket(),
+		"aws_s3_bucket_policy":             resourceS3BucketPolicy(),
+		"aws_s3_object":                    resourceS3Object(),
+		"aws_vpc":                          resourceVpc(),
+		"aws_subnet":                       resourceSubnet(),
+		"aws_security_group":               resourceSecurityGroup(),
+		"aws_instance":                     resourceInstance(),
+		"aws_route_table":                  resourceRouteTable(),
+		"aws_route":                        resourceRoute(),
+		"aws_route_table_association":      resourceRouteTableAssociation(),
+		"aws_main_route_table_association": resourceMainRouteTableAssociation(),
+		"aws_nat_gateway":                  resourceNatGateway(),
+		"aws_eip":                          resourceEip(),
+		"aws_iam_role":                     resourceIamRole(),
+		"aws_iam_policy":                   resourceIamPolicy(),
+		"aws_iam_role_policy_attachment":   resourceIamRolePolicyAttachment(),
+		"aws_iam_instance_profile":         resourceIamInstanceProfile(),
+		"aws_kms_ciphertext":               resourceKmsCiphertext(),
+	}
+	for resourceType, r := range overrides {
+		addDynamicStateUpgrader(r, resources[resourceType])
+		resources[resourceType] = r
+	}
+	// Provider-only resources for steering the mock itself
+	resources["aws_mock_drift"] = resourceMockDrift()
+
+	if r, ok := resources["aws_ec2_instance_state"]; ok {
+		resources["aws_ec2_instance_state"] = withInstanceStopProtection(r)
+	}
+
+	addComputedDiffs(resources)
+	addMissingResourceWarnings(resources)
+	addResourceIdentities(resources)
+
+	// Data sources, evaluated in the provider rather than the backend
+	dataSources := map[string]*schema.Resource{
+		"aws_iam_policy_document":    dataSourceIamPolicyDocument(),
+		"aws_mock_policy_simulation": dataSourceMockPolicySimulation(),
+	}
 
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
@@ -26,7 +65,66 @@ func Provider() *schema.Provider {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("AWS_MOCK_BACKEND_URL", "http://localhost:3000"),
-				Description: "URL of the mock AWS backend server",
+				Description: "URL of the mock AWS backend server; unix:///path/to/socket dials a Unix domain socket",
+			},
+			"endpoints": providerEndpointsSchema(),
+			"state_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("AWS_MOCK_STATE_FILE", ""),
+				Description: "Keep resources in this JSON file inside the provider instead of calling a backend server; backend_url, endpoints and the connection arguments are then ignored",
+			},
+			"read_batch_window_ms": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     10,
+				Description: "How long refresh reads wait to be coalesced into one batch request; 0 disables batching",
+			},
+			"token": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("AWS_MOCK_TOKEN", ""),
+				ConflictsWith: []string{"access_key", "secret_key"},
+				Description:   "Bearer token sent to the backend in the Authorization header",
+			},
+			"access_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("AWS_MOCK_ACCESS_KEY", ""),
+				RequiredWith: []string{"secret_key"},
+				Description:  "Access key used to sign requests to the backend with AWS SigV4; the backend decides whether to check the signature",
+			},
+			"secret_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				DefaultFunc:  schema.EnvDefaultFunc("AWS_MOCK_SECRET_KEY", ""),
+				RequiredWith: []string{"access_key"},
+				Description:  "Secret key used to sign requests to the backend; never sent itself",
+			},
+			"ca_cert_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM file of CA certificates trusted for an HTTPS backend",
+			},
+			"insecure": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip verification of the backend's TLS certificate",
+			},
+			"client_cert_file": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"client_key_file"},
+				Description:  "PEM client certificate presented to an HTTPS backend",
+			},
+			"client_key_file": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"client_cert_file"},
+				Description:  "PEM private key for client_cert_file",
 			},
 			"region": {
 				Type:        schema.TypeString,
@@ -35,18 +133,33 @@ func Provider() *schema.Provider {
 				Description: "The AWS region to use",
 			},
 		},
-		ResourcesMap:         resources,
-		ConfigureContextFunc: providerConfigure,
+		ResourcesMap:   resources,
+		DataSourcesMap: dataSources,
+		ConfigureContextFunc: func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+			if overlayErr != nil {
+				return nil, diag.FromErr(overlayErr)
+			}
+			return providerConfigure(ctx, d, resources)
+		},
 	}
 }
 
-func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	backendURL := d.Get("backend_url").(string)
+// providerConfigure builds the client for the configured backend. resources
+// gives an offline client the schemas to synthesize computed values from.
+func providerConfigure(ctx context.Context, d *schema.ResourceData, resources map[string]*schema.Resource) (interface{}, diag.Diagnostics) {
 	region := d.Get("region").(string)
 
-	client := &MockClient{
-		BackendURL: backendURL,
-		HTTPClient: &http.Client{},
+	var client *MockClient
+	if stateFile := d.Get("state_file").(string); stateFile != "" {
+		client = newOfflineClient(stateFile, region, resources)
+	} else {
+		var diags diag.Diagnostics
+		if client, diags = newBackendClient(d, region); diags.HasError() {
+			return nil, diags
+		}
+	}
+	if window := d.Get("read_batch_window_ms").(int); window > 0 {
+		client.batcher = newReadBatcher(client, time.Duration(window)*time.Millisecond)
 	}
 
 	if err := client.ConfigureProvider(region); err != nil {
@@ -56,8 +169,43 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 	return client, nil
 }
 
+// newBackendClient returns a client calling the backend server at
+// backend_url.
+func newBackendClient(d *schema.ResourceData, region string) (*MockClient, diag.Diagnostics) {
+	sockets := make(map[string]string)
+	backendURL, err := resolveSocketURL(d.Get("backend_url").(string), sockets)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	endpoints := expandEndpoints(d.Get("endpoints").([]interface{}))
+	for service, endpoint := range endpoints {
+		if endpoints[service], err = resolveSocketURL(endpoint, sockets); err != nil {
+			return nil, diag.FromErr(err)
+		}
+	}
+
+	httpClient, err := buildHTTPClient(d, sockets)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	return &MockClient{
+		BackendURL: backendURL,
+		HTTPClient: httpClient,
+		Endpoints:  endpoints,
+		Region:     region,
+	}, nil
+}
+
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: Provider,
-	})
+	ctx := context.Background()
+
+	server, err := newMuxServer(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := tf5server.Serve(providerAddress, func() tfprotov5.ProviderServer { return server }); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// memoryBackend is a minimal in-memory backend shared by the tests of
+// resources that look at other resources. It stores resources by type and
+// ID and answers list requests filtered by query parameters. Updates merge
+// into the stored attributes, and IAM entities are keyed by name, as the
+// real backend does. It sends no ETags, so every write is unconditional, and
+// has no batch-read endpoint. Tests needing more add it here rather than in
+// their own file.
+type memoryBackend struct {
+	mu        sync.Mutex
+	resources map[string]map[string]map[string]interface{}
+	nextID    int
+
+	// synth, when set, assigns IDs and fills computed attributes on create
+	// the way the backend would, but deterministically.
+	synth   *valueSynthesizer
+	schemas map[string]*schema.Resource
+}
+
+func newMemoryBackend(t *testing.T) (*memoryBackend, *MockClient) {
+	t.Helper()
+	b := &memoryBackend{resources: make(map[string]map[string]map[string]interface{})}
+	server := httptest.NewServer(b)
+	t.Cleanup(server.Close)
+	return b, &MockClient{BackendURL: server.URL, HTTPClient: server.Client(), Region: "us-east-1"}
+}
+
+// newSynthesizingBackend is a memoryBackend whose creates return synthesized
+// IDs and computed values derived from seed.
+func newSynthesizingBackend(t *testing.T, seed string) (*memoryBackend, *MockClient) {
+	t.Helper()
+	b, client := newMemoryBackend(t)
+	b.synth = newValueSynthesizer(seed, client.Region)
+	b.schemas = Provider().ResourcesMap
+	return b, client
+}
+
+func (b *memoryBackend) put(resourceType, id string, attrs map[string]interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.resources[resourceType] == nil {
+		b.resources[resourceType] = make(map[string]map[string]interface{})
+	}
+	b.resources[resourceType][id] = attrs
+}
+
+func (b *memoryBackend) get(resourceType, id string) map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.resources[resourceType][id]
+}
+
+func (b *memoryBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Like backends older than the batch-read endpoint, it has none, so
+	// batched reads fall back to one request per resource.
+	if !strings.HasPrefix(r.URL.Path, "/resource/") {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/resource/"), "/")
+	resourceType := parts[0]
+
+	if len(parts) == 1 && r.Method == "GET" {
+		b.mu.Lock()
+		var matches []ResourceResponse
+		for id, attrs := range b.resources[resourceType] {
+			match := true
+			for key := range r.URL.Query() {
+				if fmt.Sprint(attrs[key]) != r.URL.Query().Get(key) {
+					match = false
+				}
+			}
+			if match {
+				matches = append(matches, ResourceResponse{ID: id, Attributes: attrs})
+			}
+		}
+		b.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"resources": matches})
+		return
+	}
+
+	var body struct {
+		Attributes map[string]interface{} `json:"attributes"`
+	}
+	switch r.Method {
+	case "POST":
+		json.NewDecoder(r.Body).Decode(&body)
+		b.mu.Lock()
+		b.nextID++
+		id := fmt.Sprintf("%s-%d", resourceType, b.nextID)
+		b.mu.Unlock()
+		if name, ok := body.Attributes["name"].(string); ok && strings.HasPrefix(resourceType, "aws_iam_") {
+			id = name
+		}
+		if r, ok := b.schemas[resourceType]; ok && b.synth != nil {
+			id = b.synth.resourceID(resourceType, fmt.Sprint(b.nextID), body.Attributes)
+			b.synth.fillComputed(resourceType, id, r.Schema, body.Attributes)
+		}
+		b.put(resourceType, id, body.Attributes)
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(ResourceResponse{ID: id, Attributes: body.Attributes})
+	case "PUT":
+		// Updates merge into the stored attributes, as the backend's
+		// generic handler does.
+		json.NewDecoder(r.Body).Decode(&body)
+		attrs := make(map[string]interface{})
+		for key, value := range b.get(resourceType, parts[1]) {
+			attrs[key] = value
+		}
+		for key, value := range body.Attributes {
+			attrs[key] = value
+		}
+		b.put(resourceType, parts[1], attrs)
+		json.NewEncoder(w).Encode(ResourceResponse{ID: parts[1], Attributes: attrs})
+	case "GET":
+		attrs := b.get(resourceType, parts[1])
+		if attrs == nil {
+			w.WriteHeader(404)
+			return
+		}
+		json.NewEncoder(w).Encode(ResourceResponse{ID: parts[1], Attributes: attrs})
+	case "DELETE":
+		b.mu.Lock()
+		delete(b.resources[resourceType], parts[1])
+		b.mu.Unlock()
+		w.WriteHeader(204)
+	}
+}
+
+func createResource(t *testing.T, name string, client *MockClient, values map[string]interface{}) (*schema.ResourceData, error) {
+	t.Helper()
+	res := Provider().ResourcesMap[name]
+	d := res.TestResourceData()
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			t.Fatalf("setting %s: %v", key, err)
+		}
+	}
+	if diags := res.CreateContext(context.Background(), d, client); diags.HasError() {
+		return d, fmt.Errorf("%s", diags[0].Summary)
+	}
+	return d, nil
+}
+
+// configuredMuxServer returns a mux server configured against b.
+func configuredMuxServer(t *testing.T, b *memoryBackend) tfprotov5.ProviderServer {
+	t.Helper()
+	ctx := context.Background()
+
+	routes := http.NewServeMux()
+	routes.HandleFunc("/provider/configure", func(w http.ResponseWriter, r *http.Request) {})
+	routes.Handle("/", b)
+	backend := httptest.NewServer(routes)
+	t.Cleanup(backend.Close)
+
+	server, err := newMuxServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := schemaResp.Provider.Block
+	values := make(map[string]tftypes.Value)
+	for _, a := range block.Attributes {
+		values[a.Name] = tftypes.NewValue(a.Type, nil)
+	}
+	for _, bt := range block.BlockTypes {
+		values[bt.TypeName] = tftypes.NewValue(bt.ValueType(), []tftypes.Value{})
+	}
+	values["backend_url"] = tftypes.NewValue(tftypes.String, backend.URL)
+	values["region"] = tftypes.NewValue(tftypes.String, "us-east-1")
+
+	config, err := tfprotov5.NewDynamicValue(block.ValueType(), tftypes.NewValue(block.ValueType(), values))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.ConfigureProvider(ctx, &tfprotov5.ConfigureProviderRequest{Config: &config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Fatalf("configuring provider: %s: %s", d.Summary, d.Detail)
+	}
+	return server
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// addMissingResourceWarnings makes every resource explain itself when a
+// refresh finds it gone. Reads drop a resource the backend no longer has
+// from state without a word; the wrapper then asks the backend for its
+// tombstone to tell a resource the backend deleted from an ID it never
+// held, and, outside an import, warns that the next apply will create it
+// again.
+func addMissingResourceWarnings(resources map[string]*schema.Resource) {
+	for resourceType, r := range resources {
+		r.ReadContext = withMissingResourceWarning(resourceType, r.ReadContext)
+	}
+}
+
+func withMissingResourceWarning(resourceType string, next func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if next == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		id := d.Id()
+		importing := justImported(d)
+		diags := next(ctx, d, meta)
+		if diags.HasError() || id == "" || d.Id() != "" {
+			return diags
+		}
+		client, ok := meta.(*MockClient)
+		if !ok {
+			return diags
+		}
+		return append(diags, missingResourceWarning(client, resourceType, id, importing))
+	}
+}
+
+// justImported reports whether d is the state an importer has just made,
+// which is an ID and nothing else: every importer here only sets the ID,
+// while a refresh starts from what the last read or apply stored.
+func justImported(d *schema.ResourceData) bool {
+	state := d.State()
+	if state == nil {
+		return false
+	}
+	for key, value := range state.Attributes {
+		empty := (strings.HasSuffix(key, ".#") || strings.HasSuffix(key, ".%")) && value == "0"
+		if key != "id" && !empty {
+			return false
+		}
+	}
+	return true
+}
+
+// missingResourceWarning describes why resourceType id is missing from the
+// backend, as far as the backend knows. A resource being imported was never
+// in state, so there is nothing to recreate and no advice about it.
+func missingResourceWarning(client *MockClient, resourceType, id string, importing bool) diag.Diagnostic {
+	recreate := "\n\nTerraform has removed it from state and will plan to create it again. " +
+		"If it should not be recreated, remove it from the configuration."
+	if importing {
+		recreate = ""
+	}
+
+	tombstone, err := client.ReadTombstone(resourceType, id)
+	switch {
+	case err != nil:
+		return diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%s %s no longer exists", resourceType, id),
+			Detail: fmt.Sprintf("The backend has no %s with this ID, and asking it why failed: %s.%s",
+				resourceType, err, recreate),
+		}
+	case tombstone != nil:
+		return diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary: fmt.Sprintf("%s %s was deleted from the backend at %s",
+				resourceType, id, tombstone.DeletedAt.UTC().Format(time.RFC3339)),
+			Detail: fmt.Sprintf("The backend holds a record of deleting this %s but no longer has it.%s",
+				resourceType, recreate),
+		}
+	default:
+		return diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%s %s not found", resourceType, id),
+			Detail: fmt.Sprintf("The backend has never deleted a %s with this ID, so the ID is likely wrong: "+
+				"mistyped in an import or state edit, or from a backend whose state has since been reset.%s",
+				resourceType, recreate),
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// refreshMissing reads resourceType id through the provider from a state
+// holding attributes, expecting the read to drop it from state, and returns
+// the diagnostics. Without attributes the state is one an import just made.
+func refreshMissing(t *testing.T, client *MockClient, resourceType, id string, attributes map[string]string) diag.Diagnostics {
+	t.Helper()
+	r := Provider().ResourcesMap[resourceType]
+	state := &terraform.InstanceState{ID: id, Attributes: map[string]string{"id": id}}
+	maps.Copy(state.Attributes, attributes)
+	d := r.Data(state)
+	diags := r.ReadContext(context.Background(), d, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatalf("%s %s should have been removed from state", resourceType, id)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("diagnostics = %v, want one warning", diags)
+	}
+	return diags
+}
+
+func TestMissingResourceDeletedFromBackend(t *testing.T) {
+	client := newOfflineClient(filepath.Join(t.TempDir(), "state.json"), "us-east-1", Provider().ResourcesMap)
+
+	// A hand-written resource and a dynamic one warn alike.
+	for resourceType, attrs := range map[string]map[string]string{
+		"aws_vpc":       {"cidr_block": "10.0.0.0/16"},
+		"aws_sqs_queue": {"name": "jobs"},
+	} {
+		created, err := client.CreateResource(resourceType, stringAttributes(attrs))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.DeleteResource(resourceType, created.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		diags := refreshMissing(t, client, resourceType, created.ID, attrs)
+		if !strings.Contains(diags[0].Summary, "deleted from the backend at ") {
+			t.Errorf("%s: summary = %q, want when the backend deleted it", resourceType, diags[0].Summary)
+		}
+		if !strings.Contains(diags[0].Detail, "create it again") {
+			t.Errorf("%s: detail = %q, want it to explain the recreate", resourceType, diags[0].Detail)
+		}
+
+		// Importing it finds it gone too, but there is nothing to recreate.
+		diags = refreshMissing(t, client, resourceType, created.ID, nil)
+		if !strings.Contains(diags[0].Summary, "deleted from the backend at ") {
+			t.Errorf("%s: import summary = %q, want when the backend deleted it", resourceType, diags[0].Summary)
+		}
+		if strings.Contains(diags[0].Detail, "create it again") {
+			t.Errorf("%s: import detail = %q, should not talk of recreating it", resourceType, diags[0].Detail)
+		}
+	}
+}
+
+func stringAttributes(attrs map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(attrs))
+	for key, value := range attrs {
+		result[key] = value
+	}
+	return result
+}
+
+func TestMissingResourceNeverExisted(t *testing.T) {
+	client := newOfflineClient(filepath.Join(t.TempDir(), "state.json"), "us-east-1", Provider().ResourcesMap)
+
+	diags := refreshMissing(t, client, "aws_vpc", "vpc-typo", nil)
+	if !strings.Contains(diags[0].Summary, "not found") || !strings.Contains(diags[0].Detail, "likely wrong") {
+		t.Errorf("diagnostic = %q / %q, want the ID reported as wrong", diags[0].Summary, diags[0].Detail)
+	}
+}
+
+func TestMissingResourceRecreatedClearsTombstone(t *testing.T) {
+	client := newOfflineClient(filepath.Join(t.TempDir(), "state.json"), "us-east-1", Provider().ResourcesMap)
+
+	created, _ := client.CreateResource("aws_sqs_queue", map[string]interface{}{"name": "jobs"})
+	client.DeleteResource("aws_sqs_queue", created.ID)
+	if tombstone, err := client.ReadTombstone("aws_sqs_queue", "jobs"); err != nil || tombstone == nil {
+		t.Fatalf("tombstone = %v, %v; want one after delete", tombstone, err)
+	}
+
+	client.CreateResource("aws_sqs_queue", map[string]interface{}{"name": "jobs"})
+	if tombstone, err := client.ReadTombstone("aws_sqs_queue", "jobs"); err != nil || tombstone != nil {
+		t.Errorf("tombstone = %v, %v; want none once the queue exists again", tombstone, err)
+	}
+}
+
+func TestOfflineStoreCapsTombstones(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := &storeData{Resources: map[string]map[string]*storedResource{}}
+	for i := 0; i <= maxTombstones; i++ {
+		data.bury("aws_vpc", fmt.Sprintf("vpc-%d", i), start.Add(time.Duration(i)*time.Second))
+	}
+
+	buried := data.Tombstones["aws_vpc"]
+	if len(buried) != maxTombstones {
+		t.Errorf("kept %d tombstones, want %d", len(buried), maxTombstones)
+	}
+	if buried["vpc-0"] != nil {
+		t.Error("the oldest tombstone should have been pruned")
+	}
+	if buried[fmt.Sprintf("vpc-%d", maxTombstones)] == nil {
+		t.Error("the newest tombstone should be kept")
+	}
+}
+
+func TestMissingResourceWithoutTombstones(t *testing.T) {
+	// Backends without the tombstone endpoint still get a warning.
+	_, client := newMemoryBackend(t)
+
+	diags := refreshMissing(t, client, "aws_subnet", "subnet-gone", map[string]string{"vpc_id": "vpc-1"})
+	if !strings.Contains(diags[0].Summary, "not found") {
+		t.Errorf("summary = %q, want not found", diags[0].Summary)
+	}
+}
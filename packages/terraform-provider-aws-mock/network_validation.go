@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// notFoundCodes holds the EC2 error code reported when a referenced resource
+// of each type does not exist.
+var notFoundCodes = map[string]string{
+	"aws_eip":              "InvalidAllocationID.NotFound",
+	"aws_instance":         "InvalidInstanceID.NotFound",
+	"aws_internet_gateway": "InvalidInternetGatewayID.NotFound",
+	"aws_nat_gateway":      "NatGatewayNotFound",
+	"aws_route_table":      "InvalidRouteTableID.NotFound",
+	"aws_subnet":           "InvalidSubnetID.NotFound",
+	"aws_vpc":              "InvalidVpcID.NotFound",
+}
+
+// readReference reads a resource named by another resource's argument and
+// fails with the EC2 NotFound error if it doesn't exist.
+func readReference(client *MockClient, resourceType, id, argument string) (*ResourceResponse, diag.Diagnostics) {
+	result, err := client.ReadResource(resourceType, id)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	if result == nil {
+		code := notFoundCodes[resourceType]
+		if code == "" {
+			code = "InvalidParameterValue"
+		}
+		return nil, diag.Errorf("%s: %s '%s' referenced by %s does not exist", code, resourceType, id, argument)
+	}
+	return result, nil
+}
+
+// stringAttribute returns a string attribute from a backend response.
+func stringAttribute(r *ResourceResponse, key string) string {
+	v, _ := r.Attributes[key].(string)
+	return v
+}
+
+// routeTargetVpcID works out which VPC a route target lives in. ok is false
+// for targets the mock doesn't model (e.g. transit gateways), which are
+// accepted without a network check.
+func routeTargetVpcID(client *MockClient, argument, targetID string) (vpcID string, ok bool, diags diag.Diagnostics) {
+	switch {
+	case argument == "gateway_id" && targetID == "local":
+		return "", false, nil
+
+	case argument == "gateway_id" && strings.HasPrefix(targetID, "igw-"):
+		igw, diags := readReference(client, "aws_internet_gateway", targetID, argument)
+		if diags.HasError() {
+			return "", false, diags
+		}
+		vpcID := stringAttribute(igw, "vpc_id")
+		if vpcID == "" {
+			return "", false, diag.Errorf("Gateway.NotAttached: internet gateway %s is not attached to any VPC", targetID)
+		}
+		return vpcID, true, nil
+
+	case argument == "nat_gateway_id":
+		nat, diags := readReference(client, "aws_nat_gateway", targetID, argument)
+		if diags.HasError() {
+			return "", false, diags
+		}
+		subnet, diags := readReference(client, "aws_subnet", stringAttribute(nat, "subnet_id"), "subnet_id")
+		if diags.HasError() {
+			return "", false, diags
+		}
+		return stringAttribute(subnet, "vpc_id"), true, nil
+	}
+
+	return "", false, nil
+}
+
+// routeTargetArguments lists the route arguments whose targets are checked
+// against the route table's VPC.
+var routeTargetArguments = []string{"gateway_id", "nat_gateway_id"}
+
+// validateRouteTargets checks that every gateway a route points at lives in
+// the route table's VPC.
+func validateRouteTargets(client *MockClient, routeTableID, vpcID string, route map[string]interface{}) diag.Diagnostics {
+	for _, argument := range routeTargetArguments {
+		targetID, _ := route[argument].(string)
+		if targetID == "" {
+			continue
+		}
+
+		targetVpcID, ok, diags := routeTargetVpcID(client, argument, targetID)
+		if diags.HasError() {
+			return diags
+		}
+		if ok && targetVpcID != vpcID {
+			return diag.Errorf("InvalidParameterValue: route table %s and %s %s belong to different networks (%s and %s)",
+				routeTableID, argument, targetID, vpcID, targetVpcID)
+		}
+	}
+	return nil
+}
+
+// describeRouteTable names a route table in errors, falling back to a
+// placeholder before it has an ID.
+func describeRouteTable(id string) string {
+	if id == "" {
+		return "(new route table)"
+	}
+	return id
+}
+
+// validateInternetGatewayAttachment refuses a second internet gateway on a VPC.
+func validateInternetGatewayAttachment(client *MockClient, igwID, vpcID string) diag.Diagnostics {
+	if vpcID == "" {
+		return nil
+	}
+	if _, diags := readReference(client, "aws_vpc", vpcID, "vpc_id"); diags.HasError() {
+		return diags
+	}
+
+	attached, err := client.ListResources("aws_internet_gateway", map[string]string{"vpc_id": vpcID})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for _, igw := range attached {
+		if igw.ID != igwID && stringAttribute(&igw, "vpc_id") == vpcID {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Resource.AlreadyAssociated: resource %s is already attached to network gateway %s", vpcID, igw.ID),
+				Detail:   "A VPC can have only one internet gateway attached at a time.",
+			}}
+		}
+	}
+	return nil
+}
+
+// validateRouteTableAssociation checks that a subnet or edge gateway is in
+// the same VPC as the route table it is being associated with.
+func validateRouteTableAssociation(client *MockClient, routeTableID, subnetID, gatewayID string) diag.Diagnostics {
+	if (subnetID == "") == (gatewayID == "") {
+		return diag.Errorf("InvalidParameterValue: exactly one of subnet_id or gateway_id must be set")
+	}
+
+	routeTable, diags := readReference(client, "aws_route_table", routeTableID, "route_table_id")
+	if diags.HasError() {
+		return diags
+	}
+	vpcID := stringAttribute(routeTable, "vpc_id")
+
+	if subnetID != "" {
+		subnet, diags := readReference(client, "aws_subnet", subnetID, "subnet_id")
+		if diags.HasError() {
+			return diags
+		}
+		if subnetVpcID := stringAttribute(subnet, "vpc_id"); subnetVpcID != vpcID {
+			return diag.Errorf("InvalidParameterValue: route table %s and subnet %s belong to different networks (%s and %s)",
+				routeTableID, subnetID, vpcID, subnetVpcID)
+		}
+		return nil
+	}
+
+	gatewayVpcID, ok, diags := routeTargetVpcID(client, "gateway_id", gatewayID)
+	if diags.HasError() {
+		return diags
+	}
+	if ok && gatewayVpcID != vpcID {
+		return diag.Errorf("InvalidParameterValue: route table %s and gateway %s belong to different networks (%s and %s)",
+			routeTableID, gatewayID, vpcID, gatewayVpcID)
+	}
+	return nil
+}
+
+// mainRouteTableID returns the main route table of a VPC: the one its
+// aws_main_route_table_association makes main, or else the VPC's own. The
+// association lives on its own record rather than being written to the VPC,
+// so making a route table main doesn't change the VPC's version. list finds
+// the association: ListResources, or ListResourcesBatched on refresh so
+// every VPC refreshed together shares one list call. A backend without the
+// list route or the association type (404 or 405) has no associations to
+// report, so the VPC's own main route table stands.
+func mainRouteTableID(vpc *ResourceResponse, list func(string, map[string]string) ([]ResourceResponse, error)) (string, error) {
+	associations, err := list("aws_main_route_table_association", map[string]string{"vpc_id": vpc.ID})
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == 404 || apiErr.StatusCode == 405) {
+		associations, err = nil, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, association := range associations {
+		if routeTableID := stringAttribute(&association, "route_table_id"); routeTableID != "" {
+			return routeTableID, nil
+		}
+	}
+	return stringAttribute(vpc, "main_route_table_id"), nil
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// twoVpcNetwork seeds two VPCs, each with a subnet, route table and internet
+// gateway.
+func twoVpcNetwork(b *memoryBackend) {
+	for _, n := range []string{"a", "b"} {
+		b.put("aws_vpc", "vpc-"+n, map[string]interface{}{"cidr_block": "10.0.0.0/16", "main_route_table_id": "rtb-main-" + n})
+		b.put("aws_subnet", "subnet-"+n, map[string]interface{}{"vpc_id": "vpc-" + n, "cidr_block": "10.0.1.0/24"})
+		b.put("aws_route_table", "rtb-"+n, map[string]interface{}{"vpc_id": "vpc-" + n})
+		b.put("aws_internet_gateway", "igw-"+n, map[string]interface{}{"vpc_id": "vpc-" + n})
+	}
+}
+
+func TestProviderDeclaresNetworkingResources(t *testing.T) {
+	p := Provider()
+	for _, name := range []string{
+		"aws_route_table", "aws_route", "aws_route_table_association", "aws_main_route_table_association",
+		"aws_internet_gateway", "aws_nat_gateway", "aws_eip",
+	} {
+		res := p.ResourcesMap[name]
+		if res == nil {
+			t.Errorf("%s resource missing from provider", name)
+			continue
+		}
+		if res.CreateContext == nil || res.ReadContext == nil || res.UpdateContext == nil || res.DeleteContext == nil {
+			t.Errorf("%s should have full CRUD", name)
+		}
+	}
+}
+
+func TestRouteTargetMustShareVpc(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	twoVpcNetwork(b)
+
+	if _, err := createResource(t, "aws_route", client, map[string]interface{}{
+		"route_table_id": "rtb-a", "destination_cidr_block": "0.0.0.0/0", "gateway_id": "igw-a",
+	}); err != nil {
+		t.Errorf("route to the VPC's own gateway should succeed: %v", err)
+	}
+
+	_, err := createResource(t, "aws_route", client, map[string]interface{}{
+		"route_table_id": "rtb-a", "destination_cidr_block": "0.0.0.0/0", "gateway_id": "igw-b",
+	})
+	if err == nil || !strings.Contains(err.Error(), "different networks") {
+		t.Errorf("expected a different networks error, got %v", err)
+	}
+}
+
+func TestRouteTableInlineRoutesValidated(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	twoVpcNetwork(b)
+	b.put("aws_nat_gateway", "nat-b", map[string]interface{}{"subnet_id": "subnet-b"})
+
+	_, err := createResource(t, "aws_route_table", client, map[string]interface{}{
+		"vpc_id": "vpc-a",
+		"route": []interface{}{
+			map[string]interface{}{"cidr_block": "0.0.0.0/0", "nat_gateway_id": "nat-b"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "different networks") {
+		t.Errorf("expected a different networks error for a NAT gateway in another VPC, got %v", err)
+	}
+
+	_, err = createResource(t, "aws_route_table", client, map[string]interface{}{
+		"vpc_id": "vpc-a",
+		"route": []interface{}{
+			map[string]interface{}{"cidr_block": "0.0.0.0/0", "gateway_id": "igw-missing"},
+		},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "InvalidInternetGatewayID.NotFound") {
+		t.Errorf("expected InvalidInternetGatewayID.NotFound, got %v", err)
+	}
+}
+
+func TestRouteToDetachedGatewayFails(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	twoVpcNetwork(b)
+	b.put("aws_internet_gateway", "igw-detached", map[string]interface{}{})
+
+	_, err := createResource(t, "aws_route", client, map[string]interface{}{
+		"route_table_id": "rtb-a", "destination_cidr_block": "0.0.0.0/0", "gateway_id": "igw-detached",
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "Gateway.NotAttached") {
+		t.Errorf("expected Gateway.NotAttached, got %v", err)
+	}
+}
+
+func TestOneInternetGatewayPerVpc(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	twoVpcNetwork(b)
+	b.put("aws_vpc", "vpc-c", map[string]interface{}{})
+
+	_, err := createResource(t, "aws_internet_gateway", client, map[string]interface{}{"vpc_id": "vpc-a"})
+	if err == nil || !strings.HasPrefix(err.Error(), "Resource.AlreadyAssociated") {
+		t.Errorf("expected Resource.AlreadyAssociated, got %v", err)
+	}
+
+	if _, err := createResource(t, "aws_internet_gateway", client, map[string]interface{}{"vpc_id": "vpc-c"}); err != nil {
+		t.Errorf("first gateway on a VPC should attach: %v", err)
+	}
+}
+
+func TestRouteTableAssociationSubnetMustShareVpc(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	twoVpcNetwork(b)
+
+	_, err := createResource(t, "aws_route_table_association", client, map[string]interface{}{
+		"route_table_id": "rtb-a", "subnet_id": "subnet-b",
+	})
+	if err == nil || !strings.Contains(err.Error(), "different networks") {
+		t.Errorf("expected a different networks error, got %v", err)
+	}
+
+	_, err = createResource(t, "aws_route_table_association", client, map[string]interface{}{
+		"route_table_id": "rtb-a", "subnet_id": "subnet-a", "gateway_id": "igw-a",
+	})
+	if err == nil || !strings.Contains(err.Error(), "exactly one of") {
+		t.Errorf("expected an exactly-one error, got %v", err)
+	}
+}
+
+func TestMainRouteTableAssociationLeavesVpcRecordAlone(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	twoVpcNetwork(b)
+	vpc := Provider().ResourcesMap["aws_vpc"]
+	readVpc := func() *schema.ResourceData {
+		t.Helper()
+		d := vpc.TestResourceData()
+		d.SetId("vpc-a")
+		if diags := vpc.ReadContext(context.Background(), d, client); diags.HasError() {
+			t.Fatalf("reading the VPC: %v", diags)
+		}
+		return d
+	}
+
+	d, err := createResource(t, "aws_main_route_table_association", client, map[string]interface{}{
+		"vpc_id": "vpc-a", "route_table_id": "rtb-a",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := b.get("aws_vpc", "vpc-a")["main_route_table_id"]; got != "rtb-main-a" {
+		t.Errorf("the VPC record should not be written, main_route_table_id = %v", got)
+	}
+	if got := readVpc().Get("main_route_table_id"); got != "rtb-a" {
+		t.Errorf("expected the VPC to read main_route_table_id rtb-a, got %v", got)
+	}
+	if d.Get("original_route_table_id").(string) != "rtb-main-a" {
+		t.Errorf("expected original_route_table_id rtb-main-a, got %s", d.Get("original_route_table_id"))
+	}
+
+	res := Provider().ResourcesMap["aws_main_route_table_association"]
+	if diags := res.DeleteContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected delete error: %v", diags)
+	}
+	if got := readVpc().Get("main_route_table_id"); got != "rtb-main-a" {
+		t.Errorf("expected the original main route table restored, got %v", got)
+	}
+
+	if _, err := createResource(t, "aws_main_route_table_association", client, map[string]interface{}{
+		"vpc_id": "vpc-a", "route_table_id": "rtb-b",
+	}); err == nil {
+		t.Error("expected an error making another VPC's route table main")
+	}
+}
+
+func TestVpcRefreshSharesOneAssociationList(t *testing.T) {
+	var mu sync.Mutex
+	var lists int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/resources/batch-read":
+			var body struct {
+				Resources []ResourceRef `json:"resources"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			items := make([]map[string]interface{}, 0, len(body.Resources))
+			for _, ref := range body.Resources {
+				items = append(items, map[string]interface{}{"type": ref.Type, "id": ref.ID, "found": true,
+					"attributes": map[string]interface{}{"cidr_block": "10.0.0.0/16", "main_route_table_id": "rtb-main-" + ref.ID}})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"resources": items})
+		case "/resource/aws_main_route_table_association":
+			mu.Lock()
+			lists++
+			mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]interface{}{"resources": []map[string]interface{}{
+				{"id": "rtbassoc-1", "attributes": map[string]interface{}{"vpc_id": "vpc-3", "route_table_id": "rtb-custom"}},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	client.batcher = newReadBatcher(client, 20*time.Millisecond)
+	vpc := Provider().ResourcesMap["aws_vpc"]
+
+	mainRouteTables := make([]string, 5)
+	var wg sync.WaitGroup
+	for i := range mainRouteTables {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d := vpc.TestResourceData()
+			d.SetId(fmt.Sprintf("vpc-%d", i))
+			if diags := vpc.ReadContext(context.Background(), d, client); diags.HasError() {
+				t.Errorf("reading %s: %v", d.Id(), diags)
+				return
+			}
+			mainRouteTables[i] = d.Get("main_route_table_id").(string)
+		}(i)
+	}
+	wg.Wait()
+
+	if lists != 1 {
+		t.Errorf("refreshing 5 VPCs listed associations %d times, want once", lists)
+	}
+	if mainRouteTables[3] != "rtb-custom" || mainRouteTables[0] != "rtb-main-vpc-0" {
+		t.Errorf("main_route_table_id = %v, want the association's for vpc-3 and the VPC's own otherwise", mainRouteTables)
+	}
+}
+
+func TestVpcReadWithoutAssociationListing(t *testing.T) {
+	for _, status := range []int{404, 405} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" && r.URL.Path == "/resource/aws_vpc/vpc-1" {
+				json.NewEncoder(w).Encode(ResourceResponse{ID: "vpc-1", Attributes: map[string]interface{}{"main_route_table_id": "rtb-main"}})
+				return
+			}
+			w.WriteHeader(status)
+		}))
+
+		vpc := Provider().ResourcesMap["aws_vpc"]
+		d := vpc.TestResourceData()
+		d.SetId("vpc-1")
+		diags := vpc.ReadContext(context.Background(), d, &MockClient{BackendURL: server.URL, HTTPClient: server.Client()})
+		server.Close()
+		if diags.HasError() {
+			t.Errorf("status %d: a backend without the list route should not fail the read: %v", status, diags)
+			continue
+		}
+		if got := d.Get("main_route_table_id"); got != "rtb-main" {
+			t.Errorf("status %d: main_route_table_id = %v, want the VPC's own", status, got)
+		}
+	}
+}
+
+func TestNatGatewayTakesAddressesFromSubnetAndEip(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	twoVpcNetwork(b)
+	b.put("aws_eip", "eipalloc-1", map[string]interface{}{"public_ip": "3.4.5.6"})
+
+	d, err := createResource(t, "aws_nat_gateway", client, map[string]interface{}{
+		"subnet_id": "subnet-a", "allocation_id": "eipalloc-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Get("public_ip").(string) != "3.4.5.6" {
+		t.Errorf("expected public_ip from the EIP, got %s", d.Get("public_ip"))
+	}
+	if err := validatePrivateIP(d.Get("private_ip").(string), "10.0.1.0/24"); err != nil {
+		t.Errorf("private_ip not allocated from subnet: %v", err)
+	}
+
+	if _, err := createResource(t, "aws_nat_gateway", client, map[string]interface{}{"subnet_id": "subnet-a"}); err == nil {
+		t.Error("expected a public NAT gateway without allocation_id to fail")
+	}
+	if _, err := createResource(t, "aws_nat_gateway", client, map[string]interface{}{
+		"subnet_id": "subnet-a", "connectivity_type": "private",
+	}); err != nil {
+		t.Errorf("private NAT gateway should not need an EIP: %v", err)
+	}
+}
+
+func TestEipAssociatedInstanceMustExist(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	b.put("aws_instance", "i-1", map[string]interface{}{"private_ip": "10.0.1.9"})
+
+	d, err := createResource(t, "aws_eip", client, map[string]interface{}{"instance": "i-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Get("private_ip").(string) != "10.0.1.9" || d.Get("public_ip").(string) == "" || d.Get("domain").(string) != "vpc" {
+		t.Errorf("unexpected EIP attributes: private_ip=%s public_ip=%s domain=%s",
+			d.Get("private_ip"), d.Get("public_ip"), d.Get("domain"))
+	}
+
+	_, err = createResource(t, "aws_eip", client, map[string]interface{}{"instance": "i-missing"})
+	if err == nil || !strings.HasPrefix(err.Error(), "InvalidInstanceID.NotFound") {
+		t.Errorf("expected InvalidInstanceID.NotFound, got %v", err)
+	}
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// offlineBackendURL is the placeholder backend URL of an offline client.
+// Requests to it never leave the process.
+const offlineBackendURL = "http://aws-mock.offline"
+
+// offlineSeed seeds the values synthesized for offline resources, so a
+// fresh state file always gets the same IDs and ARNs.
+const offlineSeed = "aws-mock-offline"
+
+// validRegions are the regions ConfigureProvider accepts, as in the
+// backend's validation.ts.
+var validRegions = map[string]bool{
+	"us-east-1": true, "us-east-2": true, "us-west-1": true, "us-west-2": true,
+	"af-south-1": true, "ap-east-1": true, "ap-south-1": true, "ap-south-2": true,
+	"ap-southeast-1": true, "ap-southeast-2": true, "ap-southeast-3": true, "ap-southeast-4": true,
+	"ap-northeast-1": true, "ap-northeast-2": true, "ap-northeast-3": true,
+	"ca-central-1": true, "ca-west-1": true,
+	"eu-central-1": true, "eu-central-2": true, "eu-west-1": true, "eu-west-2": true,
+	"eu-west-3": true, "eu-south-1": true, "eu-south-2": true, "eu-north-1": true,
+	"il-central-1": true, "me-south-1": true, "me-central-1": true, "sa-east-1": true,
+}
+
+// offlineBackend serves the backend's HTTP API from a fileStore inside the
+// provider process, so Terraform runs without a backend server. Like the
+// backend's generic handler it stores the attributes it is sent, keeps
+// tags_all and arn up to date, and synthesizes the remaining computed
+// attributes from the resource schemas.
+type offlineBackend struct {
+	store   *fileStore
+	synth   *valueSynthesizer
+	schemas map[string]*schema.Resource
+	mux     *http.ServeMux
+}
+
+func newOfflineBackend(path, region string, schemas map[string]*schema.Resource) *offlineBackend {
+	b := &offlineBackend{
+		store:   newFileStore(path),
+		synth:   newValueSynthesizer(offlineSeed, region),
+		schemas: schemas,
+		mux:     http.NewServeMux(),
+	}
+	b.mux.HandleFunc("POST /provider/configure", b.configure)
+	b.mux.HandleFunc("POST /resources/batch-read", b.batchRead)
+	b.mux.HandleFunc("POST /resource/{type}", b.create)
+	b.mux.HandleFunc("GET /resource/{type}", b.list)
+	b.mux.HandleFunc("GET /resource/{type}/{id...}", b.read)
+	b.mux.HandleFunc("PUT /resource/{type}/{id...}", b.update)
+	b.mux.HandleFunc("DELETE /resource/{type}/{id...}", b.delete)
+	b.mux.HandleFunc("POST /drift/{type}/{id...}", b.drift)
+	b.mux.HandleFunc("GET /tombstone/{type}/{id...}", b.tombstone)
+	return b
+}
+
+// newOfflineClient returns a client whose requests are served by an
+// offlineBackend keeping its resources in the state file at path.
+func newOfflineClient(path, region string, schemas map[string]*schema.Resource) *MockClient {
+	backend := newOfflineBackend(path, region, schemas)
+	return &MockClient{
+		BackendURL: offlineBackendURL,
+		HTTPClient: &http.Client{Transport: &handlerTransport{handler: backend}},
+		Region:     region,
+	}
+}
+
+// handlerTransport answers requests by calling handler directly.
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t *handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+func (b *offlineBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeResource answers with a resource and its version in the ETag header.
+func writeResource(w http.ResponseWriter, status int, resource *storedResource) {
+	w.Header().Set("ETag", ifMatch(resource.etag()))
+	writeJSON(w, status, resourceResponse(resource))
+}
+
+func resourceResponse(resource *storedResource) ResourceResponse {
+	return ResourceResponse{ID: resource.ID, Attributes: resource.Attributes, Version: resource.etag()}
+}
+
+// versionMatches reports whether the request's If-Match header, if it has
+// one, names the resource's current version.
+func versionMatches(r *http.Request, resource *storedResource) bool {
+	match := r.Header.Get("If-Match")
+	if match == "" || match == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(match, ",") {
+		if strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`) == resource.etag() {
+			return true
+		}
+	}
+	return false
+}
+
+func versionMismatch(resourceType string, resource *storedResource) error {
+	return fmt.Errorf("Resource %s/%s has changed: it is now at version %s", resourceType, resource.ID, resource.etag())
+}
+
+// dependencyViolation refuses a delete, as the backend does, while other
+// resources still depend on the one being deleted.
+type dependencyViolation struct {
+	message string
+}
+
+func (e *dependencyViolation) Error() string {
+	return e.message
+}
+
+// dependents describes what still depends on resource, or returns "" if
+// nothing does: subnets in a VPC, and instances using a security group by
+// ID in vpc_security_group_ids or by ID or name in security_groups.
+func (data *storeData) dependents(resourceType string, resource *storedResource) string {
+	switch resourceType {
+	case "aws_vpc":
+		for _, subnet := range data.Resources["aws_subnet"] {
+			if subnet.Attributes["vpc_id"] == resource.ID {
+				return fmt.Sprintf("The vpc '%s' has dependencies and cannot be deleted.", resource.ID)
+			}
+		}
+	case "aws_security_group":
+		name, _ := resource.Attributes["name"].(string)
+		for _, instance := range data.Resources["aws_instance"] {
+			for _, attr := range []string{"vpc_security_group_ids", "security_groups"} {
+				groups, _ := instance.Attributes[attr].([]interface{})
+				for _, group := range groups {
+					if group == resource.ID || (name != "" && group == name) {
+						return fmt.Sprintf("resource %s has a dependent object", resource.ID)
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// resourceType returns the request's resource type, answering 404 itself
+// when the provider has no such resource.
+func (b *offlineBackend) resourceType(w http.ResponseWriter, r *http.Request) (string, bool) {
+	resourceType := r.PathValue("type")
+	if _, ok := b.schemas[resourceType]; !ok {
+		writeError(w, http.StatusNotFound, "Unknown resource type: "+resourceType)
+		return "", false
+	}
+	return resourceType, true
+}
+
+func decodeAttributes(r *http.Request) (map[string]interface{}, error) {
+	var body struct {
+		Attributes map[string]interface{} `json:"attributes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Attributes == nil {
+		return nil, fmt.Errorf("Missing attributes")
+	}
+	return body.Attributes, nil
+}
+
+func (b *offlineBackend) configure(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Region string `json:"region"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+	if !validRegions[body.Region] {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid region: %q is not a valid AWS region", body.Region))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"region": body.Region})
+}
+
+func (b *offlineBackend) create(w http.ResponseWriter, r *http.Request) {
+	resourceType, ok := b.resourceType(w, r)
+	if !ok {
+		return
+	}
+	attrs, err := decodeAttributes(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var created *storedResource
+	err = b.store.update(func(data *storeData) error {
+		if data.Resources[resourceType] == nil {
+			data.Resources[resourceType] = make(map[string]*storedResource)
+		}
+		existing := data.Resources[resourceType]
+
+		id := b.newID(data, resourceType, attrs)
+		attrs["id"] = id
+		b.refreshAttributes(resourceType, id, attrs, true)
+		b.synth.fillComputed(resourceType, id, b.schemas[resourceType].Schema, attrs)
+
+		created = &storedResource{ID: id, Attributes: attrs, Version: 1}
+		existing[id] = created
+		delete(data.Tombstones[resourceType], id)
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeResource(w, http.StatusCreated, created)
+}
+
+// newID returns the ID of a new resource: its name for types identified by
+// name, as the backend does, otherwise the next synthesized ID from the
+// type's counter in the state file. The counter only grows, so a deleted
+// resource's ID is never handed out again; IDs already taken or buried, as
+// in files written before the counter, are skipped.
+func (b *offlineBackend) newID(data *storeData, resourceType string, attrs map[string]interface{}) string {
+	if attr, ok := nameAsIDAttributes[resourceType]; ok {
+		if name, _ := attrs[attr].(string); name != "" {
+			return name
+		}
+	}
+	if data.NextIDs == nil {
+		data.NextIDs = make(map[string]int)
+	}
+	for {
+		data.NextIDs[resourceType]++
+		id := b.synth.resourceID(resourceType, strconv.Itoa(data.NextIDs[resourceType]), attrs)
+		if data.Resources[resourceType][id] == nil && data.Tombstones[resourceType][id] == nil {
+			return id
+		}
+	}
+}
+
+// refreshAttributes recomputes tags_all from tags, when tags were sent, and
+// the ARN, as the backend does on create and update.
+func (b *offlineBackend) refreshAttributes(resourceType, id string, attrs map[string]interface{}, tagsSent bool) {
+	sch := b.schemas[resourceType].Schema
+	if tags, ok := attrs["tags"].(map[string]interface{}); ok && tagsSent {
+		if _, ok := sch["tags_all"]; ok {
+			attrs["tags_all"] = maps.Clone(tags)
+		}
+	}
+	if field, ok := sch["arn"]; ok && field.Computed {
+		attrs["arn"] = b.synth.arn(resourceType, id)
+	}
+}
+
+func (b *offlineBackend) read(w http.ResponseWriter, r *http.Request) {
+	resourceType, ok := b.resourceType(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+
+	var found *storedResource
+	err := b.store.view(func(data *storeData) error {
+		found = data.Resources[resourceType][id]
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if found == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource %s/%s not found", resourceType, id))
+		return
+	}
+	writeResource(w, http.StatusOK, found)
+}
+
+func (b *offlineBackend) update(w http.ResponseWriter, r *http.Request) {
+	resourceType, ok := b.resourceType(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+	attrs, err := decodeAttributes(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var updated *storedResource
+	status := http.StatusBadRequest
+	err = b.store.update(func(data *storeData) error {
+		existing := data.Resources[resourceType][id]
+		if existing == nil {
+			return fmt.Errorf("Resource %s/%s not found", resourceType, id)
+		}
+		if !versionMatches(r, existing) {
+			status = http.StatusPreconditionFailed
+			return versionMismatch(resourceType, existing)
+		}
+		// The sent attributes replace the stored ones, as in the backend's
+		// StateStore, so an argument removed from configuration goes away.
+		// Computed-only attributes are never sent and stay as they are.
+		_, tagsSent := attrs["tags"]
+		for name, value := range existing.Attributes {
+			if field, ok := b.schemas[resourceType].Schema[name]; ok && field.Computed && !field.Optional {
+				if _, sent := attrs[name]; !sent {
+					attrs[name] = value
+				}
+			}
+		}
+		attrs["id"] = id
+		b.refreshAttributes(resourceType, id, attrs, tagsSent)
+		existing.Attributes = attrs
+		existing.Version++
+		updated = existing
+		return nil
+	})
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	writeResource(w, http.StatusOK, updated)
+}
+
+func (b *offlineBackend) delete(w http.ResponseWriter, r *http.Request) {
+	resourceType, ok := b.resourceType(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+
+	var found bool
+	status := http.StatusInternalServerError
+	err := b.store.update(func(data *storeData) error {
+		existing := data.Resources[resourceType][id]
+		if found = existing != nil; !found {
+			return nil
+		}
+		if !versionMatches(r, existing) {
+			status = http.StatusPreconditionFailed
+			return versionMismatch(resourceType, existing)
+		}
+		if message := data.dependents(resourceType, existing); message != "" {
+			status = http.StatusBadRequest
+			return &dependencyViolation{message: message}
+		}
+		data.bury(resourceType, id, b.store.now())
+		return nil
+	})
+	var violation *dependencyViolation
+	if errors.As(err, &violation) {
+		writeJSON(w, status, map[string]string{"error": violation.message, "code": errCodeDependencyViolation})
+		return
+	}
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource %s/%s not found", resourceType, id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tombstone answers when a resource that no longer exists was deleted.
+func (b *offlineBackend) tombstone(w http.ResponseWriter, r *http.Request) {
+	resourceType, ok := b.resourceType(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+
+	var found *tombstone
+	err := b.store.view(func(data *storeData) error {
+		found = data.Tombstones[resourceType][id]
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if found == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource %s/%s was never deleted", resourceType, id))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"type": resourceType, "id": id, "deleted_at": found.DeletedAt})
+}
+
+// drift changes a resource's attributes outside Terraform, now or, when
+// the body carries apply_at, once that time has passed.
+func (b *offlineBackend) drift(w http.ResponseWriter, r *http.Request) {
+	resourceType, ok := b.resourceType(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+
+	var body struct {
+		Attributes map[string]interface{} `json:"attributes"`
+		ApplyAt    *time.Time             `json:"apply_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if body.Attributes == nil {
+		writeError(w, http.StatusBadRequest, "Missing attributes")
+		return
+	}
+	scheduled := body.ApplyAt != nil && body.ApplyAt.After(b.store.now())
+
+	var drifted *storedResource
+	err := b.store.update(func(data *storeData) error {
+		drifted = data.Resources[resourceType][id]
+		if drifted == nil {
+			return nil
+		}
+		if scheduled {
+			data.Drift = append(data.Drift, &pendingDrift{Type: resourceType, ID: id, Attributes: body.Attributes, ApplyAt: *body.ApplyAt})
+		} else {
+			applyDrift(drifted, body.Attributes)
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if drifted == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource %s/%s not found", resourceType, id))
+		return
+	}
+	if scheduled {
+		writeResource(w, http.StatusAccepted, drifted)
+		return
+	}
+	writeResource(w, http.StatusOK, drifted)
+}
+
+// list returns the resources of a type whose attributes equal every query
+// parameter.
+func (b *offlineBackend) list(w http.ResponseWriter, r *http.Request) {
+	resourceType, ok := b.resourceType(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	resources := []ResourceResponse{}
+	err := b.store.view(func(data *storeData) error {
+		for _, id := range slices.Sorted(maps.Keys(data.Resources[resourceType])) {
+			resource := data.Resources[resourceType][id]
+			match := true
+			for key := range query {
+				if fmt.Sprint(resource.Attributes[key]) != query.Get(key) {
+					match = false
+					break
+				}
+			}
+			if match {
+				resources = append(resources, resourceResponse(resource))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"resources": resources})
+}
+
+// batchRead reads many resources under a single lock of the state file.
+func (b *offlineBackend) batchRead(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Resources []ResourceRef `json:"resources"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items := make([]batchReadItem, 0, len(body.Resources))
+	err := b.store.view(func(data *storeData) error {
+		for _, ref := range body.Resources {
+			item := batchReadItem{Type: ref.Type, ID: ref.ID}
+			if resource := data.Resources[ref.Type][ref.ID]; resource != nil {
+				item.Found = true
+				item.Attributes = resource.Attributes
+				item.Version = resource.etag()
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"resources": items})
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// storeVersion is the version of the state file format, shared with the
+// backend's StateStore.
+const storeVersion = 1
+
+type storedResource struct {
+	ID         string                 `json:"id"`
+	Attributes map[string]interface{} `json:"attributes"`
+
+	// Version counts the changes made to the resource, starting at 1 on
+	// create. It is sent as the ETag and checked against If-Match.
+	Version int `json:"version,omitempty"`
+}
+
+// etag returns the resource's version as the ETag and If-Match headers
+// carry it, without the quotes.
+func (r *storedResource) etag() string {
+	return strconv.Itoa(r.Version)
+}
+
+// pendingDrift is a change to a resource's attributes scheduled by
+// aws_mock_drift, applied the first time the store is loaded after ApplyAt.
+type pendingDrift struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Attributes map[string]interface{} `json:"attributes"`
+	ApplyAt    time.Time              `json:"apply_at"`
+}
+
+// tombstone is what the store remembers of a deleted resource.
+type tombstone struct {
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// storeData is the state file: resources by type, then by ID, drift not
+// yet applied, tombstones of deleted resources and, per type, the number of
+// IDs handed out so far. It has the same shape as the backend's state.json,
+// so either can open the other's file.
+type storeData struct {
+	Version    int                                   `json:"version"`
+	Resources  map[string]map[string]*storedResource `json:"resources"`
+	Drift      []*pendingDrift                       `json:"drift,omitempty"`
+	Tombstones map[string]map[string]*tombstone      `json:"tombstones,omitempty"`
+	NextIDs    map[string]int                        `json:"next_ids,omitempty"`
+}
+
+// maxTombstones is how many tombstones the store keeps per resource type,
+// as in the backend's StateStore. Beyond it the oldest are forgotten, so a
+// long-lived state file doesn't grow with every resource ever deleted.
+const maxTombstones = 100
+
+// bury deletes a resource, leaving a tombstone recording when and pruning
+// the type's oldest tombstones beyond maxTombstones.
+func (data *storeData) bury(resourceType, id string, now time.Time) {
+	delete(data.Resources[resourceType], id)
+	if data.Tombstones == nil {
+		data.Tombstones = make(map[string]map[string]*tombstone)
+	}
+	if data.Tombstones[resourceType] == nil {
+		data.Tombstones[resourceType] = make(map[string]*tombstone)
+	}
+	data.Tombstones[resourceType][id] = &tombstone{DeletedAt: now.UTC()}
+
+	buried := data.Tombstones[resourceType]
+	for len(buried) > maxTombstones {
+		oldest := ""
+		for id, t := range buried {
+			if oldest == "" || t.DeletedAt.Before(buried[oldest].DeletedAt) ||
+				t.DeletedAt.Equal(buried[oldest].DeletedAt) && id < oldest {
+				oldest = id
+			}
+		}
+		delete(buried, oldest)
+	}
+}
+
+// applyDueDrift applies the pending drift whose time has come and reports
+// whether there was any.
+func (data *storeData) applyDueDrift(now time.Time) bool {
+	var waiting []*pendingDrift
+	for _, drift := range data.Drift {
+		if drift.ApplyAt.After(now) {
+			waiting = append(waiting, drift)
+			continue
+		}
+		if resource := data.Resources[drift.Type][drift.ID]; resource != nil {
+			applyDrift(resource, drift.Attributes)
+		}
+	}
+	applied := len(waiting) != len(data.Drift)
+	data.Drift = waiting
+	return applied
+}
+
+// applyDrift merges drifted attributes into a resource. Drifting tags
+// drifts tags_all with them, as a change made in the console would.
+func applyDrift(resource *storedResource, attrs map[string]interface{}) {
+	maps.Copy(resource.Attributes, attrs)
+	resource.Version++
+	tags, ok := attrs["tags"].(map[string]interface{})
+	if _, has := resource.Attributes["tags_all"]; ok && has {
+		if _, sent := attrs["tags_all"]; !sent {
+			resource.Attributes["tags_all"] = maps.Clone(tags)
+		}
+	}
+}
+
+// fileStore keeps resources in a JSON file on disk. Every operation loads,
+// changes and saves the whole file while holding both an in-process mutex
+// and an exclusive lock on a sibling .lock file, so parallel resource
+// operations and other provider processes sharing the file don't lose
+// each other's writes.
+type fileStore struct {
+	path string
+	now  func() time.Time
+	mu   sync.Mutex
+}
+
+func newFileStore(path string) *fileStore {
+	return &fileStore{path: path, now: time.Now}
+}
+
+// view runs fn on the current contents of the store.
+func (s *fileStore) view(fn func(*storeData) error) error {
+	return s.withLock(func(data *storeData) (bool, error) {
+		return false, fn(data)
+	})
+}
+
+// update runs fn on the current contents of the store and saves them
+// afterwards unless fn fails.
+func (s *fileStore) update(fn func(*storeData) error) error {
+	return s.withLock(func(data *storeData) (bool, error) {
+		return true, fn(data)
+	})
+}
+
+func (s *fileStore) withLock(fn func(*storeData) (bool, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	lock, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("locking %s: %w", s.path, err)
+	}
+	defer unlockFile(lock)
+
+	data, err := s.load()
+	if err != nil {
+		return err
+	}
+	drifted := data.applyDueDrift(s.now())
+	save, err := fn(data)
+	if err != nil {
+		return err
+	}
+	if !save && !drifted {
+		return nil
+	}
+	return s.save(data)
+}
+
+// load reads the state file. A missing or empty file is an empty store.
+func (s *fileStore) load() (*storeData, error) {
+	data := &storeData{Version: storeVersion}
+	raw, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, data); err != nil {
+			return nil, fmt.Errorf("reading state file %s: %w", s.path, err)
+		}
+		if data.Version != storeVersion {
+			return nil, fmt.Errorf("state file %s has unsupported version %d", s.path, data.Version)
+		}
+	}
+	if data.Resources == nil {
+		data.Resources = make(map[string]map[string]*storedResource)
+	}
+	return data, nil
+}
+
+// save writes the state file through a temporary file and a rename, so a
+// crash mid-write never leaves a truncated file behind.
+func (s *fileStore) save(data *storeData) error {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
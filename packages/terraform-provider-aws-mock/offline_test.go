@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestFileStoreKeepsParallelWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	// Two stores on one file stand in for two provider processes, which
+	// only the file lock keeps apart.
+	stores := []*fileStore{newFileStore(path), newFileStore(path)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := stores[i%2].update(func(data *storeData) error {
+				if data.Resources["aws_vpc"] == nil {
+					data.Resources["aws_vpc"] = make(map[string]*storedResource)
+				}
+				id := fmt.Sprintf("vpc-%d", i)
+				data.Resources["aws_vpc"][id] = &storedResource{ID: id, Attributes: map[string]interface{}{"id": id}}
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data storeData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Version != storeVersion {
+		t.Errorf("version = %d, want %d", data.Version, storeVersion)
+	}
+	if got := len(data.Resources["aws_vpc"]); got != 40 {
+		t.Errorf("stored %d VPCs, want 40", got)
+	}
+}
+
+func TestFileStoreRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"version": 2, "resources": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := newFileStore(path).view(func(*storeData) error { return nil })
+	if err == nil {
+		t.Fatal("expected an error for an unsupported version")
+	}
+}
+
+func TestProviderConfiguresOfflineClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	p := Provider()
+
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"region":      "eu-west-1",
+		"state_file":  path,
+		"backend_url": "http://127.0.0.1:1",
+	}))
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	client := p.Meta().(*MockClient)
+	if client.BackendURL != offlineBackendURL {
+		t.Errorf("BackendURL = %q, want the offline backend", client.BackendURL)
+	}
+
+	diags = Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"region":     "mars-north-1",
+		"state_file": path,
+	}))
+	if !diags.HasError() {
+		t.Error("expected an invalid region to be rejected offline")
+	}
+}
+
+func TestOfflineClientLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	resources := Provider().ResourcesMap
+	client := newOfflineClient(path, "us-east-1", resources)
+	ctx := context.Background()
+
+	vpc := resources["aws_vpc"]
+	d := vpc.TestResourceData()
+	d.Set("cidr_block", "10.0.0.0/16")
+	d.Set("tags", map[string]interface{}{"Name": "main"})
+	if diags := vpc.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if !regexp.MustCompile(`^vpc-0[0-9a-f]{16}$`).MatchString(d.Id()) {
+		t.Errorf("id %q is not a VPC ID", d.Id())
+	}
+	if got, want := d.Get("arn"), "arn:aws:ec2:us-east-1:123456789012:vpc/"+d.Id(); got != want {
+		t.Errorf("arn = %v, want %s", got, want)
+	}
+
+	// A second client on the same file sees the resource, as a later
+	// terraform run would.
+	other := newOfflineClient(path, "us-east-1", resources)
+	stored, err := other.ReadResource("aws_vpc", d.Id())
+	if err != nil || stored == nil {
+		t.Fatalf("reading from a second client: %v, %v", stored, err)
+	}
+	if tags, _ := stored.Attributes["tags_all"].(map[string]interface{}); tags["Name"] != "main" {
+		t.Errorf("tags_all = %v, want the configured tags", stored.Attributes["tags_all"])
+	}
+
+	listed, err := other.ListResources("aws_vpc", map[string]string{"cidr_block": "10.0.0.0/16"})
+	if err != nil || len(listed) != 1 {
+		t.Errorf("list = %v, %v; want the VPC", listed, err)
+	}
+
+	ownerID := stored.Attributes["owner_id"]
+	if _, err := client.UpdateResource("aws_vpc", d.Id(), map[string]interface{}{
+		"tags": map[string]interface{}{"Name": "renamed"},
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	stored, _ = other.ReadResource("aws_vpc", d.Id())
+	if _, ok := stored.Attributes["cidr_block"]; ok {
+		t.Error("an attribute left out of an update should go away, as the update replaces the attributes")
+	}
+	if ownerID == nil || stored.Attributes["owner_id"] != ownerID {
+		t.Errorf("owner_id = %v after update, want the computed %v kept", stored.Attributes["owner_id"], ownerID)
+	}
+	if tags, _ := stored.Attributes["tags_all"].(map[string]interface{}); tags["Name"] != "renamed" {
+		t.Errorf("tags_all = %v after update", stored.Attributes["tags_all"])
+	}
+
+	if err := client.DeleteResource("aws_vpc", d.Id()); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if stored, _ := other.ReadResource("aws_vpc", d.Id()); stored != nil {
+		t.Error("resource should be gone after delete")
+	}
+}
+
+func TestOfflineClientConflictingWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	resources := Provider().ResourcesMap
+	first := newOfflineClient(path, "us-east-1", resources)
+	second := newOfflineClient(path, "us-east-1", resources)
+
+	created, err := first.CreateResource("aws_vpc", map[string]interface{}{"cidr_block": "10.0.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Version != "1" {
+		t.Fatalf("created version = %q, want 1", created.Version)
+	}
+
+	// Both writers read version 1; the first to write wins.
+	read, _ := second.ReadResource("aws_vpc", created.ID)
+	updated, err := first.UpdateResourceIfMatch("aws_vpc", created.ID, created.Version, map[string]interface{}{"enable_dns_hostnames": true})
+	if err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	if updated.Version != "2" {
+		t.Errorf("updated version = %q, want 2", updated.Version)
+	}
+
+	_, err = second.UpdateResourceIfMatch("aws_vpc", created.ID, read.Version, map[string]interface{}{"enable_dns_hostnames": false})
+	if _, ok := err.(*PreconditionFailedError); !ok {
+		t.Fatalf("stale update: got %v, want PreconditionFailedError", err)
+	}
+	if err := second.DeleteResourceIfMatch("aws_vpc", created.ID, read.Version); err == nil {
+		t.Fatal("stale delete should fail")
+	}
+	if stored, _ := second.ReadResource("aws_vpc", created.ID); stored == nil || stored.Attributes["enable_dns_hostnames"] != true {
+		t.Errorf("stale writes should leave the first write in place, got %v", stored)
+	}
+
+	// Drift is a change like any other.
+	if err := first.DriftResource("aws_vpc", created.ID, map[string]interface{}{"enable_dns_support": false}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.UpdateResourceIfMatch("aws_vpc", created.ID, updated.Version, map[string]interface{}{}); err == nil {
+		t.Error("an update should fail after the resource drifted")
+	}
+	current, _ := second.ReadResource("aws_vpc", created.ID)
+	if current.Version != "3" {
+		t.Errorf("version after drift = %q, want 3", current.Version)
+	}
+	if err := second.DeleteResourceIfMatch("aws_vpc", created.ID, current.Version); err != nil {
+		t.Errorf("delete at the current version: %v", err)
+	}
+}
+
+func TestOfflineClientNeverReusesDeletedIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	client := newOfflineClient(path, "us-east-1", Provider().ResourcesMap)
+
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		created, err := client.CreateResource("aws_vpc", map[string]interface{}{"cidr_block": "10.0.0.0/16"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[created.ID] {
+			t.Fatalf("create %d reused ID %s", i, created.ID)
+		}
+		seen[created.ID] = true
+		if err := client.DeleteResource("aws_vpc", created.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A file written before the counter still skips buried IDs.
+	if err := newFileStore(path).update(func(data *storeData) error {
+		data.NextIDs = nil
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	created, err := client.CreateResource("aws_vpc", map[string]interface{}{"cidr_block": "10.0.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen[created.ID] {
+		t.Errorf("create without a counter reused deleted ID %s", created.ID)
+	}
+}
+
+func TestOfflineClientNamesResourcesByName(t *testing.T) {
+	client := newOfflineClient(filepath.Join(t.TempDir(), "state.json"), "us-east-1", Provider().ResourcesMap)
+
+	created, err := client.CreateResource("aws_sqs_queue", map[string]interface{}{"name": "jobs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != "jobs" {
+		t.Errorf("id = %q, want the queue name", created.ID)
+	}
+	if got, want := created.Attributes["arn"], "arn:aws:sqs:us-east-1:123456789012:jobs"; got != want {
+		t.Errorf("arn = %v, want %s", got, want)
+	}
+
+	if _, err := client.CreateResource("aws_no_such_thing", map[string]interface{}{}); err == nil {
+		t.Error("expected unknown resource types to be rejected")
+	}
+}
+
+func TestOfflineDeleteRefusesResourcesWithDependents(t *testing.T) {
+	client := newOfflineClient(filepath.Join(t.TempDir(), "state.json"), "us-east-1", Provider().ResourcesMap)
+	create := func(resourceType string, attrs map[string]interface{}) string {
+		t.Helper()
+		created, err := client.CreateResource(resourceType, attrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created.ID
+	}
+
+	vpc := create("aws_vpc", map[string]interface{}{"cidr_block": "10.0.0.0/16"})
+	create("aws_subnet", map[string]interface{}{"vpc_id": vpc, "cidr_block": "10.0.1.0/24"})
+	byID := create("aws_security_group", map[string]interface{}{"name": "web", "vpc_id": vpc})
+	byName := create("aws_security_group", map[string]interface{}{"name": "classic", "vpc_id": vpc})
+	create("aws_instance", map[string]interface{}{
+		"vpc_security_group_ids": []interface{}{byID},
+		"security_groups":        []interface{}{"classic"},
+	})
+
+	for _, target := range []struct{ resourceType, id string }{
+		{"aws_vpc", vpc},
+		{"aws_security_group", byID},
+		{"aws_security_group", byName},
+	} {
+		err := client.DeleteResource(target.resourceType, target.id)
+		if !hasErrorCode(err, errCodeDependencyViolation) {
+			t.Errorf("deleting %s %s: got %v, want DependencyViolation", target.resourceType, target.id, err)
+		}
+	}
+}
+
+func TestOfflineDeleteWaitsOutDependencyViolation(t *testing.T) {
+	client := newOfflineClient(filepath.Join(t.TempDir(), "state.json"), "us-east-1", Provider().ResourcesMap)
+	vpc, err := client.CreateResource("aws_vpc", map[string]interface{}{"cidr_block": "10.0.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subnet, err := client.CreateResource("aws_subnet", map[string]interface{}{"vpc_id": vpc.ID, "cidr_block": "10.0.1.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The subnet goes while the VPC delete is retrying, as when Terraform
+	// deletes both without depends_on ordering them.
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		client.DeleteResource("aws_subnet", subnet.ID)
+	}()
+
+	res := Provider().ResourcesMap["aws_vpc"]
+	d := res.TestResourceData()
+	d.SetId(vpc.ID)
+	if diags := res.DeleteContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got, err := client.ReadResource("aws_vpc", vpc.ID); err != nil || got != nil {
+		t.Errorf("VPC still there after delete: %v, %v", got, err)
+	}
+}
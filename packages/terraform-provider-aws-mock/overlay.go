@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceOverlay layers AWS behaviour onto a dynamic resource, for types
+// that need a little realism but no hand-written CRUD.
+type resourceOverlay struct {
+	// fields adjusts attributes of the dynamic schema. Attributes inside
+	// blocks are named with dots, e.g. "ingress.cidr_blocks".
+	fields map[string]fieldOverlay
+
+	// importID names the attributes the real provider's import ID is made
+	// of, joined by "/", e.g. "subnet_id/route_table_id". Import looks up
+	// the resource whose attributes match; backend IDs and identities are
+	// still accepted. Empty imports by backend ID only.
+	importID string
+
+	// validate runs before every create and update, e.g. to check that
+	// referenced resources exist.
+	validate func(d *schema.ResourceData, client *MockClient) diag.Diagnostics
+
+	// deleteTimeout, when set, retries deletes the backend rejects with
+	// DependencyViolation for up to this long by default.
+	deleteTimeout time.Duration
+
+	// handWritten marks a type that used to be a hand-written override. It
+	// keeps the hand-written schema version so state written then still
+	// loads.
+	handWritten bool
+}
+
+// fieldOverlay is what an overlay sets on one attribute. Unset fields leave
+// the dynamic schema alone.
+type fieldOverlay struct {
+	forceNew bool
+
+	// defaultValue makes the attribute optional rather than computed, as
+	// the SDK doesn't allow defaults on computed attributes.
+	defaultValue interface{}
+
+	validateFunc     schema.SchemaValidateFunc
+	diffSuppressFunc schema.SchemaDiffSuppressFunc
+}
+
+// applyResourceOverlays layers resourceOverlays onto the dynamic resources.
+// Types missing from the embedded schema are skipped. An overlay naming an
+// attribute its type doesn't have is left out and reported in the error, so
+// that a schema update dropping an attribute doesn't stop the provider from
+// starting.
+func applyResourceOverlays(resources map[string]*schema.Resource) error {
+	var errs []error
+	for _, resourceType := range slices.Sorted(maps.Keys(resourceOverlays)) {
+		r, ok := resources[resourceType]
+		if !ok {
+			continue
+		}
+		if err := resourceOverlays[resourceType].apply(resourceType, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply layers the overlay onto r, leaving r as it is if a field names an
+// attribute r doesn't have.
+func (o resourceOverlay) apply(resourceType string, r *schema.Resource) error {
+	fields := make(map[*schema.Schema]fieldOverlay, len(o.fields))
+	for _, name := range slices.Sorted(maps.Keys(o.fields)) {
+		s := overlaidField(r.Schema, name)
+		if s == nil {
+			return fmt.Errorf("overlay for %s: no attribute %s", resourceType, name)
+		}
+		fields[s] = o.fields[name]
+	}
+	for s, field := range fields {
+		field.apply(s)
+	}
+
+	// The SDK rejects an Update on a resource nothing can update in place.
+	if !updatableInPlace(r.Schema) {
+		r.UpdateContext = nil
+	}
+
+	if o.validate != nil {
+		r.CreateContext = withOverlayValidation(o.validate, r.CreateContext)
+		if r.UpdateContext != nil {
+			r.UpdateContext = withOverlayValidation(o.validate, r.UpdateContext)
+		}
+	}
+
+	if o.deleteTimeout > 0 {
+		r.Timeouts = dependencyViolationTimeouts(o.deleteTimeout)
+		r.DeleteContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			if diags := deleteRetryingDependencyViolation(ctx, d, meta.(*MockClient), resourceType); diags.HasError() {
+				return diags
+			}
+			d.SetId("")
+			return nil
+		}
+	}
+
+	if o.importID != "" {
+		r.Importer = &schema.ResourceImporter{
+			StateContext: importByAttributes(resourceType, strings.Split(o.importID, "/")),
+		}
+	}
+
+	if o.handWritten {
+		addDynamicStateUpgrader(r, nil)
+	}
+	return nil
+}
+
+func (f fieldOverlay) apply(s *schema.Schema) {
+	if f.forceNew {
+		s.ForceNew = true
+	}
+	if f.defaultValue != nil {
+		s.Default = f.defaultValue
+		s.Optional = true
+		s.Required = false
+		s.Computed = false
+	}
+	if f.validateFunc != nil {
+		s.ValidateFunc = f.validateFunc
+	}
+	if f.diffSuppressFunc != nil {
+		s.DiffSuppressFunc = f.diffSuppressFunc
+	}
+}
+
+// updatableInPlace reports whether any argument can change without
+// replacing the resource.
+func updatableInPlace(s map[string]*schema.Schema) bool {
+	for _, field := range s {
+		if !field.ForceNew && (field.Optional || field.Required) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaidField finds a possibly dotted attribute name in a schema.
+func overlaidField(s map[string]*schema.Schema, name string) *schema.Schema {
+	head, rest, nested := strings.Cut(name, ".")
+	field := s[head]
+	if !nested || field == nil {
+		return field
+	}
+	block, ok := field.Elem.(*schema.Resource)
+	if !ok {
+		return nil
+	}
+	return overlaidField(block.Schema, rest)
+}
+
+func withOverlayValidation(validate func(*schema.ResourceData, *MockClient) diag.Diagnostics, next func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		if diags := validate(d, meta.(*MockClient)); diags.HasError() {
+			return diags
+		}
+		return next(ctx, d, meta)
+	}
+}
+
+// importByAttributes imports a resource by the attribute values its import
+// ID is made of, looking up which backend ID holds them. Identities, and
+// IDs without one part per attribute, are passed through as backend IDs.
+func importByAttributes(resourceType string, names []string) schema.StateContextFunc {
+	passthrough := schema.ImportStatePassthroughWithIdentity(resourceIdentityKey)
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		parts := strings.Split(d.Id(), "/")
+		if d.Id() == "" || len(parts) != len(names) {
+			return passthrough(ctx, d, meta)
+		}
+
+		filters := make(map[string]string, len(names))
+		for i, name := range names {
+			filters[name] = parts[i]
+		}
+		found, err := meta.(*MockClient).ListResources(resourceType, filters)
+		if err != nil {
+			return nil, err
+		}
+		switch len(found) {
+		case 0:
+			// Not one of the real provider's import IDs after all
+			return passthrough(ctx, d, meta)
+		case 1:
+			d.SetId(found[0].ID)
+			return []*schema.ResourceData{d}, nil
+		default:
+			return nil, fmt.Errorf("import ID %q (%s) matches %d %s resources", d.Id(), strings.Join(names, "/"), len(found), resourceType)
+		}
+	}
+}
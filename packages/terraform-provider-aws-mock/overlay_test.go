@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceOverlaysApply(t *testing.T) {
+	p := Provider()
+	for resourceType := range resourceOverlays {
+		r, ok := p.ResourcesMap[resourceType]
+		if !ok {
+			continue
+		}
+		if err := r.InternalValidate(nil, true); err != nil {
+			t.Errorf("%s: %v", resourceType, err)
+		}
+	}
+
+	queue := p.ResourcesMap["aws_sqs_queue"]
+	if !queue.Schema["name"].ForceNew {
+		t.Error("aws_sqs_queue.name should force a new queue")
+	}
+	if _, errs := queue.Schema["name"].ValidateFunc("orders queue", "name"); len(errs) == 0 {
+		t.Error("aws_sqs_queue.name should reject spaces")
+	}
+	if _, errs := queue.Schema["name"].ValidateFunc("orders.fifo", "name"); len(errs) != 0 {
+		t.Errorf("aws_sqs_queue.name should accept FIFO names: %v", errs)
+	}
+
+	if lambda := p.ResourcesMap["aws_lambda_function"]; lambda.UpdateContext != nil {
+		t.Error("aws_lambda_function has nothing to update in place and should have no Update")
+	}
+
+	igw := p.ResourcesMap["aws_internet_gateway"]
+	if igw.Timeouts == nil || *igw.Timeouts.Delete != 20*time.Minute {
+		t.Error("aws_internet_gateway should wait out DependencyViolation for 20 minutes")
+	}
+}
+
+func TestFieldOverlays(t *testing.T) {
+	r := buildDynamicResource("aws_test", map[string]*schema.Schema{
+		"mode": {Type: schema.TypeString, Optional: true, Computed: true},
+		"rule": {Type: schema.TypeList, Optional: true, Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+			"cidr": {Type: schema.TypeString, Optional: true},
+		}}},
+	})
+	suppressCase := func(k, old, new string, d *schema.ResourceData) bool { return strings.EqualFold(old, new) }
+	resourceOverlay{fields: map[string]fieldOverlay{
+		"mode":      {defaultValue: "standard", diffSuppressFunc: suppressCase},
+		"rule.cidr": {forceNew: true},
+	}}.apply("aws_test", r)
+
+	mode := r.Schema["mode"]
+	if mode.Default != "standard" || mode.Computed || !mode.Optional || mode.DiffSuppressFunc == nil {
+		t.Errorf("mode = %+v, want an optional attribute with a default and a diff suppression", mode)
+	}
+	if !r.Schema["rule"].Elem.(*schema.Resource).Schema["cidr"].ForceNew {
+		t.Error("rule.cidr should force a new resource")
+	}
+	if err := r.InternalValidate(nil, true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResourceOverlayUnknownField(t *testing.T) {
+	r := buildDynamicResource("aws_test", map[string]*schema.Schema{
+		"name": {Type: schema.TypeString, Optional: true},
+	})
+	err := resourceOverlay{fields: map[string]fieldOverlay{
+		"name": {forceNew: true},
+		"nmae": {forceNew: true},
+	}}.apply("aws_test", r)
+	if err == nil || !strings.Contains(err.Error(), "nmae") {
+		t.Errorf("expected an error naming the missing attribute, got %v", err)
+	}
+	if r.Schema["name"].ForceNew {
+		t.Error("an overlay with a missing attribute should leave the resource alone")
+	}
+}
+
+// Every overlay must name attributes its type has in the embedded schema;
+// one that doesn't would fail provider configuration.
+func TestEveryOverlayMatchesItsSchema(t *testing.T) {
+	if err := applyResourceOverlays(buildAllDynamicResources()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBrokenOverlayFailsConfigure(t *testing.T) {
+	saved := resourceOverlays["aws_sqs_queue"]
+	defer func() { resourceOverlays["aws_sqs_queue"] = saved }()
+	resourceOverlays["aws_sqs_queue"] = resourceOverlay{fields: map[string]fieldOverlay{"nmae": {forceNew: true}}}
+
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"region":     "us-east-1",
+		"state_file": filepath.Join(t.TempDir(), "state.json"),
+	}))
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "nmae") {
+		t.Errorf("expected configure to report the broken overlay, got %v", diags)
+	}
+}
+
+func TestOverlayImportByAttributes(t *testing.T) {
+	client := newOfflineClient(filepath.Join(t.TempDir(), "state.json"), "us-east-1", Provider().ResourcesMap)
+	topic, err := client.CreateResource("aws_sns_topic", map[string]interface{}{"name": "alerts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Provider().ResourcesMap["aws_sns_topic"]
+
+	// The real provider imports topics by ARN.
+	for _, importID := range []string{topic.Attributes["arn"].(string), topic.ID} {
+		d := r.Data(&terraform.InstanceState{ID: importID})
+		imported, err := r.Importer.StateContext(context.Background(), d, client)
+		if err != nil {
+			t.Fatalf("importing %s: %v", importID, err)
+		}
+		if got := imported[0].Id(); got != topic.ID {
+			t.Errorf("importing %s gave ID %s, want %s", importID, got, topic.ID)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceOverlays is the AWS behaviour layered onto dynamic resources, by
+// type. Prefer an entry here to a hand-written override unless the type
+// needs CRUD the generic resource can't do.
+var resourceOverlays = map[string]resourceOverlay{
+	"aws_db_instance": {
+		fields: map[string]fieldOverlay{
+			"identifier": {forceNew: true, validateFunc: validation.StringMatch(
+				regexp.MustCompile(`^[a-z][a-z0-9-]{0,62}$`),
+				"must start with a lowercase letter and contain only lowercase letters, digits and hyphens, up to 63 characters")},
+		},
+	},
+	"aws_dynamodb_table": {
+		fields: map[string]fieldOverlay{
+			"name": {forceNew: true, validateFunc: validation.StringMatch(
+				regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,255}$`),
+				"must be 3 to 255 letters, digits, underscores, periods or hyphens")},
+		},
+		importID: "name",
+	},
+	"aws_internet_gateway": {
+		validate: func(d *schema.ResourceData, client *MockClient) diag.Diagnostics {
+			if d.Id() != "" && !d.HasChange("vpc_id") {
+				return nil
+			}
+			return validateInternetGatewayAttachment(client, d.Id(), d.Get("vpc_id").(string))
+		},
+		deleteTimeout: 20 * time.Minute,
+		handWritten:   true,
+	},
+	"aws_lambda_function": {
+		fields: map[string]fieldOverlay{
+			"function_name": {forceNew: true, validateFunc: validation.StringMatch(
+				regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`),
+				"must be 1 to 64 letters, digits, underscores or hyphens")},
+		},
+		importID: "function_name",
+	},
+	"aws_sns_topic": {
+		fields: map[string]fieldOverlay{
+			"name": {forceNew: true},
+		},
+		importID: "arn",
+	},
+	"aws_sqs_queue": {
+		fields: map[string]fieldOverlay{
+			"name": {forceNew: true, validateFunc: validation.StringMatch(
+				regexp.MustCompile(`^[a-zA-Z0-9_-]{1,80}(\.fifo)?$`),
+				"must be 1 to 80 letters, digits, underscores or hyphens, optionally ending in .fifo")},
+		},
+	},
+}
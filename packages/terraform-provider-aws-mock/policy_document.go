@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const errCodeMalformedPolicyDocument = "MalformedPolicyDocument"
+
+// policyVersions are the IAM policy language versions AWS accepts.
+var policyVersions = map[string]bool{"2012-10-17": true, "2008-10-17": true}
+
+// policyStatements parses an IAM policy document and returns its statements,
+// accepting either a single Statement object or an array of them.
+func policyStatements(document string) ([]map[string]interface{}, error) {
+	var policy map[string]interface{}
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return nil, fmt.Errorf("%s: policy is not valid JSON: %w", errCodeMalformedPolicyDocument, err)
+	}
+
+	if version, ok := policy["Version"]; ok {
+		if v, _ := version.(string); !policyVersions[v] {
+			return nil, fmt.Errorf("%s: unsupported policy Version %v", errCodeMalformedPolicyDocument, version)
+		}
+	}
+
+	var raw []interface{}
+	switch s := policy["Statement"].(type) {
+	case []interface{}:
+		raw = s
+	case map[string]interface{}:
+		raw = []interface{}{s}
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%s: policy must contain at least one Statement", errCodeMalformedPolicyDocument)
+	}
+
+	statements := make([]map[string]interface{}, 0, len(raw))
+	for i, r := range raw {
+		statement, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: Statement %d is not an object", errCodeMalformedPolicyDocument, i)
+		}
+		if effect := statement["Effect"]; effect != "Allow" && effect != "Deny" {
+			return nil, fmt.Errorf("%s: Statement %d has invalid Effect %v; must be Allow or Deny",
+				errCodeMalformedPolicyDocument, i, effect)
+		}
+		_, hasAction := statement["Action"]
+		_, hasNotAction := statement["NotAction"]
+		if hasAction == hasNotAction {
+			return nil, fmt.Errorf("%s: Statement %d must contain exactly one of Action or NotAction",
+				errCodeMalformedPolicyDocument, i)
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// validatePolicyDocument checks an identity-based policy: every statement
+// needs a Resource and must not name a Principal.
+func validatePolicyDocument(document string) error {
+	statements, err := policyStatements(document)
+	if err != nil {
+		return err
+	}
+	for i, statement := range statements {
+		if _, ok := statement["Principal"]; ok {
+			return fmt.Errorf("%s: Statement %d has a Principal, which identity-based policies cannot contain",
+				errCodeMalformedPolicyDocument, i)
+		}
+		_, hasResource := statement["Resource"]
+		_, hasNotResource := statement["NotResource"]
+		if !hasResource && !hasNotResource {
+			return fmt.Errorf("%s: Statement %d is missing a Resource", errCodeMalformedPolicyDocument, i)
+		}
+	}
+	return nil
+}
+
+// validateTrustPolicy checks a role's assume_role_policy: every statement
+// names a Principal, allows only sts:AssumeRole* actions and has no Resource.
+func validateTrustPolicy(document string) error {
+	statements, err := policyStatements(document)
+	if err != nil {
+		return err
+	}
+	for i, statement := range statements {
+		if _, ok := statement["Principal"]; !ok {
+			if _, ok := statement["NotPrincipal"]; !ok {
+				return fmt.Errorf("%s: Statement %d of the trust policy is missing a Principal",
+					errCodeMalformedPolicyDocument, i)
+			}
+		}
+		if _, ok := statement["Resource"]; ok {
+			return fmt.Errorf("%s: Statement %d of the trust policy has a Resource, which trust policies cannot contain",
+				errCodeMalformedPolicyDocument, i)
+		}
+		for _, action := range stringOrSlice(statement["Action"]) {
+			if !strings.HasPrefix(action, "sts:AssumeRole") && action != "sts:TagSession" && action != "sts:SetSourceIdentity" {
+				return fmt.Errorf("%s: Statement %d of the trust policy allows %s; only sts:AssumeRole actions are valid",
+					errCodeMalformedPolicyDocument, i, action)
+			}
+		}
+	}
+	return nil
+}
+
+// stringOrSlice reads a policy element that may be a string or a list.
+func stringOrSlice(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// normalizePolicy rewrites a parsed policy so that equivalent documents
+// compare equal: single strings become one-element lists, lists are sorted
+// and a lone Statement object becomes an array.
+func normalizePolicy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if key == "Statement" {
+				if single, ok := value.(map[string]interface{}); ok {
+					value = []interface{}{single}
+				}
+				out[key] = normalizePolicy(value)
+				continue
+			}
+			if s, ok := value.(string); ok && key != "Version" && key != "Sid" && key != "Effect" && key != "Id" {
+				value = []interface{}{s}
+			}
+			out[key] = normalizePolicy(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizePolicy(item)
+		}
+		sort.SliceStable(out, func(i, j int) bool {
+			a, _ := json.Marshal(out[i])
+			b, _ := json.Marshal(out[j])
+			return string(a) < string(b)
+		})
+		return out
+	}
+	return v
+}
+
+// policiesEquivalent reports whether two policy documents grant the same
+// permissions, ignoring whitespace, key order and list-vs-string encoding.
+func policiesEquivalent(a, b string) bool {
+	var pa, pb interface{}
+	if err := json.Unmarshal([]byte(a), &pa); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &pb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(normalizePolicy(pa), normalizePolicy(pb))
+}
+
+// suppressEquivalentPolicyDiffs is a DiffSuppressFunc for policy JSON
+// attributes.
+func suppressEquivalentPolicyDiffs(k, old, new string, d *schema.ResourceData) bool {
+	return policiesEquivalent(old, new)
+}
+
+// validatePolicyDocumentFunc and validateTrustPolicyFunc adapt the policy
+// validators to schema.SchemaValidateFunc.
+func validatePolicyDocumentFunc(v interface{}, k string) ([]string, []error) {
+	if err := validatePolicyDocument(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", k, err)}
+	}
+	return nil, nil
+}
+
+func validateTrustPolicyFunc(v interface{}, k string) ([]string, []error) {
+	if err := validateTrustPolicy(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", k, err)}
+	}
+	return nil, nil
+}
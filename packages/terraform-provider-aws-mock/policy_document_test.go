@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const ec2TrustPolicy = `{
+  "Version": "2012-10-17",
+  "Statement": [{
+    "Effect": "Allow",
+    "Principal": {"Service": "ec2.amazonaws.com"},
+    "Action": "sts:AssumeRole"
+  }]
+}`
+
+func TestValidateTrustPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{"valid", ec2TrustPolicy, ""},
+		{"not json", `{"Version":`, "not valid JSON"},
+		{"bad version", `{"Version":"2020-01-01","Statement":[{"Effect":"Allow","Principal":"*","Action":"sts:AssumeRole"}]}`, "Version"},
+		{"no statements", `{"Version":"2012-10-17","Statement":[]}`, "at least one Statement"},
+		{"bad effect", `{"Statement":{"Effect":"Permit","Principal":"*","Action":"sts:AssumeRole"}}`, "Effect"},
+		{"no principal", `{"Statement":{"Effect":"Allow","Action":"sts:AssumeRole"}}`, "missing a Principal"},
+		{"resource", `{"Statement":{"Effect":"Allow","Principal":"*","Action":"sts:AssumeRole","Resource":"*"}}`, "Resource"},
+		{"wrong action", `{"Statement":{"Effect":"Allow","Principal":"*","Action":["sts:AssumeRole","s3:GetObject"]}}`, "s3:GetObject"},
+	}
+	for _, tt := range tests {
+		err := validateTrustPolicy(tt.document)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.wantErr, err)
+		}
+		if err != nil && !strings.HasPrefix(err.Error(), errCodeMalformedPolicyDocument) {
+			t.Errorf("%s: expected %s error, got %v", tt.name, errCodeMalformedPolicyDocument, err)
+		}
+	}
+}
+
+func TestValidatePolicyDocument(t *testing.T) {
+	valid := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":"*"}]}`
+	if err := validatePolicyDocument(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validatePolicyDocument(ec2TrustPolicy); err == nil {
+		t.Error("expected an error for a Principal in an identity-based policy")
+	}
+	if err := validatePolicyDocument(`{"Statement":{"Effect":"Allow","Action":"s3:*"}}`); err == nil {
+		t.Error("expected an error for a statement without Resource")
+	}
+}
+
+func TestPoliciesEquivalent(t *testing.T) {
+	compact := `{"Version":"2012-10-17","Statement":{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":["sts:AssumeRole"]}}`
+	if !policiesEquivalent(ec2TrustPolicy, compact) {
+		t.Error("expected whitespace, single-statement and string-vs-list differences to be equivalent")
+	}
+
+	reordered := `{"Statement":[{"Effect":"Allow","Action":["s3:PutObject","s3:GetObject"],"Resource":"*"}],"Version":"2012-10-17"}`
+	original := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject","s3:PutObject"],"Resource":"*"}]}`
+	if !policiesEquivalent(original, reordered) {
+		t.Error("expected action order to be ignored")
+	}
+
+	different := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":["s3:GetObject","s3:PutObject"],"Resource":"*"}]}`
+	if policiesEquivalent(original, different) {
+		t.Error("expected a different Effect to be a real change")
+	}
+	if policiesEquivalent(original, "not json") {
+		t.Error("invalid JSON should never be equivalent")
+	}
+}
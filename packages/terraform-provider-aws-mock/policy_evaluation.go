@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Policy evaluation outcomes, named after the IAM policy simulator's
+// EvalDecision values.
+const (
+	decisionAllowed      = "allowed"
+	decisionExplicitDeny = "explicitDeny"
+	decisionImplicitDeny = "implicitDeny"
+)
+
+// policyRequest is the request a policy is evaluated against.
+type policyRequest struct {
+	Principal string
+	Action    string
+	Resource  string
+	Context   map[string][]string
+}
+
+// policyEvaluation is the result of evaluating a policy: the decision and
+// the statements (by Sid, or by index when unnamed) that produced it.
+type policyEvaluation struct {
+	Decision          string
+	MatchedStatements []string
+}
+
+// evaluatePolicy decides a request the way IAM does within a single policy:
+// a matching Deny wins, otherwise a matching Allow allows, otherwise the
+// request is implicitly denied.
+func evaluatePolicy(document string, req policyRequest) (*policyEvaluation, error) {
+	var doc iamPolicyDoc
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		return nil, fmt.Errorf("%s: policy is not valid JSON: %w", errCodeMalformedPolicyDocument, err)
+	}
+
+	var allows, denies []string
+	for i, statement := range doc.Statements {
+		matched, err := statementMatches(statement, req)
+		if err != nil {
+			return nil, fmt.Errorf("Statement %d: %w", i, err)
+		}
+		if !matched {
+			continue
+		}
+		name := statement.Sid
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		switch statement.Effect {
+		case "Deny":
+			denies = append(denies, name)
+		case "Allow":
+			allows = append(allows, name)
+		default:
+			return nil, fmt.Errorf("%s: Statement %d has invalid Effect %q", errCodeMalformedPolicyDocument, i, statement.Effect)
+		}
+	}
+
+	switch {
+	case len(denies) > 0:
+		return &policyEvaluation{Decision: decisionExplicitDeny, MatchedStatements: denies}, nil
+	case len(allows) > 0:
+		return &policyEvaluation{Decision: decisionAllowed, MatchedStatements: allows}, nil
+	}
+	return &policyEvaluation{Decision: decisionImplicitDeny}, nil
+}
+
+func statementMatches(s *iamPolicyStatement, req policyRequest) (bool, error) {
+	if s.Principals != nil && !principalMatches(s.Principals, req.Principal) {
+		return false, nil
+	}
+	if s.NotPrincipals != nil && principalMatches(s.NotPrincipals, req.Principal) {
+		return false, nil
+	}
+
+	if s.Actions != nil && !anyWildcardMatch(stringOrSlice(s.Actions), req.Action, true, nil) {
+		return false, nil
+	}
+	if s.NotActions != nil && anyWildcardMatch(stringOrSlice(s.NotActions), req.Action, true, nil) {
+		return false, nil
+	}
+
+	if s.Resources != nil && !anyWildcardMatch(stringOrSlice(s.Resources), req.Resource, false, req.Context) {
+		return false, nil
+	}
+	if s.NotResources != nil && anyWildcardMatch(stringOrSlice(s.NotResources), req.Resource, false, req.Context) {
+		return false, nil
+	}
+
+	if s.Conditions != nil {
+		return conditionsMatch(s.Conditions, req.Context)
+	}
+	return true, nil
+}
+
+// principalMatches reports whether a Principal element names the caller. An
+// account ID or account root ARN matches every principal in that account.
+func principalMatches(element interface{}, principal string) bool {
+	if s, ok := element.(string); ok {
+		return s == "*"
+	}
+	principals, ok := element.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for principalType, ids := range principals {
+		for _, id := range stringOrSlice(ids) {
+			switch {
+			case id == "*" || id == principal:
+				return true
+			case principalType == "AWS" && principalAccount(id) != "" && principalAccount(id) == principalAccount(principal) &&
+				(isAccountID(id) || strings.HasSuffix(id, ":root")):
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// principalAccount returns the account ID in an IAM ARN or a bare account ID.
+func principalAccount(principal string) string {
+	if isAccountID(principal) {
+		return principal
+	}
+	parts := strings.Split(principal, ":")
+	if len(parts) >= 6 && parts[0] == "arn" && parts[2] == "iam" {
+		return parts[4]
+	}
+	return ""
+}
+
+func isAccountID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// anyWildcardMatch reports whether value matches any pattern. Actions
+// compare case-insensitively; resource patterns may contain ${...} policy
+// variables, which are filled in from the request context.
+func anyWildcardMatch(patterns []string, value string, ignoreCase bool, context map[string][]string) bool {
+	for _, pattern := range patterns {
+		pattern = substitutePolicyVariables(pattern, context)
+		if ignoreCase {
+			pattern, value = strings.ToLower(pattern), strings.ToLower(value)
+		}
+		if wildcardMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch matches IAM wildcards: * is any run of characters
+// (including none) and ? is exactly one.
+func wildcardMatch(pattern, value string) bool {
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case star >= 0:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// substitutePolicyVariables replaces ${key} with the key's single value from
+// the request context. Unknown variables are left as they are, so they never
+// match.
+func substitutePolicyVariables(s string, context map[string][]string) string {
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			return s
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return s
+		}
+		key := s[start+2 : start+end]
+		values := context[key]
+		if len(values) != 1 {
+			return s
+		}
+		s = s[:start] + values[0] + s[start+end+1:]
+	}
+}
+
+// conditionsMatch evaluates a Condition element. Every operator and every key
+// under it must be satisfied.
+func conditionsMatch(element interface{}, context map[string][]string) (bool, error) {
+	operators, ok := element.(map[string]interface{})
+	if !ok {
+		return false, fmt.Errorf("%s: Condition must be an object", errCodeMalformedPolicyDocument)
+	}
+	for operator, raw := range operators {
+		keys, ok := raw.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s: Condition %s must be an object", errCodeMalformedPolicyDocument, operator)
+		}
+		for key, expected := range keys {
+			matched, err := conditionMatches(operator, context[key], stringOrSlice(expected), context)
+			if err != nil {
+				return false, err
+			}
+			if !matched {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// conditionMatches evaluates one condition key. The ForAnyValue: and
+// ForAllValues: set qualifiers and the IfExists suffix are supported.
+func conditionMatches(operator string, actual, expected []string, context map[string][]string) (bool, error) {
+	if operator == "Null" {
+		wantNull := len(expected) > 0 && strings.EqualFold(expected[0], "true")
+		return (len(actual) == 0) == wantNull, nil
+	}
+
+	qualifier := ""
+	if i := strings.Index(operator, ":"); i >= 0 {
+		qualifier, operator = operator[:i], operator[i+1:]
+	}
+	ifExists := strings.HasSuffix(operator, "IfExists")
+	operator = strings.TrimSuffix(operator, "IfExists")
+
+	compare, negated, err := conditionOperator(operator)
+	if err != nil {
+		return false, err
+	}
+
+	// An absent key satisfies IfExists, ForAllValues (there is no value to
+	// fail) and a negated operator on its own (no value equals, is like or
+	// is in anything). It fails everything else, ForAnyValue included.
+	if len(actual) == 0 {
+		return ifExists || qualifier == "ForAllValues" || (negated && qualifier == ""), nil
+	}
+	for i := range expected {
+		expected[i] = substitutePolicyVariables(expected[i], context)
+	}
+	matchesAny := func(value string) bool {
+		for _, e := range expected {
+			if compare(value, e) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch qualifier {
+	case "ForAllValues":
+		for _, value := range actual {
+			if matchesAny(value) == negated {
+				return false, nil
+			}
+		}
+		return true, nil
+	default:
+		// Single-valued keys and ForAnyValue: at least one value matches, or
+		// for negated operators, no value matches.
+		for _, value := range actual {
+			if matchesAny(value) {
+				return !negated, nil
+			}
+		}
+		return negated, nil
+	}
+}
+
+// conditionOperator returns the comparison behind a condition operator and
+// whether the operator is a negation (StringNotEquals and friends).
+func conditionOperator(operator string) (func(actual, expected string) bool, bool, error) {
+	switch operator {
+	case "StringEquals", "ArnEquals":
+		return func(a, e string) bool { return a == e }, false, nil
+	case "StringNotEquals", "ArnNotEquals":
+		return func(a, e string) bool { return a == e }, true, nil
+	case "StringEqualsIgnoreCase":
+		return strings.EqualFold, false, nil
+	case "StringNotEqualsIgnoreCase":
+		return strings.EqualFold, true, nil
+	case "StringLike", "ArnLike":
+		return func(a, e string) bool { return wildcardMatch(e, a) }, false, nil
+	case "StringNotLike", "ArnNotLike":
+		return func(a, e string) bool { return wildcardMatch(e, a) }, true, nil
+	case "Bool":
+		return strings.EqualFold, false, nil
+	case "NumericEquals", "NumericNotEquals", "NumericLessThan", "NumericLessThanEquals",
+		"NumericGreaterThan", "NumericGreaterThanEquals":
+		return numericOperator(operator), operator == "NumericNotEquals", nil
+	case "IpAddress":
+		return ipInRange, false, nil
+	case "NotIpAddress":
+		return ipInRange, true, nil
+	}
+	return nil, false, fmt.Errorf("unsupported condition operator %s", operator)
+}
+
+func numericOperator(operator string) func(actual, expected string) bool {
+	return func(a, e string) bool {
+		av, errA := strconv.ParseFloat(a, 64)
+		ev, errE := strconv.ParseFloat(e, 64)
+		if errA != nil || errE != nil {
+			return false
+		}
+		switch operator {
+		case "NumericLessThan":
+			return av < ev
+		case "NumericLessThanEquals":
+			return av <= ev
+		case "NumericGreaterThan":
+			return av > ev
+		case "NumericGreaterThanEquals":
+			return av >= ev
+		}
+		return av == ev
+	}
+}
+
+// ipInRange matches an address against a CIDR block or a single address.
+func ipInRange(actual, expected string) bool {
+	ip := net.ParseIP(actual)
+	if ip == nil {
+		return false
+	}
+	if _, network, err := net.ParseCIDR(expected); err == nil {
+		return network.Contains(ip)
+	}
+	return ip.Equal(net.ParseIP(expected))
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const bucketPolicy = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "AccountRead",
+      "Effect": "Allow",
+      "Principal": {"AWS": "arn:aws:iam::111122223333:root"},
+      "Action": ["s3:Get*", "s3:ListBucket"],
+      "Resource": ["arn:aws:s3:::reports", "arn:aws:s3:::reports/*"]
+    },
+    {
+      "Sid": "OwnPrefix",
+      "Effect": "Allow",
+      "Principal": "*",
+      "Action": "s3:PutObject",
+      "Resource": "arn:aws:s3:::reports/home/${aws:username}/*"
+    },
+    {
+      "Sid": "DenyInsecure",
+      "Effect": "Deny",
+      "Principal": "*",
+      "Action": "s3:*",
+      "Resource": "arn:aws:s3:::reports/*",
+      "Condition": {"Bool": {"aws:SecureTransport": "false"}}
+    },
+    {
+      "Sid": "OfficeOnly",
+      "Effect": "Deny",
+      "Principal": {"AWS": "*"},
+      "Action": "s3:DeleteObject",
+      "Resource": "arn:aws:s3:::reports/*",
+      "Condition": {"NotIpAddress": {"aws:SourceIp": ["203.0.113.0/24"]}}
+    }
+  ]
+}`
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"*", "", true},
+		{"s3:Get*", "s3:GetObject", true},
+		{"s3:Get*", "s3:PutObject", false},
+		{"arn:aws:s3:::b/*", "arn:aws:s3:::b/a/b/c", true},
+		{"arn:aws:s3:::b/?.txt", "arn:aws:s3:::b/a.txt", true},
+		{"arn:aws:s3:::b/?.txt", "arn:aws:s3:::b/ab.txt", false},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+	}
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	secure := map[string][]string{"aws:SecureTransport": {"true"}, "aws:SourceIp": {"203.0.113.10"}}
+	tests := []struct {
+		name     string
+		req      policyRequest
+		decision string
+	}{
+		{"account root grants its users", policyRequest{
+			Principal: "arn:aws:iam::111122223333:user/alice", Action: "s3:GetObject",
+			Resource: "arn:aws:s3:::reports/q1.csv", Context: secure,
+		}, decisionAllowed},
+		{"action match is case-insensitive", policyRequest{
+			Principal: "111122223333", Action: "S3:LISTBUCKET",
+			Resource: "arn:aws:s3:::reports", Context: secure,
+		}, decisionAllowed},
+		{"other account is implicitly denied", policyRequest{
+			Principal: "arn:aws:iam::999999999999:user/mallory", Action: "s3:GetObject",
+			Resource: "arn:aws:s3:::reports/q1.csv", Context: secure,
+		}, decisionImplicitDeny},
+		{"condition deny wins over allow", policyRequest{
+			Principal: "arn:aws:iam::111122223333:user/alice", Action: "s3:GetObject",
+			Resource: "arn:aws:s3:::reports/q1.csv", Context: map[string][]string{"aws:SecureTransport": {"false"}},
+		}, decisionExplicitDeny},
+		{"policy variable from context", policyRequest{
+			Principal: "arn:aws:iam::999999999999:user/bob", Action: "s3:PutObject",
+			Resource: "arn:aws:s3:::reports/home/bob/notes.txt",
+			Context:  map[string][]string{"aws:username": {"bob"}, "aws:SecureTransport": {"true"}},
+		}, decisionAllowed},
+		{"policy variable does not cross users", policyRequest{
+			Principal: "arn:aws:iam::999999999999:user/bob", Action: "s3:PutObject",
+			Resource: "arn:aws:s3:::reports/home/alice/notes.txt",
+			Context:  map[string][]string{"aws:username": {"bob"}, "aws:SecureTransport": {"true"}},
+		}, decisionImplicitDeny},
+		{"NotIpAddress outside office", policyRequest{
+			Principal: "arn:aws:iam::111122223333:user/alice", Action: "s3:DeleteObject",
+			Resource: "arn:aws:s3:::reports/q1.csv",
+			Context:  map[string][]string{"aws:SecureTransport": {"true"}, "aws:SourceIp": {"198.51.100.7"}},
+		}, decisionExplicitDeny},
+	}
+	for _, tt := range tests {
+		got, err := evaluatePolicy(bucketPolicy, tt.req)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got.Decision != tt.decision {
+			t.Errorf("%s: decision = %s (matched %v), want %s", tt.name, got.Decision, got.MatchedStatements, tt.decision)
+		}
+	}
+}
+
+func TestConditionSetQualifiers(t *testing.T) {
+	tags := []string{"team", "env"}
+	if ok, _ := conditionMatches("ForAllValues:StringEquals", tags, []string{"team", "env", "owner"}, nil); !ok {
+		t.Error("ForAllValues should match when every value is allowed")
+	}
+	if ok, _ := conditionMatches("ForAllValues:StringEquals", tags, []string{"team"}, nil); ok {
+		t.Error("ForAllValues should fail when a value is not allowed")
+	}
+	if ok, _ := conditionMatches("ForAnyValue:StringLike", tags, []string{"en*"}, nil); !ok {
+		t.Error("ForAnyValue should match when one value matches")
+	}
+	if ok, _ := conditionMatches("StringEqualsIfExists", nil, []string{"x"}, nil); !ok {
+		t.Error("IfExists should match a missing key")
+	}
+	if ok, _ := conditionMatches("Null", nil, []string{"true"}, nil); !ok {
+		t.Error("Null true should match a missing key")
+	}
+	if _, err := conditionMatches("DateBefore", []string{"x"}, []string{"y"}, nil); err == nil {
+		t.Error("expected an error for an unsupported operator")
+	}
+}
+
+func TestConditionAbsentKey(t *testing.T) {
+	tests := []struct {
+		operator string
+		want     bool
+	}{
+		{"StringEquals", false},
+		{"StringLike", false},
+		{"ArnEquals", false},
+		{"IpAddress", false},
+		{"NumericEquals", false},
+		{"StringNotEquals", true},
+		{"StringNotEqualsIgnoreCase", true},
+		{"StringNotLike", true},
+		{"ArnNotEquals", true},
+		{"ArnNotLike", true},
+		{"NotIpAddress", true},
+		{"NumericNotEquals", true},
+		{"StringEqualsIfExists", true},
+		{"StringNotEqualsIfExists", true},
+		{"ForAllValues:StringEquals", true},
+		{"ForAllValues:StringNotEquals", true},
+		{"ForAnyValue:StringEquals", false},
+		{"ForAnyValue:StringNotEquals", false},
+	}
+	for _, tt := range tests {
+		got, err := conditionMatches(tt.operator, nil, []string{"10"}, nil)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.operator, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s with the key absent = %v, want %v", tt.operator, got, tt.want)
+		}
+	}
+}
+
+func TestPolicySimulationReadsBucketPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/resource/aws_s3_bucket_policy/reports" {
+			w.WriteHeader(404)
+			return
+		}
+		json.NewEncoder(w).Encode(ResourceResponse{ID: "reports", Attributes: map[string]interface{}{
+			"bucket": "reports", "policy": bucketPolicy,
+		}})
+	}))
+	defer server.Close()
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+
+	ds := Provider().DataSourcesMap["aws_mock_policy_simulation"]
+	d := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"bucket":    "reports",
+		"principal": "arn:aws:iam::111122223333:role/reader",
+		"action":    "s3:ListBucket",
+		"context": []interface{}{
+			map[string]interface{}{"key": "aws:SecureTransport", "values": []interface{}{"true"}},
+		},
+	})
+	if diags := ds.ReadContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if !d.Get("allowed").(bool) || d.Get("decision").(string) != decisionAllowed {
+		t.Errorf("expected allowed, got %s", d.Get("decision"))
+	}
+	if d.Get("resource").(string) != "arn:aws:s3:::reports" {
+		t.Errorf("expected resource to default to the bucket ARN, got %s", d.Get("resource"))
+	}
+	if got := d.Get("matched_statements").([]interface{}); len(got) != 1 || got[0] != "AccountRead" {
+		t.Errorf("expected AccountRead to match, got %v", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"bucket": "missing", "principal": "*", "action": "s3:GetObject",
+	})
+	if diags := ds.ReadContext(context.Background(), d, client); !diags.HasError() {
+		t.Error("expected an error for a bucket with no policy")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// arn is a parsed Amazon Resource Name.
+type arn struct {
+	Partition string
+	Service   string
+	Region    string
+	AccountID string
+	Resource  string
+}
+
+// parseARN splits an ARN into its parts. The resource part may itself
+// contain colons.
+func parseARN(s string) (arn, error) {
+	parts := strings.SplitN(s, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return arn{}, fmt.Errorf("%q is not a valid ARN: expected arn:partition:service:region:account-id:resource", s)
+	}
+	if parts[1] == "" || parts[2] == "" || parts[5] == "" {
+		return arn{}, fmt.Errorf("%q is not a valid ARN: partition, service and resource must not be empty", s)
+	}
+	return arn{Partition: parts[1], Service: parts[2], Region: parts[3], AccountID: parts[4], Resource: parts[5]}, nil
+}
+
+func (a arn) String() string {
+	return strings.Join([]string{"arn", a.Partition, a.Service, a.Region, a.AccountID, a.Resource}, ":")
+}
+
+// trimIamRolePath drops the path from an IAM role ARN, e.g.
+// arn:aws:iam::123456789012:role/service/app becomes
+// arn:aws:iam::123456789012:role/app.
+func trimIamRolePath(s string) (string, error) {
+	a, err := parseARN(s)
+	if err != nil {
+		return "", err
+	}
+	if a.Service != "iam" || !strings.HasPrefix(a.Resource, "role/") {
+		return "", fmt.Errorf("%q is not an IAM role ARN", s)
+	}
+	a.Resource = "role/" + a.Resource[strings.LastIndex(a.Resource, "/")+1:]
+	return a.String(), nil
+}
+
+var arnParseResultTypes = map[string]attr.Type{
+	"partition":  types.StringType,
+	"service":    types.StringType,
+	"region":     types.StringType,
+	"account_id": types.StringType,
+	"resource":   types.StringType,
+}
+
+// arnParseFunction implements provider::aws::arn_parse.
+type arnParseFunction struct{}
+
+func newArnParseFunction() function.Function { return arnParseFunction{} }
+
+func (f arnParseFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "arn_parse"
+}
+
+func (f arnParseFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "arn_parse Function",
+		MarkdownDescription: "Parses an ARN into its constituent parts",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "arn", MarkdownDescription: "ARN (Amazon Resource Name) to parse"},
+		},
+		Return: function.ObjectReturn{AttributeTypes: arnParseResultTypes},
+	}
+}
+
+func (f arnParseFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var s string
+	resp.Error = req.Arguments.Get(ctx, &s)
+	if resp.Error != nil {
+		return
+	}
+
+	a, err := parseARN(s)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	result, diags := types.ObjectValue(arnParseResultTypes, map[string]attr.Value{
+		"partition":  types.StringValue(a.Partition),
+		"service":    types.StringValue(a.Service),
+		"region":     types.StringValue(a.Region),
+		"account_id": types.StringValue(a.AccountID),
+		"resource":   types.StringValue(a.Resource),
+	})
+	resp.Error = function.FuncErrorFromDiags(ctx, diags)
+	if resp.Error != nil {
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, result)
+}
+
+// arnBuildFunction implements provider::aws::arn_build.
+type arnBuildFunction struct{}
+
+func newArnBuildFunction() function.Function { return arnBuildFunction{} }
+
+func (f arnBuildFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "arn_build"
+}
+
+func (f arnBuildFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "arn_build Function",
+		MarkdownDescription: "Builds an ARN from its constituent parts",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "partition", MarkdownDescription: "Partition in which the resource is located"},
+			function.StringParameter{Name: "service", MarkdownDescription: "Service namespace"},
+			function.StringParameter{Name: "region", MarkdownDescription: "Region code"},
+			function.StringParameter{Name: "account_id", MarkdownDescription: "AWS account identifier"},
+			function.StringParameter{Name: "resource", MarkdownDescription: "Resource section, typically composed of a resource type and identifier"},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f arnBuildFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var a arn
+	resp.Error = req.Arguments.Get(ctx, &a.Partition, &a.Service, &a.Region, &a.AccountID, &a.Resource)
+	if resp.Error != nil {
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, a.String())
+}
+
+// trimIamRolePathFunction implements provider::aws::trim_iam_role_path.
+type trimIamRolePathFunction struct{}
+
+func newTrimIamRolePathFunction() function.Function { return trimIamRolePathFunction{} }
+
+func (f trimIamRolePathFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "trim_iam_role_path"
+}
+
+func (f trimIamRolePathFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "trim_iam_role_path Function",
+		MarkdownDescription: "Trims the path prefix from an IAM role Amazon Resource Name (ARN)",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "arn", MarkdownDescription: "IAM role Amazon Resource Name (ARN)"},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f trimIamRolePathFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var s string
+	resp.Error = req.Arguments.Get(ctx, &s)
+	if resp.Error != nil {
+		return
+	}
+
+	trimmed, err := trimIamRolePath(s)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, trimmed)
+}
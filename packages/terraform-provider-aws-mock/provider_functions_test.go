@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// runFunction calls a provider function's Run with string arguments.
+func runFunction(t *testing.T, f function.Function, result function.ResultData, args ...string) (function.ResultData, *function.FuncError) {
+	t.Helper()
+	values := make([]attr.Value, len(args))
+	for i, arg := range args {
+		values[i] = types.StringValue(arg)
+	}
+	resp := &function.RunResponse{Result: result}
+	f.Run(context.Background(), function.RunRequest{Arguments: function.NewArgumentsData(values)}, resp)
+	return resp.Result, resp.Error
+}
+
+func TestArnParse(t *testing.T) {
+	result, ferr := runFunction(t, newArnParseFunction(),
+		function.NewResultData(types.ObjectNull(arnParseResultTypes)),
+		"arn:aws:iam::444455556666:role/example")
+	if ferr != nil {
+		t.Fatalf("unexpected error: %v", ferr)
+	}
+
+	got := result.Value().(types.Object).Attributes()
+	want := map[string]string{
+		"partition":  "aws",
+		"service":    "iam",
+		"region":     "",
+		"account_id": "444455556666",
+		"resource":   "role/example",
+	}
+	for key, value := range want {
+		if s := got[key].(types.String).ValueString(); s != value {
+			t.Errorf("%s = %q, want %q", key, s, value)
+		}
+	}
+}
+
+func TestArnParseKeepsColonsInResource(t *testing.T) {
+	a, err := parseARN("arn:aws:logs:us-east-1:123456789012:log-group:/app:*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Resource != "log-group:/app:*" {
+		t.Errorf("resource = %q", a.Resource)
+	}
+	if a.String() != "arn:aws:logs:us-east-1:123456789012:log-group:/app:*" {
+		t.Errorf("round trip = %q", a.String())
+	}
+}
+
+func TestArnParseRejectsInvalid(t *testing.T) {
+	for _, s := range []string{"", "not-an-arn", "arn:aws:s3", "urn:aws:s3:::bucket:x", "arn::s3:::bucket"} {
+		_, ferr := runFunction(t, newArnParseFunction(),
+			function.NewResultData(types.ObjectNull(arnParseResultTypes)), s)
+		if ferr == nil {
+			t.Errorf("arn_parse(%q): expected an error", s)
+			continue
+		}
+		if ferr.FunctionArgument == nil || *ferr.FunctionArgument != 0 {
+			t.Errorf("arn_parse(%q): error should point at the arn argument, got %v", s, ferr.FunctionArgument)
+		}
+	}
+}
+
+func TestArnBuild(t *testing.T) {
+	result, ferr := runFunction(t, newArnBuildFunction(),
+		function.NewResultData(types.StringUnknown()),
+		"aws", "s3", "", "", "my-bucket/key")
+	if ferr != nil {
+		t.Fatalf("unexpected error: %v", ferr)
+	}
+	if got := result.Value().(types.String).ValueString(); got != "arn:aws:s3:::my-bucket/key" {
+		t.Errorf("arn_build = %q", got)
+	}
+}
+
+func TestTrimIamRolePath(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"arn:aws:iam::444455556666:role/with/path/example", "arn:aws:iam::444455556666:role/example", true},
+		{"arn:aws:iam::444455556666:role/example", "arn:aws:iam::444455556666:role/example", true},
+		{"arn:aws-us-gov:iam::444455556666:role/a/b", "arn:aws-us-gov:iam::444455556666:role/b", true},
+		{"arn:aws:iam::444455556666:user/path/example", "", false},
+		{"arn:aws:s3:::role/example", "", false},
+		{"role/example", "", false},
+	}
+	for _, tt := range tests {
+		result, ferr := runFunction(t, newTrimIamRolePathFunction(),
+			function.NewResultData(types.StringUnknown()), tt.in)
+		if (ferr == nil) != tt.ok {
+			t.Errorf("trim_iam_role_path(%q) error = %v, want ok=%v", tt.in, ferr, tt.ok)
+			continue
+		}
+		if tt.ok {
+			if got := result.Value().(types.String).ValueString(); got != tt.want {
+				t.Errorf("trim_iam_role_path(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestMuxServerServesFunctions(t *testing.T) {
+	ctx := context.Background()
+	server, err := newMuxServer(ctx)
+	if err != nil {
+		t.Fatalf("creating mux server: %v", err)
+	}
+
+	// The mux rejects servers whose provider schemas differ, so any
+	// divergence from the SDKv2 schema shows up as a diagnostic here.
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema: %v", err)
+	}
+	for _, d := range schemaResp.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", d.Summary, d.Detail)
+	}
+	for _, name := range []string{"arn_build", "arn_parse", "trim_iam_role_path"} {
+		if _, ok := schemaResp.Functions[name]; !ok {
+			t.Errorf("function %s not served", name)
+		}
+	}
+	if _, ok := schemaResp.ResourceSchemas["aws_s3_bucket"]; !ok {
+		t.Error("SDKv2 resources should still be served")
+	}
+
+	arg, err := tfprotov5.NewDynamicValue(tftypes.String, tftypes.NewValue(tftypes.String, "arn:aws:iam::444455556666:role/a/b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	callResp, err := server.CallFunction(ctx, &tfprotov5.CallFunctionRequest{
+		Name:      "trim_iam_role_path",
+		Arguments: []*tfprotov5.DynamicValue{&arg},
+	})
+	if err != nil {
+		t.Fatalf("CallFunction: %v", err)
+	}
+	if callResp.Error != nil {
+		t.Fatalf("unexpected function error: %s", callResp.Error.Text)
+	}
+	value, err := callResp.Result.Unmarshal(tftypes.String)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	value.As(&got)
+	if got != "arn:aws:iam::444455556666:role/b" {
+		t.Errorf("trim_iam_role_path over the mux = %q", got)
+	}
+}
@@ -1,7 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestProviderDeclaresS3Bucket(t *testing.T) {
@@ -410,3 +419,320 @@ func TestEC2ResourcesHaveCRUD(t *testing.T) {
 		}
 	}
 }
+
+// --- Optimistic concurrency wiring ---
+
+// --- DependencyViolation on delete ---
+
+func dependencyViolationServer(t *testing.T, failures int) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if failures < 0 || calls <= failures {
+			w.WriteHeader(400)
+			json.NewEncoder(w).Encode(map[string]string{
+				"code":  "DependencyViolation",
+				"error": "resource has dependencies and cannot be deleted",
+			})
+			return
+		}
+		w.WriteHeader(204)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestDeleteRetriesDependencyViolation(t *testing.T) {
+	for _, name := range []string{"aws_vpc", "aws_security_group"} {
+		t.Run(name, func(t *testing.T) {
+			server, calls := dependencyViolationServer(t, 1)
+
+			res := Provider().ResourcesMap[name]
+			if res.Timeouts == nil || res.Timeouts.Delete == nil {
+				t.Fatalf("%s should declare a delete timeout", name)
+			}
+
+			d := res.TestResourceData()
+			d.SetId("id-abc123")
+
+			client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+			diags := res.DeleteContext(context.Background(), d, client)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if *calls != 2 {
+				t.Errorf("expected a retry after DependencyViolation, got %d calls", *calls)
+			}
+			if d.Id() != "" {
+				t.Error("expected id cleared after delete")
+			}
+		})
+	}
+}
+
+func TestDeleteDependencyViolationGivesUp(t *testing.T) {
+	server, _ := dependencyViolationServer(t, -1)
+
+	res := Provider().ResourcesMap["aws_vpc"]
+	d := res.TestResourceData()
+	d.SetId("vpc-abc123")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	diags := res.DeleteContext(ctx, d, client)
+	if !diags.HasError() {
+		t.Fatal("expected DependencyViolation error once retries run out")
+	}
+	if !strings.Contains(diags[0].Summary, "DependencyViolation") {
+		t.Errorf("summary should name DependencyViolation, got %q", diags[0].Summary)
+	}
+	if d.Id() == "" {
+		t.Error("id should be kept when delete fails")
+	}
+}
+
+// --- aws_s3_object and bucket contents ---
+
+func TestS3ObjectSchemaRequired(t *testing.T) {
+	s := resourceS3ObjectSchema()
+	for _, name := range []string{"bucket", "key"} {
+		attr, ok := s[name]
+		if !ok {
+			t.Errorf("missing attribute: %s", name)
+			continue
+		}
+		if !attr.Required {
+			t.Errorf("%s should be Required", name)
+		}
+	}
+	for _, name := range []string{"arn", "version_id"} {
+		if attr := s[name]; attr == nil || !attr.Computed || attr.Optional {
+			t.Errorf("%s should be Computed-only", name)
+		}
+	}
+
+	if _, ok := Provider().ResourcesMap["aws_s3_object"]; !ok {
+		t.Error("Provider does not declare aws_s3_object resource")
+	}
+}
+
+func bucketWithObjectsServer(t *testing.T, deleted *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/resource/aws_s3_object":
+			if r.URL.Query().Get("bucket") != "my-bucket" {
+				t.Errorf("expected bucket=my-bucket filter, got %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"resources": []ResourceResponse{
+					{ID: "a.txt", Attributes: map[string]interface{}{"key": "a.txt"}},
+					{ID: "b.txt", Attributes: map[string]interface{}{"key": "b.txt"}},
+				},
+			})
+		case r.Method == "DELETE":
+			*deleted = append(*deleted, r.URL.Path)
+			w.WriteHeader(204)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestS3BucketDeleteFailsWhenNotEmpty(t *testing.T) {
+	var deleted []string
+	server := bucketWithObjectsServer(t, &deleted)
+
+	res := Provider().ResourcesMap["aws_s3_bucket"]
+	d := res.TestResourceData()
+	d.SetId("my-bucket")
+	d.Set("bucket", "my-bucket")
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	diags := res.DeleteContext(context.Background(), d, client)
+	if !diags.HasError() {
+		t.Fatal("expected BucketNotEmpty error")
+	}
+	if !strings.Contains(diags[0].Summary, "BucketNotEmpty") {
+		t.Errorf("summary should name BucketNotEmpty, got %q", diags[0].Summary)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("nothing should be deleted without force_destroy, got %v", deleted)
+	}
+}
+
+func TestS3BucketForceDestroyPurgesObjects(t *testing.T) {
+	var deleted []string
+	server := bucketWithObjectsServer(t, &deleted)
+
+	res := Provider().ResourcesMap["aws_s3_bucket"]
+	d := res.TestResourceData()
+	d.SetId("my-bucket")
+	d.Set("bucket", "my-bucket")
+	d.Set("force_destroy", true)
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	if diags := res.DeleteContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	expected := []string{
+		"/resource/aws_s3_object/a.txt",
+		"/resource/aws_s3_object/b.txt",
+		"/resource/aws_s3_bucket/my-bucket",
+	}
+	if strings.Join(deleted, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected objects purged before the bucket, got %v", deleted)
+	}
+}
+
+func TestS3BucketDeleteHonoursBackendBucketNotEmpty(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The bucket looks empty, but the backend knows better.
+		if r.Method == "GET" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"resources": []interface{}{}})
+			return
+		}
+		w.WriteHeader(409)
+		json.NewEncoder(w).Encode(map[string]string{"code": "BucketNotEmpty", "error": "bucket is not empty"})
+	}))
+	defer server.Close()
+
+	res := Provider().ResourcesMap["aws_s3_bucket"]
+	d := res.TestResourceData()
+	d.SetId("my-bucket")
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	diags := res.DeleteContext(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "BucketNotEmpty") {
+		t.Errorf("expected BucketNotEmpty diagnostic, got %v", diags)
+	}
+}
+
+func TestS3BucketDeleteFailsWithoutListing(t *testing.T) {
+	deletes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deletes++
+			w.WriteHeader(204)
+			return
+		}
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unknown resource type: aws_s3_object"})
+	}))
+	defer server.Close()
+
+	res := Provider().ResourcesMap["aws_s3_bucket"]
+	d := res.TestResourceData()
+	d.SetId("my-bucket")
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	diags := res.DeleteContext(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "status 404") {
+		t.Errorf("a backend that can't list objects should fail the delete, got %v", diags)
+	}
+	if deletes != 0 {
+		t.Error("the bucket should not be deleted when its objects can't be listed")
+	}
+}
+
+// --- Instance protection ---
+
+func TestInstanceDeleteBlockedByTerminationProtection(t *testing.T) {
+	deletes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deletes++
+		w.WriteHeader(204)
+	}))
+	defer server.Close()
+
+	res := Provider().ResourcesMap["aws_instance"]
+	d := res.TestResourceData()
+	d.SetId("i-abc123")
+	d.Set("disable_api_termination", true)
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	diags := res.DeleteContext(context.Background(), d, client)
+	if !diags.HasError() {
+		t.Fatal("expected OperationNotPermitted while termination protection is on")
+	}
+	if !strings.Contains(diags[0].Summary, "OperationNotPermitted") {
+		t.Errorf("summary should name OperationNotPermitted, got %q", diags[0].Summary)
+	}
+	if deletes != 0 {
+		t.Errorf("backend delete should not be called, got %d calls", deletes)
+	}
+
+	d.Set("disable_api_termination", false)
+	if diags := res.DeleteContext(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error once protection is off: %v", diags)
+	}
+	if deletes != 1 {
+		t.Errorf("expected backend delete once protection is off, got %d calls", deletes)
+	}
+}
+
+func TestInstanceStateStopBlockedByStopProtection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			json.NewEncoder(w).Encode(ResourceResponse{
+				ID:         "i-abc123",
+				Attributes: map[string]interface{}{"disable_api_stop": true},
+			})
+			return
+		}
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	schemaMap := map[string]*schema.Schema{
+		"instance_id": {Type: schema.TypeString, Required: true},
+		"state":       {Type: schema.TypeString, Required: true},
+	}
+	res := withInstanceStopProtection(buildDynamicResource("aws_ec2_instance_state", schemaMap))
+	d := res.TestResourceData()
+	d.Set("instance_id", "i-abc123")
+	d.Set("state", "stopped")
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	diags := res.CreateContext(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "disable_api_stop") {
+		t.Errorf("expected stop protection diagnostic, got %v", diags)
+	}
+}
+
+func TestInstanceStateStoppedAllowsOtherUpdates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Attributes map[string]interface{} `json:"attributes"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(ResourceResponse{ID: "i-abc123", Attributes: body.Attributes})
+	}))
+	defer server.Close()
+
+	schemaMap := map[string]*schema.Schema{
+		"instance_id": {Type: schema.TypeString, Required: true},
+		"state":       {Type: schema.TypeString, Required: true},
+		"tags":        {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+	}
+	res := withInstanceStopProtection(buildDynamicResource("aws_ec2_instance_state", schemaMap))
+	d := res.Data(&terraform.InstanceState{
+		ID:         "i-abc123",
+		Attributes: map[string]string{"id": "i-abc123", "instance_id": "i-abc123", "state": "stopped"},
+	})
+	d.Set("tags", map[string]interface{}{"Name": "web"})
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	if diags := res.UpdateContext(context.Background(), d, client); diags.HasError() {
+		t.Errorf("tags-only update of a stopped instance failed: %v", diags)
+	}
+}
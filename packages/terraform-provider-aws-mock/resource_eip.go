@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceEip() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceEipCreate,
+		ReadContext:   resourceEipRead,
+		UpdateContext: resourceEipUpdate,
+		DeleteContext: resourceEipDelete,
+		Schema:        resourceEipSchema(),
+	}
+}
+
+// eipAssociationAttributes checks the instance an Elastic IP is associated
+// with and copies its private address.
+func eipAssociationAttributes(d *schema.ResourceData, client *MockClient) (map[string]interface{}, diag.Diagnostics) {
+	network := map[string]interface{}{
+		"private_ip":  "",
+		"private_dns": "",
+	}
+
+	instanceID := d.Get("instance").(string)
+	if instanceID == "" {
+		return network, nil
+	}
+	instance, diags := readReference(client, "aws_instance", instanceID, "instance")
+	if diags.HasError() {
+		return nil, diags
+	}
+	network["private_ip"] = stringAttribute(instance, "private_ip")
+	network["private_dns"] = stringAttribute(instance, "private_dns")
+	return network, nil
+}
+
+func resourceEipCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceEipSchema())
+
+	network, diags := eipAssociationAttributes(d, client)
+	if diags.HasError() {
+		return diags
+	}
+	publicIP := d.Get("address").(string)
+	if publicIP == "" {
+		publicIP = randomPublicIP()
+	}
+	network["public_ip"] = publicIP
+	network["public_dns"] = publicDNSName(publicIP, client.Region)
+	if _, ok := d.GetOk("domain"); !ok {
+		network["domain"] = "vpc"
+	}
+	for key, value := range network {
+		attrs[key] = value
+	}
+
+	result, err := client.CreateResource("aws_eip", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+
+	if !networkAttributesApplied(result.Attributes, network) {
+		result, err = client.UpdateResourceIfMatch("aws_eip", result.ID, result.Version, attrs)
+		if err != nil {
+			return diagFromClientError(err)
+		}
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceEipSchema())
+}
+
+func resourceEipRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_eip", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceEipSchema())
+}
+
+func resourceEipUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceEipSchema())
+
+	if d.HasChange("instance") {
+		network, diags := eipAssociationAttributes(d, client)
+		if diags.HasError() {
+			return diags
+		}
+		for key, value := range network {
+			attrs[key] = value
+		}
+	}
+
+	result, err := client.UpdateResourceIfMatch("aws_eip", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceEipSchema())
+}
+
+func resourceEipDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if err := client.DeleteResourceIfMatch("aws_eip", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
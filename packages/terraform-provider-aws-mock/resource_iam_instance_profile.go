@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceIamInstanceProfile() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceIamInstanceProfileCreate,
+		ReadContext:   resourceIamInstanceProfileRead,
+		UpdateContext: resourceIamInstanceProfileUpdate,
+		DeleteContext: resourceIamInstanceProfileDelete,
+		Schema:        resourceIamInstanceProfileSchema(),
+	}
+}
+
+func resourceIamInstanceProfileCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if role := d.Get("role").(string); role != "" {
+		if _, diags := readIamRole(client, role, "role"); diags.HasError() {
+			return diags
+		}
+	}
+
+	attrs := extractAttributes(d, resourceIamInstanceProfileSchema())
+	attrs["name"] = iamName(d)
+
+	result, err := client.CreateResource("aws_iam_instance_profile", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamInstanceProfileSchema())
+}
+
+func resourceIamInstanceProfileRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_iam_instance_profile", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamInstanceProfileSchema())
+}
+
+func resourceIamInstanceProfileUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if d.HasChange("role") {
+		if role := d.Get("role").(string); role != "" {
+			if _, diags := readIamRole(client, role, "role"); diags.HasError() {
+				return diags
+			}
+		}
+	}
+
+	attrs := extractAttributes(d, resourceIamInstanceProfileSchema())
+
+	result, err := client.UpdateResourceIfMatch("aws_iam_instance_profile", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamInstanceProfileSchema())
+}
+
+func resourceIamInstanceProfileDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if err := client.DeleteResourceIfMatch("aws_iam_instance_profile", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
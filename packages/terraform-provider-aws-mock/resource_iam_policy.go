@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceIamPolicy() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceIamPolicyCreate,
+		ReadContext:   resourceIamPolicyRead,
+		UpdateContext: resourceIamPolicyUpdate,
+		DeleteContext: resourceIamPolicyDelete,
+		Schema:        resourceIamPolicySchema(),
+	}
+}
+
+func resourceIamPolicyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceIamPolicySchema())
+	attrs["name"] = iamName(d)
+
+	result, err := client.CreateResource("aws_iam_policy", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamPolicySchema())
+}
+
+func resourceIamPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_iam_policy", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	if diags := setAttributes(d, result.Attributes, resourceIamPolicySchema()); diags.HasError() {
+		return diags
+	}
+
+	attachments, err := policyAttachments(client, d.Get("arn").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("attachment_count", len(attachments))
+	return nil
+}
+
+func resourceIamPolicyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceIamPolicySchema())
+
+	result, err := client.UpdateResourceIfMatch("aws_iam_policy", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamPolicySchema())
+}
+
+func resourceIamPolicyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attachments, err := policyAttachments(client, d.Get("arn").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(attachments) > 0 {
+		return deleteConflictDiag("aws_iam_policy", d.Id(), "Cannot delete a policy attached to entities.")
+	}
+
+	err = client.DeleteResourceIfMatch("aws_iam_policy", d.Id(), resourceVersion(ctx))
+	if hasErrorCode(err, errCodeDeleteConflict) {
+		return deleteConflictDiag("aws_iam_policy", d.Id(), err.Error())
+	}
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// policyAttachments lists the role attachments of the policy with arn.
+func policyAttachments(client *MockClient, arn string) ([]ResourceResponse, error) {
+	if arn == "" {
+		return nil, nil
+	}
+	return client.ListResources("aws_iam_role_policy_attachment", map[string]string{"policy_arn": arn})
+}
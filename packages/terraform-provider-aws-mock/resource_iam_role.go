@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceIamRole() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceIamRoleCreate,
+		ReadContext:   resourceIamRoleRead,
+		UpdateContext: resourceIamRoleUpdate,
+		DeleteContext: resourceIamRoleDelete,
+		Schema:        resourceIamRoleSchema(),
+	}
+}
+
+func resourceIamRoleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceIamRoleSchema())
+	attrs["name"] = iamName(d)
+
+	if boundary := d.Get("permissions_boundary").(string); boundary != "" {
+		if diags := readIamPolicy(client, boundary); diags.HasError() {
+			return diags
+		}
+	}
+
+	result, err := client.CreateResource("aws_iam_role", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamRoleSchema())
+}
+
+func resourceIamRoleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_iam_role", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamRoleSchema())
+}
+
+func resourceIamRoleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceIamRoleSchema())
+
+	if d.HasChange("permissions_boundary") {
+		if boundary := d.Get("permissions_boundary").(string); boundary != "" {
+			if diags := readIamPolicy(client, boundary); diags.HasError() {
+				return diags
+			}
+		}
+	}
+
+	result, err := client.UpdateResourceIfMatch("aws_iam_role", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamRoleSchema())
+}
+
+// resourceIamRoleDelete refuses to delete a role that is still in an
+// instance profile or has managed policies attached, unless
+// force_detach_policies asks for the attachments to be removed first.
+func resourceIamRoleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	profiles, err := client.ListResources("aws_iam_instance_profile", map[string]string{"role": d.Id()})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(profiles) > 0 {
+		return deleteConflictDiag("aws_iam_role", d.Id(),
+			"Cannot delete entity, must remove roles from instance profile first: "+profiles[0].ID)
+	}
+
+	attachments, err := client.ListResources("aws_iam_role_policy_attachment", map[string]string{"role": d.Id()})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(attachments) > 0 {
+		if !d.Get("force_detach_policies").(bool) {
+			return deleteConflictDiag("aws_iam_role", d.Id(),
+				"Cannot delete entity, must detach all policies first. Set force_detach_policies = true to have Terraform detach them.")
+		}
+		for _, attachment := range attachments {
+			if err := client.DeleteResource("aws_iam_role_policy_attachment", attachment.ID); err != nil {
+				return diag.Errorf("deleting aws_iam_role (%s): detaching %s: %s", d.Id(), stringAttribute(&attachment, "policy_arn"), err)
+			}
+		}
+	}
+
+	err = client.DeleteResourceIfMatch("aws_iam_role", d.Id(), resourceVersion(ctx))
+	if hasErrorCode(err, errCodeDeleteConflict) {
+		return deleteConflictDiag("aws_iam_role", d.Id(), err.Error())
+	}
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
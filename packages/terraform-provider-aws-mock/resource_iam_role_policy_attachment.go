@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceIamRolePolicyAttachment attaches a managed policy to a role. As in
+// IAM, an attachment can't be changed in place: a new role or policy_arn
+// replaces it.
+func resourceIamRolePolicyAttachment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceIamRolePolicyAttachmentCreate,
+		ReadContext:   resourceIamRolePolicyAttachmentRead,
+		DeleteContext: resourceIamRolePolicyAttachmentDelete,
+		Schema:        resourceIamRolePolicyAttachmentSchema(),
+	}
+}
+
+// validateRolePolicyAttachment checks that both the role and the policy
+// being attached exist.
+func validateRolePolicyAttachment(d *schema.ResourceData, client *MockClient) diag.Diagnostics {
+	if _, diags := readIamRole(client, d.Get("role").(string), "role"); diags.HasError() {
+		return diags
+	}
+	return readIamPolicy(client, d.Get("policy_arn").(string))
+}
+
+func resourceIamRolePolicyAttachmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if diags := validateRolePolicyAttachment(d, client); diags.HasError() {
+		return diags
+	}
+
+	attrs := extractAttributes(d, resourceIamRolePolicyAttachmentSchema())
+
+	result, err := client.CreateResource("aws_iam_role_policy_attachment", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamRolePolicyAttachmentSchema())
+}
+
+func resourceIamRolePolicyAttachmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_iam_role_policy_attachment", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceIamRolePolicyAttachmentSchema())
+}
+
+func resourceIamRolePolicyAttachmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if err := client.DeleteResourceIfMatch("aws_iam_role_policy_attachment", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
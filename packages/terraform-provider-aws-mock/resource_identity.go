@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceIdentityKey names the one identity attribute every resource has:
+// the ID the backend stores it under.
+const resourceIdentityKey = "id"
+
+func resourceIdentitySchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		resourceIdentityKey: {
+			Type:              schema.TypeString,
+			RequiredForImport: true,
+			Description:       "ID of the resource in the mock backend",
+		},
+	}
+}
+
+// stateOnlyResources are kept only in Terraform state, with no backend
+// collection to import them from or list them in.
+var stateOnlyResources = map[string]bool{
+	"aws_kms_ciphertext": true,
+	"aws_mock_drift":     true,
+}
+
+// addResourceIdentities gives every resource kept in the backend an
+// identity and an importer accepting either an ID or an identity. terraform
+// query reports listed resources by identity and generates import blocks
+// from it, so resources without an identity get no list resource either.
+func addResourceIdentities(resources map[string]*schema.Resource) {
+	for resourceType, r := range resources {
+		if r.Identity != nil || stateOnlyResources[resourceType] {
+			continue
+		}
+		r.Identity = &schema.ResourceIdentity{SchemaFunc: resourceIdentitySchema}
+		r.CreateContext = withIdentity(r.CreateContext)
+		r.ReadContext = withIdentity(r.ReadContext)
+		r.UpdateContext = withIdentity(r.UpdateContext)
+		if r.Importer == nil {
+			r.Importer = &schema.ResourceImporter{
+				StateContext: schema.ImportStatePassthroughWithIdentity(resourceIdentityKey),
+			}
+		}
+	}
+}
+
+// withIdentity records the resource's ID as its identity after next
+// succeeds. SDKv2 rejects a create, read or update that leaves a resource
+// with an identity schema but no identity.
+func withIdentity(next func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if next == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		diags := next(ctx, d, meta)
+		if diags.HasError() || d.Id() == "" {
+			return diags
+		}
+		identity, err := d.Identity()
+		if err == nil {
+			err = identity.Set(resourceIdentityKey, d.Id())
+		}
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		return diags
+	}
+}
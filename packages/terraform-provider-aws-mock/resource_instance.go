@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -22,19 +23,43 @@ func resourceInstanceCreate(ctx context.Context, d *schema.ResourceData, meta in
 
 	attrs := extractAttributes(d, resourceInstanceSchema())
 
+	if diags := validateInstanceProfile(client, d.Get("iam_instance_profile").(string)); diags.HasError() {
+		return diags
+	}
+
+	network, diags := instanceNetworkAttributes(d, client)
+	if diags.HasError() {
+		return diags
+	}
+	for key, value := range network {
+		attrs[key] = value
+	}
+
 	result, err := client.CreateResource("aws_instance", attrs)
 	if err != nil {
+		releaseNetwork(client, d.Get("subnet_id").(string), network)
 		return diag.FromErr(err)
 	}
 
 	d.SetId(result.ID)
+
+	// Backends that synthesize their own network values on create get the
+	// subnet-derived ones written back so state and backend agree.
+	if !networkAttributesApplied(result.Attributes, network) {
+		result, err = client.UpdateResourceIfMatch("aws_instance", result.ID, result.Version, attrs)
+		if err != nil {
+			return diagFromClientError(err)
+		}
+	}
+
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceInstanceSchema())
 }
 
 func resourceInstanceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	result, err := client.ReadResource("aws_instance", d.Id())
+	result, err := client.ReadResourceBatched("aws_instance", d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -44,6 +69,7 @@ func resourceInstanceRead(ctx context.Context, d *schema.ResourceData, meta inte
 		return nil
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceInstanceSchema())
 }
 
@@ -52,21 +78,84 @@ func resourceInstanceUpdate(ctx context.Context, d *schema.ResourceData, meta in
 
 	attrs := extractAttributes(d, resourceInstanceSchema())
 
-	result, err := client.UpdateResource("aws_instance", d.Id(), attrs)
+	if d.HasChange("iam_instance_profile") {
+		if diags := validateInstanceProfile(client, d.Get("iam_instance_profile").(string)); diags.HasError() {
+			return diags
+		}
+	}
+
+	result, err := client.UpdateResourceIfMatch("aws_instance", d.Id(), resourceVersion(ctx), attrs)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromClientError(err)
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceInstanceSchema())
 }
 
 func resourceInstanceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	if err := client.DeleteResource("aws_instance", d.Id()); err != nil {
-		return diag.FromErr(err)
+	if d.Get("disable_api_termination").(bool) {
+		return operationNotPermittedDiag(d.Id(), "terminated", "disable_api_termination")
+	}
+
+	err := client.DeleteResourceIfMatch("aws_instance", d.Id(), resourceVersion(ctx))
+	if hasErrorCode(err, errCodeOperationNotPermitted) {
+		return operationNotPermittedDiag(d.Id(), "terminated", "disable_api_termination")
 	}
+	if err != nil {
+		return diagFromClientError(err)
+	}
+	client.ips.release(d.Get("subnet_id").(string), d.Get("private_ip").(string))
 
 	d.SetId("")
 	return nil
 }
+
+const errCodeOperationNotPermitted = "OperationNotPermitted"
+
+// operationNotPermittedDiag reports an instance operation blocked by one of
+// its protection attributes, e.g. termination while disable_api_termination
+// is on.
+func operationNotPermittedDiag(instanceID, operation, protection string) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("%s: The instance '%s' may not be %s", errCodeOperationNotPermitted, instanceID, operation),
+		Detail: fmt.Sprintf(
+			"%s is enabled on %s. Set %s = false and apply that change first, then try again.",
+			protection, instanceID, protection,
+		),
+	}}
+}
+
+// withInstanceStopProtection guards an instance-state resource (such as
+// aws_ec2_instance_state) so that stopping an instance with
+// disable_api_stop enabled fails the same way termination protection does.
+// Only the transition to stopped is guarded: other changes to an instance
+// already stopped go through.
+func withInstanceStopProtection(r *schema.Resource) *schema.Resource {
+	guard := func(next func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			if d.Get("state").(string) != "stopped" || (d.Id() != "" && !d.HasChange("state")) {
+				return next(ctx, d, meta)
+			}
+
+			instanceID := d.Get("instance_id").(string)
+			instance, err := meta.(*MockClient).ReadResource("aws_instance", instanceID)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if instance != nil {
+				if stop, _ := instance.Attributes["disable_api_stop"].(bool); stop {
+					return operationNotPermittedDiag(instanceID, "stopped", "disable_api_stop")
+				}
+			}
+			return next(ctx, d, meta)
+		}
+	}
+
+	r.CreateContext = guard(r.CreateContext)
+	r.UpdateContext = guard(r.UpdateContext)
+	return r
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceMainRouteTableAssociation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMainRouteTableAssociationCreate,
+		ReadContext:   resourceMainRouteTableAssociationRead,
+		UpdateContext: resourceMainRouteTableAssociationUpdate,
+		DeleteContext: resourceMainRouteTableAssociationDelete,
+		Schema:        resourceMainRouteTableAssociationSchema(),
+	}
+}
+
+// validateMainRouteTable checks that the route table belongs to the VPC it is
+// about to become the main route table of.
+func validateMainRouteTable(client *MockClient, vpcID, routeTableID string) diag.Diagnostics {
+	routeTable, diags := readReference(client, "aws_route_table", routeTableID, "route_table_id")
+	if diags.HasError() {
+		return diags
+	}
+	if rtVpcID := stringAttribute(routeTable, "vpc_id"); rtVpcID != vpcID {
+		return diag.Errorf("InvalidParameterValue: route table %s belongs to %s, not %s", routeTableID, rtVpcID, vpcID)
+	}
+	return nil
+}
+
+func resourceMainRouteTableAssociationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	vpcID := d.Get("vpc_id").(string)
+	routeTableID := d.Get("route_table_id").(string)
+	if diags := validateMainRouteTable(client, vpcID, routeTableID); diags.HasError() {
+		return diags
+	}
+
+	vpc, diags := readReference(client, "aws_vpc", vpcID, "vpc_id")
+	if diags.HasError() {
+		return diags
+	}
+	original, err := mainRouteTableID(vpc, client.ListResources)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	attrs := extractAttributes(d, resourceMainRouteTableAssociationSchema())
+	attrs["original_route_table_id"] = original
+
+	result, err := client.CreateResource("aws_main_route_table_association", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceMainRouteTableAssociationSchema())
+}
+
+func resourceMainRouteTableAssociationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_main_route_table_association", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	if diags := setAttributes(d, result.Attributes, resourceMainRouteTableAssociationSchema()); diags.HasError() {
+		return diags
+	}
+
+	// The association goes with its VPC.
+	vpc, err := client.ReadResourceBatched("aws_vpc", d.Get("vpc_id").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if vpc == nil {
+		d.SetId("")
+	}
+	return nil
+}
+
+func resourceMainRouteTableAssociationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	vpcID := d.Get("vpc_id").(string)
+	routeTableID := d.Get("route_table_id").(string)
+	if diags := validateMainRouteTable(client, vpcID, routeTableID); diags.HasError() {
+		return diags
+	}
+
+	attrs := extractAttributes(d, resourceMainRouteTableAssociationSchema())
+	attrs["original_route_table_id"] = d.Get("original_route_table_id")
+
+	result, err := client.UpdateResourceIfMatch("aws_main_route_table_association", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceMainRouteTableAssociationSchema())
+}
+
+// resourceMainRouteTableAssociationDelete hands the VPC back to its own
+// main route table, the one that was main before the association.
+func resourceMainRouteTableAssociationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if err := client.DeleteResourceIfMatch("aws_main_route_table_association", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
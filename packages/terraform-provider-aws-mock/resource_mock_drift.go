@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceMockDrift changes another resource in the backend as if someone
+// had edited it outside Terraform, so the next plan shows drift. The change
+// is either the given attribute values or, with a random block, values
+// picked from a seed, and lands at apply time or once delay has passed.
+// Nothing is kept in the backend for the drift itself; to drift again,
+// replace it.
+func resourceMockDrift() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMockDriftCreate,
+		ReadContext:   resourceMockDriftRead,
+		DeleteContext: resourceMockDriftDelete,
+		Schema:        resourceMockDriftSchema(),
+	}
+}
+
+func resourceMockDriftCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+	resourceType := d.Get("resource_type").(string)
+	resourceID := d.Get("resource_id").(string)
+
+	target, err := client.ReadResource(resourceType, resourceID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if target == nil {
+		return diag.Errorf("%s %s not found in the backend", resourceType, resourceID)
+	}
+
+	var attrs map[string]interface{}
+	if random := d.Get("random").([]interface{}); len(random) > 0 && random[0] != nil {
+		attrs, err = randomDrift(target.Attributes, random[0].(map[string]interface{}))
+	} else {
+		attrs, err = configuredDrift(target.Attributes, d.Get("attributes").(map[string]interface{}))
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	applyAt := time.Now().UTC()
+	var scheduled time.Time
+	if delay := d.Get("delay").(string); delay != "" {
+		duration, _ := time.ParseDuration(delay)
+		applyAt = applyAt.Add(duration)
+		scheduled = applyAt
+	}
+
+	if err := client.DriftResource(resourceType, resourceID, attrs, scheduled); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id.UniqueId())
+	d.Set("apply_at", applyAt.Format(time.RFC3339))
+	d.Set("drifted_attributes", driftedAttributeStrings(attrs))
+	return nil
+}
+
+func resourceMockDriftRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceMockDriftDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// configuredDrift converts the attributes argument to backend values. Values
+// for attributes the target holds as anything but a string are JSON, e.g.
+// "false" or jsonencode({ Owner = "ops" }).
+func configuredDrift(target, configured map[string]interface{}) (map[string]interface{}, error) {
+	attrs := make(map[string]interface{}, len(configured))
+	for name, raw := range configured {
+		value := raw.(string)
+		switch target[name].(type) {
+		case string, nil:
+			attrs[name] = value
+		default:
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+				return nil, fmt.Errorf("attributes.%s: the target holds a %T, so the value must be JSON: %w", name, target[name], err)
+			}
+			attrs[name] = decoded
+		}
+	}
+	return attrs, nil
+}
+
+// randomDrift changes count of the candidate attributes, chosen and changed
+// by a generator seeded from the configuration, so the same seed drifts
+// the same resource the same way every time.
+func randomDrift(target map[string]interface{}, config map[string]interface{}) (map[string]interface{}, error) {
+	seed := uint64(config["seed"].(int))
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	var candidates []string
+	for _, name := range config["attributes"].([]interface{}) {
+		if _, ok := target[name.(string)]; !ok {
+			return nil, fmt.Errorf("random.attributes: the target has no attribute %q", name)
+		}
+		candidates = append(candidates, name.(string))
+	}
+	if len(candidates) == 0 {
+		candidates = driftCandidates(target)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("the target has no attributes that can drift")
+	}
+
+	slices.Sort(candidates)
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	attrs := make(map[string]interface{})
+	for _, name := range candidates[:min(config["count"].(int), len(candidates))] {
+		attrs[name] = driftValue(rng, target[name])
+	}
+	return attrs, nil
+}
+
+// driftCandidates returns the target's attributes a person might plausibly
+// change by hand: tags and plain values, leaving out identifiers and
+// references to other resources.
+func driftCandidates(target map[string]interface{}) []string {
+	var names []string
+	for name, value := range target {
+		if name == "id" || name == "arn" || name == "tags_all" ||
+			strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "_arn") {
+			continue
+		}
+		switch value.(type) {
+		case string, bool, float64:
+			names = append(names, name)
+		case map[string]interface{}:
+			if name == "tags" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// driftValue returns a changed copy of value: strings gain a suffix,
+// booleans flip, numbers grow and tags gain a DriftedBy tag.
+func driftValue(rng *rand.Rand, value interface{}) interface{} {
+	suffix := fmt.Sprintf("%04x", rng.IntN(0x10000))
+	switch v := value.(type) {
+	case bool:
+		return !v
+	case float64:
+		return v + float64(1+rng.IntN(9))
+	case map[string]interface{}:
+		tags := maps.Clone(v)
+		tags["DriftedBy"] = "console-" + suffix
+		return tags
+	case string:
+		if v == "" {
+			return "drift-" + suffix
+		}
+		return v + "-drift-" + suffix
+	}
+	return value
+}
+
+// driftedAttributeStrings renders drifted values for the drifted_attributes
+// map: strings as they are, anything else as JSON.
+func driftedAttributeStrings(attrs map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(attrs))
+	for name, value := range attrs {
+		if s, ok := value.(string); ok {
+			out[name] = s
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		out[name] = string(encoded)
+	}
+	return out
+}
+
+// validateDuration accepts Go duration strings such as "90s" or "10m".
+func validateDuration(v interface{}, k string) ([]string, []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", k, err)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// newDriftBackend is an offline backend holding one VPC, with a client
+// calling it.
+func newDriftBackend(t *testing.T) (*offlineBackend, *MockClient, string) {
+	t.Helper()
+	backend := newOfflineBackend(filepath.Join(t.TempDir(), "state.json"), "us-east-1", Provider().ResourcesMap)
+	client := &MockClient{
+		BackendURL: offlineBackendURL,
+		HTTPClient: &http.Client{Transport: &handlerTransport{handler: backend}},
+		Region:     "us-east-1",
+	}
+	vpc, err := client.CreateResource("aws_vpc", map[string]interface{}{
+		"cidr_block":         "10.0.0.0/16",
+		"enable_dns_support": true,
+		"tags":               map[string]interface{}{"Name": "main"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backend, client, vpc.ID
+}
+
+func applyMockDrift(t *testing.T, client *MockClient, values map[string]interface{}) (*schema.ResourceData, error) {
+	t.Helper()
+	res := resourceMockDrift()
+	d := res.TestResourceData()
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			t.Fatalf("setting %s: %v", key, err)
+		}
+	}
+	if diags := res.CreateContext(context.Background(), d, client); diags.HasError() {
+		return d, fmt.Errorf("%s", diags[0].Summary)
+	}
+	return d, nil
+}
+
+func TestMockDriftChangesTargetNow(t *testing.T) {
+	_, client, vpcID := newDriftBackend(t)
+
+	_, err := applyMockDrift(t, client, map[string]interface{}{
+		"resource_type": "aws_vpc",
+		"resource_id":   vpcID,
+		"attributes": map[string]interface{}{
+			"enable_dns_support": "false",
+			"tags":               `{"Name": "main", "Owner": "console"}`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vpc, _ := client.ReadResource("aws_vpc", vpcID)
+	if vpc.Attributes["enable_dns_support"] != false {
+		t.Errorf("enable_dns_support = %v, want false", vpc.Attributes["enable_dns_support"])
+	}
+	if tags, _ := vpc.Attributes["tags_all"].(map[string]interface{}); tags["Owner"] != "console" {
+		t.Errorf("tags_all = %v, want the drifted tags", vpc.Attributes["tags_all"])
+	}
+	if vpc.Attributes["cidr_block"] != "10.0.0.0/16" {
+		t.Error("attributes not drifted should be left alone")
+	}
+}
+
+func TestMockDriftRejectsNonJSONForTypedAttributes(t *testing.T) {
+	_, client, vpcID := newDriftBackend(t)
+
+	_, err := applyMockDrift(t, client, map[string]interface{}{
+		"resource_type": "aws_vpc",
+		"resource_id":   vpcID,
+		"attributes":    map[string]interface{}{"enable_dns_support": "nope"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "must be JSON") {
+		t.Errorf("err = %v, want a JSON error", err)
+	}
+}
+
+func TestMockDriftWaitsForDelay(t *testing.T) {
+	backend, client, vpcID := newDriftBackend(t)
+
+	d, err := applyMockDrift(t, client, map[string]interface{}{
+		"resource_type": "aws_vpc",
+		"resource_id":   vpcID,
+		"attributes":    map[string]interface{}{"enable_dns_support": "false"},
+		"delay":         "1h",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyAt, err := time.Parse(time.RFC3339, d.Get("apply_at").(string))
+	if err != nil || applyAt.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("apply_at = %v, want an hour from now", d.Get("apply_at"))
+	}
+
+	if vpc, _ := client.ReadResource("aws_vpc", vpcID); vpc.Attributes["enable_dns_support"] != true {
+		t.Error("scheduled drift should not apply before its time")
+	}
+
+	backend.store.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if vpc, _ := client.ReadResource("aws_vpc", vpcID); vpc.Attributes["enable_dns_support"] != false {
+		t.Error("scheduled drift should apply once its time has passed")
+	}
+}
+
+func TestMockDriftRandomIsSeeded(t *testing.T) {
+	drift := func(seed int) map[string]interface{} {
+		_, client, vpcID := newDriftBackend(t)
+		d, err := applyMockDrift(t, client, map[string]interface{}{
+			"resource_type": "aws_vpc",
+			"resource_id":   vpcID,
+			"random":        []interface{}{map[string]interface{}{"seed": seed, "count": 2}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d.Get("drifted_attributes").(map[string]interface{})
+	}
+
+	first := drift(7)
+	if len(first) != 2 {
+		t.Fatalf("drifted %v, want two attributes", first)
+	}
+	for name := range first {
+		if name == "id" || name == "arn" || strings.HasSuffix(name, "_id") {
+			t.Errorf("random drift changed identifier %s", name)
+		}
+	}
+	second := drift(7)
+	for name, value := range first {
+		if second[name] != value {
+			t.Errorf("seed 7 drifted %s to %v then %v", name, value, second[name])
+		}
+	}
+}
+
+func TestMockDriftRandomCandidates(t *testing.T) {
+	_, client, vpcID := newDriftBackend(t)
+
+	d, err := applyMockDrift(t, client, map[string]interface{}{
+		"resource_type": "aws_vpc",
+		"resource_id":   vpcID,
+		"random": []interface{}{map[string]interface{}{
+			"seed": 1, "count": 5, "attributes": []interface{}{"tags"},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	drifted := d.Get("drifted_attributes").(map[string]interface{})
+	if len(drifted) != 1 || !strings.Contains(drifted["tags"].(string), "DriftedBy") {
+		t.Errorf("drifted_attributes = %v, want only a DriftedBy tag", drifted)
+	}
+
+	_, err = applyMockDrift(t, client, map[string]interface{}{
+		"resource_type": "aws_vpc",
+		"resource_id":   vpcID,
+		"random": []interface{}{map[string]interface{}{
+			"seed": 1, "attributes": []interface{}{"no_such_attribute"},
+		}},
+	})
+	if err == nil {
+		t.Error("expected an unknown candidate attribute to be rejected")
+	}
+}
+
+func TestMockDriftMissingTarget(t *testing.T) {
+	_, client, _ := newDriftBackend(t)
+
+	_, err := applyMockDrift(t, client, map[string]interface{}{
+		"resource_type": "aws_vpc",
+		"resource_id":   "vpc-0000",
+		"attributes":    map[string]interface{}{"enable_dns_support": "false"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("err = %v, want not found", err)
+	}
+}
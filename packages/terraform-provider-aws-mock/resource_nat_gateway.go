@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceNatGateway() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceNatGatewayCreate,
+		ReadContext:   resourceNatGatewayRead,
+		UpdateContext: resourceNatGatewayUpdate,
+		DeleteContext: resourceNatGatewayDelete,
+		Schema:        resourceNatGatewaySchema(),
+		Timeouts:      dependencyViolationTimeouts(30 * time.Minute),
+	}
+}
+
+// natGatewayNetworkAttributes checks the NAT gateway's subnet and Elastic IP
+// and derives its addresses: private_ip comes from the subnet and, for a
+// public gateway, public_ip from the allocation.
+func natGatewayNetworkAttributes(d *schema.ResourceData, client *MockClient) (map[string]interface{}, diag.Diagnostics) {
+	subnetID := d.Get("subnet_id").(string)
+	subnet, diags := readReference(client, "aws_subnet", subnetID, "subnet_id")
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	network := make(map[string]interface{})
+
+	allocationID := d.Get("allocation_id").(string)
+	switch connectivity := d.Get("connectivity_type").(string); connectivity {
+	case "", "public":
+		if allocationID == "" {
+			return nil, diag.Errorf("InvalidParameterValue: allocation_id is required for a public NAT gateway")
+		}
+		eip, diags := readReference(client, "aws_eip", allocationID, "allocation_id")
+		if diags.HasError() {
+			return nil, diags
+		}
+		if instance := stringAttribute(eip, "instance"); instance != "" {
+			return nil, diag.Errorf("Resource.AlreadyAssociated: Elastic IP address %s is already associated with %s",
+				allocationID, instance)
+		}
+		network["public_ip"] = stringAttribute(eip, "public_ip")
+	case "private":
+		if allocationID != "" {
+			return nil, diag.Errorf("InvalidParameterValue: allocation_id cannot be set for a private NAT gateway")
+		}
+	default:
+		return nil, diag.Errorf("InvalidParameterValue: connectivity_type must be public or private, got %q", connectivity)
+	}
+
+	if cidr := stringAttribute(subnet, "cidr_block"); cidr != "" {
+		taken, err := takenPrivateIPs(client, subnetID, d.Id())
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		privateIP := d.Get("private_ip").(string)
+		if privateIP != "" {
+			if err := validatePrivateIP(privateIP, cidr); err != nil {
+				return nil, diag.FromErr(err)
+			}
+			if err := client.ips.reserve(subnetID, privateIP, taken); err != nil {
+				return nil, diag.FromErr(err)
+			}
+		} else if privateIP, err = client.ips.allocate(subnetID, cidr, taken); err != nil {
+			return nil, diag.FromErr(err)
+		}
+		network["private_ip"] = privateIP
+	}
+
+	return network, nil
+}
+
+func resourceNatGatewayCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceNatGatewaySchema())
+
+	network, diags := natGatewayNetworkAttributes(d, client)
+	if diags.HasError() {
+		return diags
+	}
+	for key, value := range network {
+		attrs[key] = value
+	}
+
+	result, err := client.CreateResource("aws_nat_gateway", attrs)
+	if err != nil {
+		releaseNetwork(client, d.Get("subnet_id").(string), network)
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+
+	if !networkAttributesApplied(result.Attributes, network) {
+		result, err = client.UpdateResourceIfMatch("aws_nat_gateway", result.ID, result.Version, attrs)
+		if err != nil {
+			return diagFromClientError(err)
+		}
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceNatGatewaySchema())
+}
+
+func resourceNatGatewayRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_nat_gateway", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceNatGatewaySchema())
+}
+
+func resourceNatGatewayUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceNatGatewaySchema())
+
+	result, err := client.UpdateResourceIfMatch("aws_nat_gateway", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceNatGatewaySchema())
+}
+
+func resourceNatGatewayDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if diags := deleteRetryingDependencyViolation(ctx, d, client, "aws_nat_gateway"); diags.HasError() {
+		return diags
+	}
+	client.ips.release(d.Get("subnet_id").(string), d.Get("private_ip").(string))
+
+	d.SetId("")
+	return nil
+}
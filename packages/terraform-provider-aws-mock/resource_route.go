@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceRoute() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRouteCreate,
+		ReadContext:   resourceRouteRead,
+		UpdateContext: resourceRouteUpdate,
+		DeleteContext: resourceRouteDelete,
+		Schema:        resourceRouteSchema(),
+	}
+}
+
+// validateRoute checks that the route's target gateway lives in the same VPC
+// as its route table.
+func validateRoute(d *schema.ResourceData, client *MockClient) diag.Diagnostics {
+	routeTableID := d.Get("route_table_id").(string)
+	routeTable, diags := readReference(client, "aws_route_table", routeTableID, "route_table_id")
+	if diags.HasError() {
+		return diags
+	}
+
+	route := make(map[string]interface{})
+	for _, argument := range routeTargetArguments {
+		route[argument] = d.Get(argument)
+	}
+	return validateRouteTargets(client, routeTableID, stringAttribute(routeTable, "vpc_id"), route)
+}
+
+func resourceRouteCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if diags := validateRoute(d, client); diags.HasError() {
+		return diags
+	}
+
+	attrs := extractAttributes(d, resourceRouteSchema())
+
+	result, err := client.CreateResource("aws_route", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceRouteSchema())
+}
+
+func resourceRouteRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_route", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceRouteSchema())
+}
+
+func resourceRouteUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if diags := validateRoute(d, client); diags.HasError() {
+		return diags
+	}
+
+	attrs := extractAttributes(d, resourceRouteSchema())
+
+	result, err := client.UpdateResourceIfMatch("aws_route", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceRouteSchema())
+}
+
+func resourceRouteDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if err := client.DeleteResourceIfMatch("aws_route", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
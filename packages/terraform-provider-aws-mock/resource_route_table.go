@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceRouteTable() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRouteTableCreate,
+		ReadContext:   resourceRouteTableRead,
+		UpdateContext: resourceRouteTableUpdate,
+		DeleteContext: resourceRouteTableDelete,
+		Schema:        resourceRouteTableSchema(),
+	}
+}
+
+// validateRouteTable checks the route table's VPC exists and that every
+// inline route targets a gateway in that VPC.
+func validateRouteTable(d *schema.ResourceData, client *MockClient) diag.Diagnostics {
+	vpcID := d.Get("vpc_id").(string)
+	if _, diags := readReference(client, "aws_vpc", vpcID, "vpc_id"); diags.HasError() {
+		return diags
+	}
+
+	for _, raw := range d.Get("route").(*schema.Set).List() {
+		route, _ := raw.(map[string]interface{})
+		if diags := validateRouteTargets(client, describeRouteTable(d.Id()), vpcID, route); diags.HasError() {
+			return diags
+		}
+	}
+	return nil
+}
+
+func resourceRouteTableCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if diags := validateRouteTable(d, client); diags.HasError() {
+		return diags
+	}
+
+	attrs := extractAttributes(d, resourceRouteTableSchema())
+
+	result, err := client.CreateResource("aws_route_table", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceRouteTableSchema())
+}
+
+func resourceRouteTableRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_route_table", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceRouteTableSchema())
+}
+
+func resourceRouteTableUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if diags := validateRouteTable(d, client); diags.HasError() {
+		return diags
+	}
+
+	attrs := extractAttributes(d, resourceRouteTableSchema())
+
+	result, err := client.UpdateResourceIfMatch("aws_route_table", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceRouteTableSchema())
+}
+
+func resourceRouteTableDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if err := client.DeleteResourceIfMatch("aws_route_table", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
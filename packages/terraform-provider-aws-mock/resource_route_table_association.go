@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceRouteTableAssociation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRouteTableAssociationCreate,
+		ReadContext:   resourceRouteTableAssociationRead,
+		UpdateContext: resourceRouteTableAssociationUpdate,
+		DeleteContext: resourceRouteTableAssociationDelete,
+		Schema:        resourceRouteTableAssociationSchema(),
+	}
+}
+
+func resourceRouteTableAssociationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	diags := validateRouteTableAssociation(client,
+		d.Get("route_table_id").(string), d.Get("subnet_id").(string), d.Get("gateway_id").(string))
+	if diags.HasError() {
+		return diags
+	}
+
+	attrs := extractAttributes(d, resourceRouteTableAssociationSchema())
+
+	result, err := client.CreateResource("aws_route_table_association", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceRouteTableAssociationSchema())
+}
+
+func resourceRouteTableAssociationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_route_table_association", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceRouteTableAssociationSchema())
+}
+
+func resourceRouteTableAssociationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	diags := validateRouteTableAssociation(client,
+		d.Get("route_table_id").(string), d.Get("subnet_id").(string), d.Get("gateway_id").(string))
+	if diags.HasError() {
+		return diags
+	}
+
+	attrs := extractAttributes(d, resourceRouteTableAssociationSchema())
+
+	result, err := client.UpdateResourceIfMatch("aws_route_table_association", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceRouteTableAssociationSchema())
+}
+
+func resourceRouteTableAssociationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if err := client.DeleteResourceIfMatch("aws_route_table_association", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -20,7 +21,7 @@ func resourceS3Bucket() *schema.Resource {
 func extractAttributes(d *schema.ResourceData, s map[string]*schema.Schema) map[string]interface{} {
 	attrs := make(map[string]interface{})
 	for key, field := range s {
-		if field.Computed && !field.Optional {
+		if field.Computed && !field.Optional || field.WriteOnly {
 			continue
 		}
 		if v, ok := d.GetOk(key); ok {
@@ -36,7 +37,11 @@ func extractAttributes(d *schema.ResourceData, s map[string]*schema.Schema) map[
 
 func setAttributes(d *schema.ResourceData, attrs map[string]interface{}, s map[string]*schema.Schema) diag.Diagnostics {
 	var diags diag.Diagnostics
-	for key := range s {
+	for key, field := range s {
+		// Write-only values are sent to the backend but never kept in state
+		if field.WriteOnly {
+			continue
+		}
 		if val, ok := attrs[key]; ok {
 			if err := d.Set(key, val); err != nil {
 				diags = append(diags, diag.Errorf("error setting %s: %s", key, err)...)
@@ -57,13 +62,14 @@ func resourceS3BucketCreate(ctx context.Context, d *schema.ResourceData, meta in
 	}
 
 	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceS3BucketSchema())
 }
 
 func resourceS3BucketRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	result, err := client.ReadResource("aws_s3_bucket", d.Id())
+	result, err := client.ReadResourceBatched("aws_s3_bucket", d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -73,6 +79,7 @@ func resourceS3BucketRead(ctx context.Context, d *schema.ResourceData, meta inte
 		return nil
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceS3BucketSchema())
 }
 
@@ -81,21 +88,62 @@ func resourceS3BucketUpdate(ctx context.Context, d *schema.ResourceData, meta in
 
 	attrs := extractAttributes(d, resourceS3BucketSchema())
 
-	result, err := client.UpdateResource("aws_s3_bucket", d.Id(), attrs)
+	result, err := client.UpdateResourceIfMatch("aws_s3_bucket", d.Id(), resourceVersion(ctx), attrs)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromClientError(err)
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceS3BucketSchema())
 }
 
 func resourceS3BucketDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	if err := client.DeleteResource("aws_s3_bucket", d.Id()); err != nil {
+	bucket := d.Get("bucket").(string)
+	if bucket == "" {
+		bucket = d.Id()
+	}
+
+	objects, err := client.ListBucketObjects(bucket)
+	if err != nil {
 		return diag.FromErr(err)
 	}
+	if len(objects) > 0 {
+		if !d.Get("force_destroy").(bool) {
+			return bucketNotEmptyDiag(bucket, len(objects))
+		}
+		for _, object := range objects {
+			if err := client.DeleteResource("aws_s3_object", object.ID); err != nil {
+				return diag.Errorf("deleting S3 Bucket (%s): purging object %s: %s", bucket, object.ID, err)
+			}
+		}
+	}
+
+	err = client.DeleteResourceIfMatch("aws_s3_bucket", d.Id(), resourceVersion(ctx))
+	if hasErrorCode(err, errCodeBucketNotEmpty) {
+		return bucketNotEmptyDiag(bucket, 0)
+	}
+	if err != nil {
+		return diagFromClientError(err)
+	}
 
 	d.SetId("")
 	return nil
 }
+
+const errCodeBucketNotEmpty = "BucketNotEmpty"
+
+// bucketNotEmptyDiag reports a delete of a bucket that still holds objects.
+// objectCount is 0 when only the backend knows the bucket's contents.
+func bucketNotEmptyDiag(bucket string, objectCount int) diag.Diagnostics {
+	detail := "The bucket you tried to delete is not empty."
+	if objectCount > 0 {
+		detail = fmt.Sprintf("The bucket you tried to delete is not empty: it still holds %d object(s).", objectCount)
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("deleting S3 Bucket (%s): %s", bucket, errCodeBucketNotEmpty),
+		Detail:   detail + " Delete the objects first, or set force_destroy = true to have Terraform purge them.",
+	}}
+}
@@ -28,13 +28,14 @@ func resourceS3BucketPolicyCreate(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceS3BucketPolicySchema())
 }
 
 func resourceS3BucketPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	result, err := client.ReadResource("aws_s3_bucket_policy", d.Id())
+	result, err := client.ReadResourceBatched("aws_s3_bucket_policy", d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -44,6 +45,7 @@ func resourceS3BucketPolicyRead(ctx context.Context, d *schema.ResourceData, met
 		return nil
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceS3BucketPolicySchema())
 }
 
@@ -52,19 +54,20 @@ func resourceS3BucketPolicyUpdate(ctx context.Context, d *schema.ResourceData, m
 
 	attrs := extractAttributes(d, resourceS3BucketPolicySchema())
 
-	result, err := client.UpdateResource("aws_s3_bucket_policy", d.Id(), attrs)
+	result, err := client.UpdateResourceIfMatch("aws_s3_bucket_policy", d.Id(), resourceVersion(ctx), attrs)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromClientError(err)
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceS3BucketPolicySchema())
 }
 
 func resourceS3BucketPolicyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	if err := client.DeleteResource("aws_s3_bucket_policy", d.Id()); err != nil {
-		return diag.FromErr(err)
+	if err := client.DeleteResourceIfMatch("aws_s3_bucket_policy", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
 	}
 
 	d.SetId("")
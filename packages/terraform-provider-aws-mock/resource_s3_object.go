@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceS3Object() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceS3ObjectCreate,
+		ReadContext:   resourceS3ObjectRead,
+		UpdateContext: resourceS3ObjectUpdate,
+		DeleteContext: resourceS3ObjectDelete,
+		Schema:        resourceS3ObjectSchema(),
+	}
+}
+
+func resourceS3ObjectCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceS3ObjectSchema())
+
+	result, err := client.CreateResource("aws_s3_object", attrs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceS3ObjectSchema())
+}
+
+func resourceS3ObjectRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	result, err := client.ReadResourceBatched("aws_s3_object", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceS3ObjectSchema())
+}
+
+func resourceS3ObjectUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	attrs := extractAttributes(d, resourceS3ObjectSchema())
+
+	result, err := client.UpdateResourceIfMatch("aws_s3_object", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	setResourceVersion(ctx, result)
+	return setAttributes(d, result.Attributes, resourceS3ObjectSchema())
+}
+
+func resourceS3ObjectDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*MockClient)
+
+	if err := client.DeleteResourceIfMatch("aws_s3_object", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
+	}
+
+	d.SetId("")
+	return nil
+}
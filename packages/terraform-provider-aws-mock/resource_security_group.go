@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -14,6 +15,7 @@ func resourceSecurityGroup() *schema.Resource {
 		UpdateContext: resourceSecurityGroupUpdate,
 		DeleteContext: resourceSecurityGroupDelete,
 		Schema:        resourceSecurityGroupSchema(),
+		Timeouts:      dependencyViolationTimeouts(15 * time.Minute),
 	}
 }
 
@@ -28,13 +30,14 @@ func resourceSecurityGroupCreate(ctx context.Context, d *schema.ResourceData, me
 	}
 
 	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceSecurityGroupSchema())
 }
 
 func resourceSecurityGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	result, err := client.ReadResource("aws_security_group", d.Id())
+	result, err := client.ReadResourceBatched("aws_security_group", d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -44,6 +47,7 @@ func resourceSecurityGroupRead(ctx context.Context, d *schema.ResourceData, meta
 		return nil
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceSecurityGroupSchema())
 }
 
@@ -52,19 +56,20 @@ func resourceSecurityGroupUpdate(ctx context.Context, d *schema.ResourceData, me
 
 	attrs := extractAttributes(d, resourceSecurityGroupSchema())
 
-	result, err := client.UpdateResource("aws_security_group", d.Id(), attrs)
+	result, err := client.UpdateResourceIfMatch("aws_security_group", d.Id(), resourceVersion(ctx), attrs)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromClientError(err)
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceSecurityGroupSchema())
 }
 
 func resourceSecurityGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	if err := client.DeleteResource("aws_security_group", d.Id()); err != nil {
-		return diag.FromErr(err)
+	if diags := deleteRetryingDependencyViolation(ctx, d, client, "aws_security_group"); diags.HasError() {
+		return diags
 	}
 
 	d.SetId("")
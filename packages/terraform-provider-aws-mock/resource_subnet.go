@@ -28,13 +28,14 @@ func resourceSubnetCreate(ctx context.Context, d *schema.ResourceData, meta inte
 	}
 
 	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceSubnetSchema())
 }
 
 func resourceSubnetRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	result, err := client.ReadResource("aws_subnet", d.Id())
+	result, err := client.ReadResourceBatched("aws_subnet", d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -44,6 +45,7 @@ func resourceSubnetRead(ctx context.Context, d *schema.ResourceData, meta interf
 		return nil
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceSubnetSchema())
 }
 
@@ -52,19 +54,20 @@ func resourceSubnetUpdate(ctx context.Context, d *schema.ResourceData, meta inte
 
 	attrs := extractAttributes(d, resourceSubnetSchema())
 
-	result, err := client.UpdateResource("aws_subnet", d.Id(), attrs)
+	result, err := client.UpdateResourceIfMatch("aws_subnet", d.Id(), resourceVersion(ctx), attrs)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromClientError(err)
 	}
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceSubnetSchema())
 }
 
 func resourceSubnetDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	if err := client.DeleteResource("aws_subnet", d.Id()); err != nil {
-		return diag.FromErr(err)
+	if err := client.DeleteResourceIfMatch("aws_subnet", d.Id(), resourceVersion(ctx)); err != nil {
+		return diagFromClientError(err)
 	}
 
 	d.SetId("")
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// resourceVersionKey is the key in a resource's private state holding the
+// backend's resource version (ETag), sent back as If-Match on update and
+// delete.
+const resourceVersionKey = "backend_version"
+
+// privateVersion carries a resource's version through one RPC: the version
+// Terraform had in private state on the way in, and the one the backend
+// returned on the way out.
+type privateVersion struct {
+	value string
+}
+
+type privateVersionKey struct{}
+
+// setResourceVersion records the version returned by the backend. Outside
+// an RPC that carries private state, as in unit tests calling resource
+// functions directly, it does nothing.
+func setResourceVersion(ctx context.Context, result *ResourceResponse) {
+	if v, ok := ctx.Value(privateVersionKey{}).(*privateVersion); ok && result.Version != "" {
+		v.value = result.Version
+	}
+}
+
+// resourceVersion returns the version last seen for the resource the RPC is
+// about, or "" if none.
+func resourceVersion(ctx context.Context) string {
+	if v, ok := ctx.Value(privateVersionKey{}).(*privateVersion); ok {
+		return v.value
+	}
+	return ""
+}
+
+// withResourceVersions wraps the SDKv2 provider server so resource functions
+// can keep the backend version in private state. SDKv2 gives them no access
+// to it, so the wrapper reads the version out of the request's private
+// bytes into the context, and writes what the resource function recorded
+// back into the response's.
+func withResourceVersions(next tfprotov5.ProviderServer) tfprotov5.ProviderServer {
+	return &versionedServer{ProviderServer: next}
+}
+
+type versionedServer struct {
+	tfprotov5.ProviderServer
+}
+
+func (s *versionedServer) ReadResource(ctx context.Context, req *tfprotov5.ReadResourceRequest) (*tfprotov5.ReadResourceResponse, error) {
+	v := &privateVersion{value: privateVersionOf(req.Private)}
+	resp, err := s.ProviderServer.ReadResource(context.WithValue(ctx, privateVersionKey{}, v), req)
+	if err == nil && resp != nil {
+		resp.Private = withPrivateVersion(resp.Private, v.value)
+	}
+	return resp, err
+}
+
+func (s *versionedServer) PlanResourceChange(ctx context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	resp, err := s.ProviderServer.PlanResourceChange(ctx, req)
+	if err == nil && resp != nil {
+		resp.PlannedPrivate = withPrivateVersion(resp.PlannedPrivate, privateVersionOf(req.PriorPrivate))
+	}
+	return resp, err
+}
+
+func (s *versionedServer) ApplyResourceChange(ctx context.Context, req *tfprotov5.ApplyResourceChangeRequest) (*tfprotov5.ApplyResourceChangeResponse, error) {
+	v := &privateVersion{value: privateVersionOf(req.PlannedPrivate)}
+	resp, err := s.ProviderServer.ApplyResourceChange(context.WithValue(ctx, privateVersionKey{}, v), req)
+	if err == nil && resp != nil {
+		resp.Private = withPrivateVersion(resp.Private, v.value)
+	}
+	return resp, err
+}
+
+// privateVersionOf returns the version held in private state bytes.
+func privateVersionOf(private []byte) string {
+	var m map[string]interface{}
+	if len(private) == 0 || json.Unmarshal(private, &m) != nil {
+		return ""
+	}
+	v, _ := m[resourceVersionKey].(string)
+	return v
+}
+
+// withPrivateVersion returns private state bytes with the version set,
+// keeping whatever else the SDK stored there.
+func withPrivateVersion(private []byte, version string) []byte {
+	if version == "" {
+		return private
+	}
+	m := make(map[string]interface{})
+	if len(private) > 0 && json.Unmarshal(private, &m) != nil {
+		return private
+	}
+	m[resourceVersionKey] = version
+	out, err := json.Marshal(m)
+	if err != nil {
+		return private
+	}
+	return out
+}
+
+// diagFromClientError converts a MockClient error into diagnostics, giving
+// known backend conditions a readable summary.
+func diagFromClientError(err error) diag.Diagnostics {
+	var precondition *PreconditionFailedError
+	if errors.As(err, &precondition) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Resource changed outside Terraform",
+			Detail: fmt.Sprintf(
+				"%s %s was modified by someone else since Terraform last read it (expected version %s). "+
+					"Run terraform plan again to review the current state before applying.",
+				precondition.ResourceType, precondition.ID, precondition.Version,
+			),
+		}}
+	}
+	return diag.FromErr(err)
+}
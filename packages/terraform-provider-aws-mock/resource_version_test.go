@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourceVersionNotInSchema(t *testing.T) {
+	for name, res := range Provider().ResourcesMap {
+		if _, ok := res.Schema[resourceVersionKey]; ok {
+			t.Errorf("%s should keep %s in private state, not its schema", name, resourceVersionKey)
+		}
+	}
+}
+
+func TestUpdateConflictReportsChangedOutsideTerraform(t *testing.T) {
+	var ifMatch string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifMatch = r.Header.Get("If-Match")
+		w.WriteHeader(412)
+	}))
+	defer server.Close()
+
+	res := Provider().ResourcesMap["aws_vpc"]
+	d := res.TestResourceData()
+	d.SetId("vpc-abc123")
+	ctx := context.WithValue(context.Background(), privateVersionKey{}, &privateVersion{value: "3"})
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: server.Client()}
+	diags := res.UpdateContext(ctx, d, client)
+
+	if ifMatch != `"3"` {
+		t.Errorf("expected If-Match \"3\", got %q", ifMatch)
+	}
+	if !diags.HasError() {
+		t.Fatal("expected an error diagnostic")
+	}
+	if diags[0].Summary != "Resource changed outside Terraform" {
+		t.Errorf("unexpected summary: %s", diags[0].Summary)
+	}
+}
+
+// versionedChange plans and applies config over prior through server,
+// returning the new state, its private bytes and the apply diagnostics.
+func versionedChange(t *testing.T, server tfprotov5.ProviderServer, r *schema.Resource, prior cty.Value, priorPrivate []byte, config cty.Value) (cty.Value, []byte, []*tfprotov5.Diagnostic) {
+	t.Helper()
+	ctx := context.Background()
+	ty := r.CoreConfigSchema().ImpliedType()
+	encode := func(v cty.Value) *tfprotov5.DynamicValue { return mustMsgpack(t, v, ty) }
+
+	plan, err := server.PlanResourceChange(ctx, &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         "aws_vpc",
+		PriorState:       encode(prior),
+		PriorPrivate:     priorPrivate,
+		ProposedNewState: encode(proposedNewState(r, prior, config)),
+		Config:           encode(config),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range plan.Diagnostics {
+		t.Fatalf("plan: %s: %s", d.Summary, d.Detail)
+	}
+
+	apply, err := server.ApplyResourceChange(ctx, &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:       "aws_vpc",
+		PriorState:     encode(prior),
+		PlannedState:   plan.PlannedState,
+		PlannedPrivate: plan.PlannedPrivate,
+		Config:         encode(config),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apply.NewState == nil {
+		return cty.NullVal(ty), apply.Private, apply.Diagnostics
+	}
+	state, err := msgpack.Unmarshal(apply.NewState.MsgPack, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state, apply.Private, apply.Diagnostics
+}
+
+func TestResourceVersionKeptInPrivateState(t *testing.T) {
+	p := Provider()
+	r := p.ResourcesMap["aws_vpc"]
+	client := newOfflineClient(filepath.Join(t.TempDir(), "state.json"), "us-east-1", p.ResourcesMap)
+	p.SetMeta(client)
+	server := withResourceVersions(schema.NewGRPCProviderServer(p))
+	ty := r.CoreConfigSchema().ImpliedType()
+
+	config := resourceObject(r, map[string]cty.Value{"cidr_block": cty.StringVal("10.0.0.0/16")})
+	state, private, diags := versionedChange(t, server, r, cty.NullVal(ty), nil, config)
+	for _, d := range diags {
+		t.Fatalf("create: %s: %s", d.Summary, d.Detail)
+	}
+	if got := privateVersionOf(private); got != "1" {
+		t.Errorf("private version after create = %q, want 1", got)
+	}
+	id := state.GetAttr("id").AsString()
+
+	// Someone else changes the VPC: the next update sends the stale
+	// version and is refused.
+	if err := client.DriftResource("aws_vpc", id, map[string]interface{}{"enable_dns_support": false}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	config = resourceObject(r, map[string]cty.Value{
+		"cidr_block": cty.StringVal("10.0.0.0/16"),
+		"tags":       cty.MapVal(map[string]cty.Value{"Name": cty.StringVal("main")}),
+	})
+	_, _, diags = versionedChange(t, server, r, state, private, config)
+	if len(diags) == 0 || diags[0].Summary != "Resource changed outside Terraform" {
+		t.Fatalf("update over a stale version: diagnostics = %v, want a conflict", diags)
+	}
+
+	// A refresh picks up the new version, and the update then goes through.
+	read, err := server.ReadResource(context.Background(), &tfprotov5.ReadResourceRequest{
+		TypeName:     "aws_vpc",
+		CurrentState: mustMsgpack(t, state, ty),
+		Private:      private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := privateVersionOf(read.Private); got != "2" {
+		t.Errorf("private version after refresh = %q, want 2", got)
+	}
+	refreshed, err := msgpack.Unmarshal(read.NewState.MsgPack, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, private, diags = versionedChange(t, server, r, refreshed, read.Private, config)
+	for _, d := range diags {
+		t.Fatalf("update after refresh: %s: %s", d.Summary, d.Detail)
+	}
+	if got := privateVersionOf(private); got != "3" {
+		t.Errorf("private version after update = %q, want 3", got)
+	}
+}
+
+func mustMsgpack(t *testing.T, v cty.Value, ty cty.Type) *tfprotov5.DynamicValue {
+	t.Helper()
+	raw, err := msgpack.Marshal(v, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tfprotov5.DynamicValue{MsgPack: raw}
+}
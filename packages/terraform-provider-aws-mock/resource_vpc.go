@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -14,6 +15,7 @@ func resourceVpc() *schema.Resource {
 		UpdateContext: resourceVpcUpdate,
 		DeleteContext: resourceVpcDelete,
 		Schema:        resourceVpcSchema(),
+		Timeouts:      dependencyViolationTimeouts(5 * time.Minute),
 	}
 }
 
@@ -28,13 +30,14 @@ func resourceVpcCreate(ctx context.Context, d *schema.ResourceData, meta interfa
 	}
 
 	d.SetId(result.ID)
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceVpcSchema())
 }
 
 func resourceVpcRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	result, err := client.ReadResource("aws_vpc", d.Id())
+	result, err := client.ReadResourceBatched("aws_vpc", d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -44,7 +47,7 @@ func resourceVpcRead(ctx context.Context, d *schema.ResourceData, meta interface
 		return nil
 	}
 
-	return setAttributes(d, result.Attributes, resourceVpcSchema())
+	return setVpcAttributes(ctx, d, client, result)
 }
 
 func resourceVpcUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -52,19 +55,32 @@ func resourceVpcUpdate(ctx context.Context, d *schema.ResourceData, meta interfa
 
 	attrs := extractAttributes(d, resourceVpcSchema())
 
-	result, err := client.UpdateResource("aws_vpc", d.Id(), attrs)
+	result, err := client.UpdateResourceIfMatch("aws_vpc", d.Id(), resourceVersion(ctx), attrs)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	return setVpcAttributes(ctx, d, client, result)
+}
+
+// setVpcAttributes records a VPC read from the backend, with the main route
+// table its main route table association gives it.
+func setVpcAttributes(ctx context.Context, d *schema.ResourceData, client *MockClient, result *ResourceResponse) diag.Diagnostics {
+	mainRouteTable, err := mainRouteTableID(result, client.ListResourcesBatched)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	result.Attributes["main_route_table_id"] = mainRouteTable
 
+	setResourceVersion(ctx, result)
 	return setAttributes(d, result.Attributes, resourceVpcSchema())
 }
 
 func resourceVpcDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*MockClient)
 
-	if err := client.DeleteResource("aws_vpc", d.Id()); err != nil {
-		return diag.FromErr(err)
+	if diags := deleteRetryingDependencyViolation(ctx, d, client, "aws_vpc"); diags.HasError() {
+		return diags
 	}
 
 	d.SetId("")
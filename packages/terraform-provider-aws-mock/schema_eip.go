@@ -0,0 +1,93 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceEipSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"address": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"allocation_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"associate_with_private_ip": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"association_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"carrier_ip": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"customer_owned_ip": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"customer_owned_ipv4_pool": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"domain": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"instance": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"network_border_group": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"network_interface": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"private_dns": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"private_ip": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"public_dns": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"public_ip": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"public_ipv4_pool": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"tags_all": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
@@ -0,0 +1,60 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceIamPolicySchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"arn": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"attachment_count": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"description": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"name": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"name_prefix": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"path": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"policy": { // schemagen:keep
+			Type:             schema.TypeString,
+			Required:         true,
+			ValidateFunc:     validatePolicyDocumentFunc,
+			DiffSuppressFunc: suppressEquivalentPolicyDiffs,
+		},
+		"policy_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"tags_all": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
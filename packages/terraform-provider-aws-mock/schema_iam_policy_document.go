@@ -0,0 +1,120 @@
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func iamPolicyPrincipalSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"identifiers": {
+					Type:     schema.TypeSet,
+					Required: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceIamPolicyDocumentSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"json": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"minified_json": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"override_policy_documents": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringIsJSON},
+		},
+		"policy_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"source_policy_documents": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringIsJSON},
+		},
+		"statement": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"actions": {
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"condition": {
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"test": {
+									Type:     schema.TypeString,
+									Required: true,
+								},
+								"values": {
+									Type:     schema.TypeList,
+									Required: true,
+									Elem:     &schema.Schema{Type: schema.TypeString},
+								},
+								"variable": {
+									Type:     schema.TypeString,
+									Required: true,
+								},
+							},
+						},
+					},
+					"effect": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      "Allow",
+						ValidateFunc: validation.StringInSlice([]string{"Allow", "Deny"}, false),
+					},
+					"not_actions": {
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"not_principals": iamPolicyPrincipalSchema(),
+					"not_resources": {
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"principals": iamPolicyPrincipalSchema(),
+					"resources": {
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"sid": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+				},
+			},
+		},
+		"version": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "2012-10-17",
+			ValidateFunc: validation.StringInSlice([]string{"2008-10-17", "2012-10-17"}, false),
+		},
+	}
+}
@@ -0,0 +1,95 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceIamRoleSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"arn": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"assume_role_policy": { // schemagen:keep
+			Type:             schema.TypeString,
+			Required:         true,
+			ValidateFunc:     validateTrustPolicyFunc,
+			DiffSuppressFunc: suppressEquivalentPolicyDiffs,
+		},
+		"create_date": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"description": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"force_detach_policies": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"inline_policy": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"policy": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+				},
+			},
+		},
+		"managed_policy_arns": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"max_session_duration": {
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"name": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"name_prefix": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"path": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"permissions_boundary": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"tags_all": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"unique_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+}
@@ -0,0 +1,23 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceIamRolePolicyAttachmentSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"policy_arn": { // schemagen:keep
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"role": { // schemagen:keep
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+	}
+}
@@ -0,0 +1,34 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceKmsCiphertextSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"ciphertext_blob": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"context": { // schemagen:keep
+			Type:     schema.TypeMap,
+			Optional: true,
+			ForceNew: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"key_id": { // schemagen:keep
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"plaintext": { // schemagen:keep
+			Type:      schema.TypeString,
+			Required:  true,
+			ForceNew:  true,
+			Sensitive: true,
+		},
+	}
+}
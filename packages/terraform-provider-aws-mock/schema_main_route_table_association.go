@@ -0,0 +1,25 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceMainRouteTableAssociationSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"original_route_table_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"route_table_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"vpc_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceMockDriftSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"apply_at": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"attributes": {
+			Type:         schema.TypeMap,
+			Optional:     true,
+			ForceNew:     true,
+			Elem:         &schema.Schema{Type: schema.TypeString},
+			ExactlyOneOf: []string{"attributes", "random"},
+		},
+		"delay": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			ValidateFunc: validateDuration,
+		},
+		"drifted_attributes": {
+			Type:     schema.TypeMap,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"random": {
+			Type:     schema.TypeList,
+			Optional: true,
+			ForceNew: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"attributes": {
+						Type:     schema.TypeList,
+						Optional: true,
+						ForceNew: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"count": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ForceNew:     true,
+						Default:      1,
+						ValidateFunc: validation.IntAtLeast(1),
+					},
+					"seed": {
+						Type:     schema.TypeInt,
+						Required: true,
+						ForceNew: true,
+					},
+				},
+			},
+		},
+		"resource_id": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"resource_type": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+	}
+}
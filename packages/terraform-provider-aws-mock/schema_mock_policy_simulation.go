@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceMockPolicySimulationSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"action": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"allowed": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"bucket": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ExactlyOneOf: []string{"bucket", "policy"},
+		},
+		"context": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"key": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"values": {
+						Type:     schema.TypeList,
+						Required: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		},
+		"decision": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"matched_statements": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"policy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringIsJSON,
+		},
+		"principal": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"resource": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	}
+}
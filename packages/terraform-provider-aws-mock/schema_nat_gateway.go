@@ -0,0 +1,69 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceNatGatewaySchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"allocation_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"association_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"connectivity_type": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"network_interface_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"private_ip": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"public_ip": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"secondary_allocation_ids": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"secondary_private_ip_address_count": {
+			Type:     schema.TypeInt,
+			Optional: true,
+			Computed: true,
+		},
+		"secondary_private_ip_addresses": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"subnet_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"tags_all": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceRouteSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"carrier_gateway_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"core_network_arn": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"destination_cidr_block": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"destination_ipv6_cidr_block": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"destination_prefix_list_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"egress_only_gateway_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"gateway_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"instance_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"instance_owner_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"local_gateway_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"nat_gateway_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"network_interface_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"origin": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"route_table_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"state": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"transit_gateway_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"vpc_endpoint_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"vpc_peering_connection_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	}
+}
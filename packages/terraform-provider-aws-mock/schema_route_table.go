@@ -0,0 +1,103 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceRouteTableSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"arn": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"owner_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"propagating_vgws": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"route": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"carrier_gateway_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"cidr_block": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"core_network_arn": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"destination_prefix_list_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"egress_only_gateway_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"gateway_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"ipv6_cidr_block": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"local_gateway_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"nat_gateway_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"network_interface_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"transit_gateway_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"vpc_endpoint_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"vpc_peering_connection_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+				},
+			},
+		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"tags_all": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"vpc_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+	}
+}
@@ -0,0 +1,25 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceRouteTableAssociationSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"gateway_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"route_table_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"subnet_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	}
+}
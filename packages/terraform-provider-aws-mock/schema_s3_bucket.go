@@ -1,3 +1,6 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
 package main
 
 import (
@@ -38,52 +41,6 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 			Type:     schema.TypeString,
 			Computed: true,
 		},
-		"force_destroy": {
-			Type:     schema.TypeBool,
-			Optional: true,
-		},
-		"hosted_zone_id": {
-			Type:     schema.TypeString,
-			Computed: true,
-		},
-		"object_lock_enabled": {
-			Type:     schema.TypeBool,
-			Optional: true,
-			Computed: true,
-		},
-		"policy": {
-			Type:     schema.TypeString,
-			Optional: true,
-			Computed: true,
-		},
-		"region": {
-			Type:     schema.TypeString,
-			Computed: true,
-		},
-		"request_payer": {
-			Type:     schema.TypeString,
-			Optional: true,
-			Computed: true,
-		},
-		"tags": {
-			Type:     schema.TypeMap,
-			Optional: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
-		},
-		"tags_all": {
-			Type:     schema.TypeMap,
-			Optional: true,
-			Computed: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
-		},
-		"website_domain": {
-			Type:     schema.TypeString,
-			Computed: true,
-		},
-		"website_endpoint": {
-			Type:     schema.TypeString,
-			Computed: true,
-		},
 		"cors_rule": {
 			Type:     schema.TypeList,
 			Optional: true,
@@ -117,13 +74,17 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 				},
 			},
 		},
+		"force_destroy": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
 		"grant": {
 			Type:     schema.TypeSet,
 			Optional: true,
 			Computed: true,
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
-					"id": {
+					"id": { // schemagen:keep
 						Type:     schema.TypeString,
 						Optional: true,
 					},
@@ -143,6 +104,10 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 				},
 			},
 		},
+		"hosted_zone_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
 		"lifecycle_rule": {
 			Type:     schema.TypeList,
 			Optional: true,
@@ -157,21 +122,7 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 						Type:     schema.TypeBool,
 						Required: true,
 					},
-					"id": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"prefix": {
-						Type:     schema.TypeString,
-						Optional: true,
-					},
-					"tags": {
-						Type:     schema.TypeMap,
-						Optional: true,
-						Elem:     &schema.Schema{Type: schema.TypeString},
-					},
-					"expiration": {
+					"expiration": { // schemagen:keep
 						Type:     schema.TypeList,
 						MaxItems: 1,
 						Optional: true,
@@ -192,7 +143,12 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 							},
 						},
 					},
-					"noncurrent_version_expiration": {
+					"id": { // schemagen:keep
+						Type:     schema.TypeString,
+						Optional: true,
+						Computed: true,
+					},
+					"noncurrent_version_expiration": { // schemagen:keep
 						Type:     schema.TypeList,
 						MaxItems: 1,
 						Optional: true,
@@ -205,7 +161,7 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 							},
 						},
 					},
-					"noncurrent_version_transition": {
+					"noncurrent_version_transition": { // schemagen:keep
 						Type:     schema.TypeSet,
 						Optional: true,
 						Elem: &schema.Resource{
@@ -221,7 +177,16 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 							},
 						},
 					},
-					"transition": {
+					"prefix": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"tags": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"transition": { // schemagen:keep
 						Type:     schema.TypeSet,
 						Optional: true,
 						Elem: &schema.Resource{
@@ -273,7 +238,7 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 						Type:     schema.TypeString,
 						Optional: true,
 					},
-					"rule": {
+					"rule": { // schemagen:keep
 						Type:     schema.TypeList,
 						MaxItems: 1,
 						Optional: true,
@@ -307,6 +272,20 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 				},
 			},
 		},
+		"object_lock_enabled": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Computed: true,
+		},
+		"policy": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"region": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
 		"replication_configuration": {
 			Type:     schema.TypeList,
 			MaxItems: 1,
@@ -318,7 +297,7 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 						Type:     schema.TypeString,
 						Required: true,
 					},
-					"rules": {
+					"rules": { // schemagen:keep
 						Type:     schema.TypeSet,
 						Required: true,
 						Elem: &schema.Resource{
@@ -462,6 +441,11 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 				},
 			},
 		},
+		"request_payer": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
 		"server_side_encryption_configuration": {
 			Type:     schema.TypeList,
 			MaxItems: 1,
@@ -469,7 +453,7 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 			Computed: true,
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
-					"rule": {
+					"rule": { // schemagen:keep
 						Type:     schema.TypeList,
 						MaxItems: 1,
 						Required: true,
@@ -502,6 +486,17 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 				},
 			},
 		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"tags_all": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
 		"versioning": {
 			Type:     schema.TypeList,
 			MaxItems: 1,
@@ -546,5 +541,13 @@ func resourceS3BucketSchema() map[string]*schema.Schema {
 				},
 			},
 		},
+		"website_domain": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"website_endpoint": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
 	}
 }
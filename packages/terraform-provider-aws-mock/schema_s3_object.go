@@ -0,0 +1,119 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceS3ObjectSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"acl": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"arn": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"bucket": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"bucket_key_enabled": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Computed: true,
+		},
+		"cache_control": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"content": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"content_base64": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"content_disposition": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"content_encoding": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"content_language": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"content_type": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"etag": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"force_destroy": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"key": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"kms_key_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"metadata": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"server_side_encryption": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"source": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"source_hash": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"storage_class": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"tags_all": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"version_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"website_redirect": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	}
+}
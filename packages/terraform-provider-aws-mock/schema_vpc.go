@@ -1,3 +1,6 @@
+// Generated by go generate from the provider schema JSON. Entries marked
+// schemagen:keep are kept as they are; the generator rewrites the rest.
+
 package main
 
 import (
@@ -15,10 +18,11 @@ func resourceVpcSchema() map[string]*schema.Schema {
 			Optional: true,
 			Computed: true,
 		},
-		"cidr_block": {
+		"cidr_block": { // schemagen:keep
 			Type:     schema.TypeString,
 			Optional: true,
 			Computed: true,
+			ForceNew: true,
 		},
 		"default_network_acl_id": {
 			Type:     schema.TypeString,
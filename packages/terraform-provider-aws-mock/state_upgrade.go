@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Hand-written resources start at schema version 1. Version 0 is state left
+// by buildDynamicResource for the same type, or by the hand-written resource
+// before versions were tracked. A resource that later changes its schema
+// incompatibly sets SchemaVersion itself and appends its own upgraders,
+// starting at version 1.
+const handWrittenSchemaVersion = 1
+
+// addDynamicStateUpgrader makes r able to read state written by its dynamic
+// counterpart. dynamic may be nil when the embedded schema has no such type.
+func addDynamicStateUpgrader(r, dynamic *schema.Resource) {
+	previous := r
+	if dynamic != nil {
+		previous = dynamic
+	}
+
+	if r.SchemaVersion < handWrittenSchemaVersion {
+		r.SchemaVersion = handWrittenSchemaVersion
+	}
+	r.StateUpgraders = append([]schema.StateUpgrader{{
+		Version: 0,
+		Type:    previous.CoreConfigSchema().ImpliedType(),
+		Upgrade: upgradeDynamicState(r.Schema),
+	}}, r.StateUpgraders...)
+}
+
+// upgradeDynamicState coerces generic state into the shape of s: values are
+// converted to the attribute's type, single objects and lists of one object
+// are swapped to match the block's nesting, and attributes s doesn't know
+// are dropped.
+func upgradeDynamicState(s map[string]*schema.Schema) schema.StateUpgradeFunc {
+	return func(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+		if rawState == nil {
+			return nil, nil
+		}
+
+		upgraded := coerceStateObject(rawState, s)
+		for _, key := range []string{"id", "timeouts"} {
+			if v, ok := rawState[key]; ok {
+				upgraded[key] = v
+			}
+		}
+		return upgraded, nil
+	}
+}
+
+func coerceStateObject(raw map[string]interface{}, s map[string]*schema.Schema) map[string]interface{} {
+	out := make(map[string]interface{}, len(s))
+	for key, field := range s {
+		v, ok := raw[key]
+		if !ok || v == nil {
+			continue
+		}
+		if coerced, ok := coerceStateValue(v, field); ok {
+			out[key] = coerced
+		}
+	}
+	return out
+}
+
+// coerceStateValue converts v to the JSON shape of field. ok is false when
+// there is no sensible conversion, in which case the attribute is left null
+// and refreshed from the backend.
+func coerceStateValue(v interface{}, field *schema.Schema) (interface{}, bool) {
+	switch field.Type {
+	case schema.TypeString:
+		return coerceString(v)
+	case schema.TypeBool:
+		return coerceBool(v)
+	case schema.TypeInt, schema.TypeFloat:
+		return coerceNumber(v)
+
+	case schema.TypeMap:
+		if list, ok := v.([]interface{}); ok && len(list) == 1 {
+			v = list[0]
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		elem, _ := field.Elem.(*schema.Schema)
+		if elem == nil {
+			elem = &schema.Schema{Type: schema.TypeString}
+		}
+		out := make(map[string]interface{}, len(m))
+		for key, value := range m {
+			if coerced, ok := coerceStateValue(value, elem); ok {
+				out[key] = coerced
+			}
+		}
+		return out, true
+
+	case schema.TypeList, schema.TypeSet:
+		list, ok := v.([]interface{})
+		if !ok {
+			list = []interface{}{v}
+		}
+		if field.MaxItems > 0 && len(list) > field.MaxItems {
+			list = list[:field.MaxItems]
+		}
+		out := make([]interface{}, 0, len(list))
+		for _, item := range list {
+			switch elem := field.Elem.(type) {
+			case *schema.Resource:
+				if m, ok := item.(map[string]interface{}); ok {
+					out = append(out, coerceStateObject(m, elem.Schema))
+				}
+			case *schema.Schema:
+				if coerced, ok := coerceStateValue(item, elem); ok {
+					out = append(out, coerced)
+				}
+			default:
+				if coerced, ok := coerceString(item); ok {
+					out = append(out, coerced)
+				}
+			}
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func coerceString(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	case map[string]interface{}, []interface{}:
+		// buildDynamicResource flattens nested collections to strings.
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+		return string(encoded), true
+	}
+	return nil, false
+}
+
+func coerceBool(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	case float64:
+		return v != 0, true
+	case json.Number:
+		f, err := v.Float64()
+		return f != 0, err == nil
+	}
+	return nil, false
+}
+
+func coerceNumber(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case float64, json.Number:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	case bool:
+		if v {
+			return float64(1), true
+		}
+		return float64(0), true
+	}
+	return nil, false
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// upgradeState runs r's state upgraders from version up to its current
+// schema version, the way Terraform does for JSON state.
+func upgradeState(t *testing.T, r *schema.Resource, version int, state map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	for _, upgrader := range r.StateUpgraders {
+		if upgrader.Version != version {
+			continue
+		}
+		var err error
+		if state, err = upgrader.Upgrade(context.Background(), state, nil); err != nil {
+			t.Fatalf("upgrader %d failed: %v", upgrader.Version, err)
+		}
+		version++
+	}
+	if version != r.SchemaVersion {
+		t.Fatalf("state upgraded to version %d, want %d", version, r.SchemaVersion)
+	}
+	return state
+}
+
+func readStateFixture(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	var state map[string]interface{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("parsing %s: %v", path, err)
+	}
+	return state
+}
+
+func TestHandWrittenResourcesAreVersioned(t *testing.T) {
+	p := Provider()
+	for _, name := range []string{"aws_s3_bucket", "aws_vpc", "aws_instance", "aws_iam_role", "aws_route_table", "aws_internet_gateway"} {
+		r := p.ResourcesMap[name]
+		if r.SchemaVersion < handWrittenSchemaVersion {
+			t.Errorf("%s: SchemaVersion = %d, want at least %d", name, r.SchemaVersion, handWrittenSchemaVersion)
+		}
+		if len(r.StateUpgraders) == 0 || r.StateUpgraders[0].Version != 0 {
+			t.Errorf("%s: expected an upgrader from the dynamic shape (version 0)", name)
+		}
+		if err := r.InternalValidate(nil, true); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	if r := p.ResourcesMap["aws_sqs_queue"]; r != nil && r.SchemaVersion != 0 {
+		t.Errorf("dynamic resources should stay at version 0, aws_sqs_queue is %d", r.SchemaVersion)
+	}
+}
+
+// TestStateUpgradeFixtures upgrades each testdata/state/<type>_v0.json and
+// compares it with the matching <type>_v1.json.
+func TestStateUpgradeFixtures(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "state", "*_v0.json"))
+	if err != nil || len(fixtures) == 0 {
+		t.Fatalf("no state fixtures found: %v", err)
+	}
+
+	p := Provider()
+	for _, fixture := range fixtures {
+		resourceType := strings.TrimSuffix(filepath.Base(fixture), "_v0.json")
+		t.Run(resourceType, func(t *testing.T) {
+			r := p.ResourcesMap[resourceType]
+			if r == nil {
+				t.Fatalf("no resource %s", resourceType)
+			}
+
+			got := upgradeState(t, r, 0, readStateFixture(t, fixture))
+			want := readStateFixture(t, strings.TrimSuffix(fixture, "_v0.json")+"_v1.json")
+			if !reflect.DeepEqual(got, want) {
+				gotJSON, _ := json.MarshalIndent(got, "", "  ")
+				t.Errorf("upgraded state mismatch:\n%s", gotJSON)
+			}
+		})
+	}
+}
+
+func TestStateUpgradeIsIdempotent(t *testing.T) {
+	r := Provider().ResourcesMap["aws_s3_bucket"]
+	want := readStateFixture(t, filepath.Join("testdata", "state", "aws_s3_bucket_v1.json"))
+	got := upgradeState(t, r, 0, readStateFixture(t, filepath.Join("testdata", "state", "aws_s3_bucket_v1.json")))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("upgrading state already in the current shape should not change it, got %v", got)
+	}
+}
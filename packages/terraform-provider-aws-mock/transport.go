@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// sigV4Algorithm is the AWS Signature Version 4 scheme used when the
+// provider is configured with access_key/secret_key.
+const sigV4Algorithm = "AWS4-HMAC-SHA256"
+
+// sigV4Service is the service name in the credential scope. Every backend
+// route shares it, as the backend isn't split by service.
+const sigV4Service = "mock"
+
+const sigV4DateFormat = "20060102T150405Z"
+
+// sigV4SignedHeaders lists the headers covered by the signature, sorted and
+// lower-cased as SigV4 requires.
+const sigV4SignedHeaders = "host;x-amz-content-sha256;x-amz-date"
+
+// authTransport adds backend credentials to every outgoing request. A bearer
+// token takes precedence over an access/secret key pair, which signs the
+// request the way AWS SigV4 does. The provider only sends credentials;
+// checking them is up to the backend.
+type authTransport struct {
+	base      http.RoundTripper
+	token     string
+	accessKey string
+	secretKey string
+	region    string
+	now       func() time.Time
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyHash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(bodyHash[:])
+	date := t.now().UTC().Format(sigV4DateFormat)
+	scope := sigV4Scope(date, t.region)
+
+	req.Header.Set("X-Amz-Date", date)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm,
+		t.accessKey,
+		scope,
+		sigV4SignedHeaders,
+		signRequest(t.secretKey, t.region, req, date, payloadHash),
+	))
+
+	return t.base.RoundTrip(req)
+}
+
+// sigV4Scope returns the credential scope of a request signed at date.
+func sigV4Scope(date, region string) string {
+	return date[:8] + "/" + region + "/" + sigV4Service + "/aws4_request"
+}
+
+// signRequest returns the hex SigV4 signature of req, whose X-Amz-Date and
+// X-Amz-Content-Sha256 headers are date and payloadHash.
+func signRequest(secretKey, region string, req *http.Request, date, payloadHash string) string {
+	uri := req.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uri,
+		canonicalQuery(req.URL.Query()),
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + date + "\n",
+		sigV4SignedHeaders,
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := sigV4Scope(date, region)
+	stringToSign := sigV4Algorithm + "\n" + date + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalQuery encodes a query string with its keys and values sorted.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, url.QueryEscape(key)+"="+strings.ReplaceAll(url.QueryEscape(value), "+", "%20"))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// readRequestBody returns the request body without consuming it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// buildTLSConfig assembles the TLS settings for HTTPS backends. It returns
+// nil when none of the TLS options are set.
+func buildTLSConfig(caCertFile string, insecure bool, clientCertFile, clientKeyFile string) (*tls.Config, error) {
+	if caCertFile == "" && !insecure && clientCertFile == "" && clientKeyFile == "" {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: insecure,
+	}
+
+	if caCertFile != "" {
+		pem, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca_cert_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_cert_file %s contains no PEM certificates", caCertFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if clientCertFile != "" || clientKeyFile != "" {
+		if clientCertFile == "" || clientKeyFile == "" {
+			return nil, fmt.Errorf("client_cert_file and client_key_file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// socketDialer sends connections for placeholder hosts to Unix domain
+// sockets and dials everything else over TCP as usual.
+type socketDialer struct {
+	sockets map[string]string
+	dialer  net.Dialer
+}
+
+func (s *socketDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if path, ok := s.sockets[host]; ok {
+		return s.dialer.DialContext(ctx, "unix", path)
+	}
+	return s.dialer.DialContext(ctx, network, addr)
+}
+
+// resolveSocketURL rewrites a unix:///path/to/socket backend URL to an
+// http:// URL on a placeholder host and records the host's socket path in
+// sockets. Other URLs are returned unchanged.
+func resolveSocketURL(raw string, sockets map[string]string) (string, error) {
+	if !strings.HasPrefix(raw, "unix:") {
+		return raw, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid unix socket URL %q: %w", raw, err)
+	}
+	path := u.Host + u.Path
+	if path == "" {
+		return "", fmt.Errorf("unix socket URL %q has no socket path", raw)
+	}
+
+	for host, existing := range sockets {
+		if existing == path {
+			return "http://" + host, nil
+		}
+	}
+	host := fmt.Sprintf("unix-socket-%d", len(sockets))
+	sockets[host] = path
+	return "http://" + host, nil
+}
+
+// buildHTTPClient creates the HTTP client used by MockClient from the
+// provider's connection arguments. sockets maps the placeholder hosts
+// produced by resolveSocketURL to their socket paths.
+func buildHTTPClient(d *schema.ResourceData, sockets map[string]string) (*http.Client, error) {
+	tlsConfig, err := buildTLSConfig(
+		d.Get("ca_cert_file").(string),
+		d.Get("insecure").(bool),
+		d.Get("client_cert_file").(string),
+		d.Get("client_key_file").(string),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	if len(sockets) > 0 {
+		transport.DialContext = (&socketDialer{sockets: sockets}).DialContext
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if _, ok := sockets[req.URL.Hostname()]; ok {
+				return nil, nil
+			}
+			return http.ProxyFromEnvironment(req)
+		}
+	}
+
+	var rt http.RoundTripper = transport
+
+	token := d.Get("token").(string)
+	accessKey := d.Get("access_key").(string)
+	secretKey := d.Get("secret_key").(string)
+	if token != "" || accessKey != "" {
+		rt = &authTransport{
+			base:      transport,
+			token:     token,
+			accessKey: accessKey,
+			secretKey: secretKey,
+			region:    d.Get("region").(string),
+			now:       time.Now,
+		}
+	}
+
+	return &http.Client{Transport: rt}, nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestBearerTokenSentAsAuthorizationHeader(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(204)
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"region": "us-east-1",
+		"token":  "student-42",
+	})
+	httpClient, err := buildHTTPClient(d, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: httpClient}
+	if err := client.DeleteResource("aws_vpc", "vpc-abc123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if auth != "Bearer student-42" {
+		t.Errorf("expected bearer token header, got %q", auth)
+	}
+}
+
+func TestAccessKeySignsRequests(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var auth, date, payloadHash string
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		date = r.Header.Get("X-Amz-Date")
+		payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+		received = r.Clone(r.Context())
+		received.URL.Host = r.Host
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(201)
+		w.Write([]byte(`{"id":"vpc-abc123","attributes":{}}`))
+	}))
+	defer server.Close()
+
+	client := &MockClient{
+		BackendURL: server.URL,
+		HTTPClient: &http.Client{Transport: &authTransport{
+			base:      http.DefaultTransport,
+			accessKey: "AKIDSTUDENT",
+			secretKey: "s3cr3t",
+			region:    "eu-west-1",
+			now:       func() time.Time { return now },
+		}},
+	}
+
+	if _, err := client.CreateResource("aws_vpc", map[string]interface{}{"cidr_block": "10.0.0.0/16"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if date != "20260102T030405Z" {
+		t.Errorf("expected X-Amz-Date header, got %q", date)
+	}
+	if !strings.Contains(string(body), "10.0.0.0/16") {
+		t.Errorf("signing should not consume the request body, got %q", body)
+	}
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDSTUDENT/20260102/eu-west-1/mock/aws4_request, " +
+		"SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=" +
+		signRequest("s3cr3t", "eu-west-1", received, date, payloadHash)
+	if auth != expected {
+		t.Errorf("Authorization = %q, want %q", auth, expected)
+	}
+	if strings.Contains(auth, "s3cr3t") {
+		t.Error("secret key must never be sent on the wire")
+	}
+}
+
+func TestSignRequestCanonicalQuery(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://backend/resource/aws_s3_object?b=2&a=x%20y&a=1", nil)
+	other, _ := http.NewRequest("GET", "http://backend/resource/aws_s3_object?a=1&b=2&a=x+y", nil)
+
+	if canonicalQuery(req.URL.Query()) != "a=1&a=x%20y&b=2" {
+		t.Errorf("canonical query = %q", canonicalQuery(req.URL.Query()))
+	}
+	date := "20260102T030405Z"
+	if signRequest("k", "us-east-1", req, date, "") != signRequest("k", "us-east-1", other, date, "") {
+		t.Error("query parameter order should not change the signature")
+	}
+	if signRequest("k", "us-east-1", req, date, "") == signRequest("k", "us-west-2", req, date, "") {
+		t.Error("the region is part of the signature")
+	}
+}
+
+func TestTLSConfigTrustsCACertFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"region":       "us-east-1",
+		"ca_cert_file": caFile,
+	})
+	httpClient, err := buildHTTPClient(d, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: httpClient}
+	if err := client.ConfigureProvider("us-east-1"); err != nil {
+		t.Fatalf("expected CA file to be trusted, got: %v", err)
+	}
+}
+
+func TestTLSConfigRejectsUntrustedBackend(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"region": "us-east-1",
+	})
+	httpClient, err := buildHTTPClient(d, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := &MockClient{BackendURL: server.URL, HTTPClient: httpClient}
+	if err := client.ConfigureProvider("us-east-1"); err == nil {
+		t.Fatal("expected certificate verification to fail without ca_cert_file")
+	}
+
+	insecure, err := buildTLSConfig("", true, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !insecure.InsecureSkipVerify {
+		t.Error("insecure should skip certificate verification")
+	}
+}
+
+func TestTLSConfigClientCertNeedsKey(t *testing.T) {
+	if _, err := buildTLSConfig("", false, "client.pem", ""); err == nil {
+		t.Fatal("expected error when client_key_file is missing")
+	}
+	if cfg, err := buildTLSConfig("", false, "", ""); err != nil || cfg != nil {
+		t.Errorf("expected no TLS config without TLS options, got %v, %v", cfg, err)
+	}
+}
+
+func TestUnixSocketBackend(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "backend.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	var path string
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(204)
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	sockets := make(map[string]string)
+	backendURL, err := resolveSocketURL("unix://"+socketPath, sockets)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"region": "us-east-1",
+	})
+	httpClient, err := buildHTTPClient(d, sockets)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := &MockClient{BackendURL: backendURL, HTTPClient: httpClient}
+	if err := client.DeleteResource("aws_vpc", "vpc-abc123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/resource/aws_vpc/vpc-abc123" {
+		t.Errorf("expected request over the socket, got path %q", path)
+	}
+}
+
+func TestResolveSocketURL(t *testing.T) {
+	sockets := make(map[string]string)
+
+	plain, err := resolveSocketURL("http://localhost:3000", sockets)
+	if err != nil || plain != "http://localhost:3000" {
+		t.Errorf("http URLs should pass through, got %q, %v", plain, err)
+	}
+
+	first, _ := resolveSocketURL("unix:///tmp/a.sock", sockets)
+	again, _ := resolveSocketURL("unix:///tmp/a.sock", sockets)
+	other, _ := resolveSocketURL("unix:///tmp/b.sock", sockets)
+	if first != again {
+		t.Errorf("same socket should reuse its host, got %q and %q", first, again)
+	}
+	if first == other {
+		t.Error("different sockets should get different hosts")
+	}
+	if len(sockets) != 2 || sockets[strings.TrimPrefix(first, "http://")] != "/tmp/a.sock" {
+		t.Errorf("unexpected socket table: %v", sockets)
+	}
+
+	if _, err := resolveSocketURL("unix://", sockets); err == nil {
+		t.Error("expected error for a unix URL without a path")
+	}
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// mockAccountID is the account every mock resource belongs to, matching the
+// backend.
+const mockAccountID = "123456789012"
+
+// valueSynthesizer derives realistic computed values (IDs, ARNs, DNS names,
+// availability zones) from the resource type, attribute name and a seed.
+// Unlike the backend's synthesizer it is deterministic: the same seed and
+// inputs always give the same values, so plan output can be snapshotted.
+type valueSynthesizer struct {
+	seed      string
+	region    string
+	accountID string
+}
+
+func newValueSynthesizer(seed, region string) *valueSynthesizer {
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &valueSynthesizer{seed: seed, region: region, accountID: mockAccountID}
+}
+
+// hex returns n lowercase hex characters derived from the seed and parts.
+func (s *valueSynthesizer) hex(n int, parts ...string) string {
+	var out strings.Builder
+	input := s.seed + "\x00" + strings.Join(parts, "\x00")
+	for counter := 0; out.Len() < n; counter++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", input, counter)))
+		out.WriteString(hex.EncodeToString(sum[:]))
+	}
+	return out.String()[:n]
+}
+
+// number returns a value in [0, n) derived from the seed and parts.
+func (s *valueSynthesizer) number(n uint64, parts ...string) uint64 {
+	sum := sha256.Sum256([]byte(s.seed + "\x00" + strings.Join(parts, "\x00")))
+	return binary.BigEndian.Uint64(sum[:8]) % n
+}
+
+// resourceIDPrefixes holds the ID prefix of resource types with EC2-style
+// IDs, as in the backend's id-patterns.ts.
+var resourceIDPrefixes = map[string]string{
+	"aws_ami":                    "ami-",
+	"aws_customer_gateway":       "cgw-",
+	"aws_dhcp_options":           "dopt-",
+	"aws_ebs_volume":             "vol-",
+	"aws_eip":                    "eipalloc-",
+	"aws_flow_log":               "fl-",
+	"aws_instance":               "i-",
+	"aws_internet_gateway":       "igw-",
+	"aws_key_pair":               "key-",
+	"aws_launch_template":        "lt-",
+	"aws_nat_gateway":            "nat-",
+	"aws_network_acl":            "acl-",
+	"aws_network_interface":      "eni-",
+	"aws_placement_group":        "pg-",
+	"aws_route_table":            "rtb-",
+	"aws_security_group":         "sg-",
+	"aws_snapshot":               "snap-",
+	"aws_subnet":                 "subnet-",
+	"aws_vpc":                    "vpc-",
+	"aws_vpc_endpoint":           "vpce-",
+	"aws_vpc_peering_connection": "pcx-",
+	"aws_vpn_connection":         "vpn-",
+	"aws_vpn_gateway":            "vgw-",
+}
+
+// nameAsIDAttributes holds the attribute used as the ID of resource types
+// identified by name, as in the backend's id-patterns.ts.
+var nameAsIDAttributes = map[string]string{
+	"aws_cloudwatch_log_group":    "name",
+	"aws_cloudwatch_metric_alarm": "alarm_name",
+	"aws_codebuild_project":       "name",
+	"aws_codepipeline":            "name",
+	"aws_dynamodb_table":          "name",
+	"aws_ecr_repository":          "name",
+	"aws_elasticsearch_domain":    "domain_name",
+	"aws_iam_instance_profile":    "name",
+	"aws_iam_policy":              "name",
+	"aws_iam_role":                "name",
+	"aws_kinesis_stream":          "name",
+	"aws_lambda_function":         "function_name",
+	"aws_lambda_layer_version":    "layer_name",
+	"aws_opensearch_domain":       "domain_name",
+	"aws_route53_zone":            "name",
+	"aws_s3_bucket":               "bucket",
+	"aws_secretsmanager_secret":   "name",
+	"aws_sns_topic":               "name",
+	"aws_sqs_queue":               "name",
+	"aws_ssm_parameter":           "name",
+}
+
+// resourceID returns the ID of the key'th resource of a type: its name for
+// types identified by name, an EC2-style ID such as vpc-0a1b2c3d4e5f67890
+// where the type has a prefix, and 20 hex characters otherwise.
+func (s *valueSynthesizer) resourceID(resourceType, key string, attrs map[string]interface{}) string {
+	if attr, ok := nameAsIDAttributes[resourceType]; ok {
+		if name, _ := attrs[attr].(string); name != "" {
+			return name
+		}
+	}
+	if prefix, ok := resourceIDPrefixes[resourceType]; ok {
+		return prefix + "0" + s.hex(16, resourceType, key, "id")
+	}
+	return s.hex(20, resourceType, key, "id")
+}
+
+// arnResources holds the resource part of the ARN for types whose ARN does
+// not follow <service>:<region>:<account>:<type>/<id>, and whether it leaves
+// out the region (global services) and account ID (S3).
+var arnResources = map[string]struct {
+	format        string
+	global        bool
+	omitAccountID bool
+}{
+	"aws_ami":                     {format: "image/%s"},
+	"aws_cloudwatch_log_group":    {format: "log-group:%s"},
+	"aws_cloudwatch_metric_alarm": {format: "alarm:%s"},
+	"aws_db_instance":             {format: "db:%s"},
+	"aws_ebs_volume":              {format: "volume/%s"},
+	"aws_eip":                     {format: "elastic-ip/%s"},
+	"aws_iam_instance_profile":    {format: "instance-profile/%s", global: true},
+	"aws_iam_policy":              {format: "policy/%s", global: true},
+	"aws_iam_role":                {format: "role/%s", global: true},
+	"aws_iam_user":                {format: "user/%s", global: true},
+	"aws_lambda_function":         {format: "function:%s"},
+	"aws_nat_gateway":             {format: "natgateway/%s"},
+	"aws_rds_cluster":             {format: "cluster:%s"},
+	"aws_s3_bucket":               {format: "%s", global: true, omitAccountID: true},
+	"aws_secretsmanager_secret":   {format: "secret:%s"},
+	"aws_sns_topic":               {format: "%s"},
+	"aws_sqs_queue":               {format: "%s"},
+	"aws_ssm_parameter":           {format: "parameter/%s"},
+}
+
+// arn returns the ARN of a resource. Types not listed in arnResources get
+// arn:aws:<service>:<region>:<account>:<type>/<id>, with the type's service
+// prefix dropped and underscores turned into dashes.
+func (s *valueSynthesizer) arn(resourceType, id string) string {
+	service := serviceForResourceType(resourceType)
+	a := arn{Partition: "aws", Service: service, Region: s.region, AccountID: s.accountID}
+
+	if pattern, ok := arnResources[resourceType]; ok {
+		a.Resource = fmt.Sprintf(pattern.format, id)
+		if pattern.global {
+			a.Region = ""
+		}
+		if pattern.omitAccountID {
+			a.AccountID = ""
+		}
+		return a.String()
+	}
+
+	kind := strings.TrimPrefix(resourceType, "aws_")
+	kind = strings.TrimPrefix(kind, service+"_")
+	a.Resource = strings.ReplaceAll(kind, "_", "-") + "/" + id
+	return a.String()
+}
+
+// availabilityZone returns one of the region's first three zones.
+func (s *valueSynthesizer) availabilityZone(key string) string {
+	return s.region + string(rune('a'+s.number(3, key, "availability_zone")))
+}
+
+// availabilityZoneID maps a zone name to a zone ID such as use1-az2.
+func (s *valueSynthesizer) availabilityZoneID(az string) string {
+	region := strings.TrimRight(az, "abcdefghijklmnopqrstuvwxyz")
+	parts := strings.Split(region, "-")
+	if len(parts) != 3 || len(az) == len(region) {
+		return ""
+	}
+	short := parts[0] + string(parts[1][0]) + parts[2]
+	return fmt.Sprintf("%s-az%d", short, az[len(az)-1]-'a'+1)
+}
+
+// privateIP returns an address in 10.0.0.0/16 that avoids each /24's
+// reserved addresses.
+func (s *valueSynthesizer) privateIP(key string) string {
+	return fmt.Sprintf("10.0.%d.%d", s.number(256, key, "private_ip"), 4+s.number(250, key, "private_ip", "host"))
+}
+
+// publicIP returns an address in the 3.0.0.0/8 block AWS uses for EC2
+// public IPs.
+func (s *valueSynthesizer) publicIP(key string) string {
+	return fmt.Sprintf("3.%d.%d.%d", s.number(256, key, "public_ip", "1"), s.number(256, key, "public_ip", "2"), 1+s.number(254, key, "public_ip", "3"))
+}
+
+// timestamp returns an RFC 3339 time during 2024.
+func (s *valueSynthesizer) timestamp(key, attribute string) string {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	offset := time.Duration(s.number(366*24*3600, key, attribute)) * time.Second
+	return start.Add(offset).Format(time.RFC3339)
+}
+
+// attributeIDPrefixes holds the prefix of ID-valued attributes that point at
+// resources of another type.
+var attributeIDPrefixes = map[string]string{
+	"allocation_id":                  "eipalloc-",
+	"association_id":                 "eipassoc-",
+	"default_network_acl_id":         "acl-",
+	"default_route_table_id":         "rtb-",
+	"default_security_group_id":      "sg-",
+	"dhcp_options_id":                "dopt-",
+	"image_id":                       "ami-",
+	"internet_gateway_id":            "igw-",
+	"ipv6_association_id":            "vpc-cidr-assoc-",
+	"ipv6_cidr_block_association_id": "subnet-cidr-assoc-",
+	"main_route_table_id":            "rtb-",
+	"nat_gateway_id":                 "nat-",
+	"network_interface_id":           "eni-",
+	"primary_network_interface_id":   "eni-",
+	"route_table_id":                 "rtb-",
+	"security_group_id":              "sg-",
+	"subnet_id":                      "subnet-",
+	"volume_id":                      "vol-",
+	"vpc_id":                         "vpc-",
+}
+
+// attribute synthesizes a value for one attribute of a resource. attrs holds
+// the values known so far, so that derived values agree with them (e.g.
+// private_dns with private_ip). ok is false for attributes it has no
+// realistic value for.
+func (s *valueSynthesizer) attribute(resourceType, id, name string, field *schema.Schema, attrs map[string]interface{}) (value interface{}, ok bool) {
+	key := resourceType + "/" + id
+
+	switch field.Type {
+	case schema.TypeString:
+	case schema.TypeBool:
+		return false, true
+	case schema.TypeInt, schema.TypeFloat:
+		return 0, true
+	case schema.TypeMap:
+		if name == "tags_all" {
+			if tags, ok := attrs["tags"].(map[string]interface{}); ok {
+				return tags, true
+			}
+		}
+		return map[string]interface{}{}, true
+	default:
+		return []interface{}{}, true
+	}
+
+	switch {
+	case name == "arn":
+		return s.arn(resourceType, id), true
+	case name == "owner_id" || name == "account_id":
+		return s.accountID, true
+	case name == "region":
+		return s.region, true
+	case name == "availability_zone":
+		return s.availabilityZone(key), true
+	case name == "availability_zone_id":
+		az, _ := attrs["availability_zone"].(string)
+		if az == "" {
+			az = s.availabilityZone(key)
+		}
+		return s.availabilityZoneID(az), true
+	case name == "private_ip":
+		return s.privateIP(key), true
+	case name == "public_ip":
+		return s.publicIP(key), true
+	case name == "private_dns":
+		ip, _ := attrs["private_ip"].(string)
+		if ip == "" {
+			ip = s.privateIP(key)
+		}
+		return privateDNSName(ip, s.region), true
+	case name == "public_dns":
+		ip, _ := attrs["public_ip"].(string)
+		if ip == "" {
+			ip = s.publicIP(key)
+		}
+		return publicDNSName(ip, s.region), true
+	case name == "hosted_zone_id":
+		return "Z" + strings.ToUpper(s.hex(13, key, name)), true
+	case name == "unique_id":
+		return "AROA" + strings.ToUpper(s.hex(17, key, name)), true
+	case name == "create_date" || name == "created_date" || name == "creation_date" || strings.HasSuffix(name, "_time"):
+		return s.timestamp(key, name), true
+	case attributeIDPrefixes[name] != "":
+		return attributeIDPrefixes[name] + "0" + s.hex(16, key, name), true
+	case strings.HasSuffix(name, "dns_name") || name == "endpoint":
+		return fmt.Sprintf("%s-%s.%s.%s.amazonaws.com",
+			strings.ReplaceAll(strings.TrimPrefix(resourceType, "aws_"), "_", "-"), s.hex(10, key, name),
+			s.region, serviceForResourceType(resourceType)), true
+	}
+	return nil, false
+}
+
+// fillComputed sets every computed attribute that attrs lacks to a
+// synthesized value, leaving values already present (e.g. from the backend
+// or configuration) untouched. Attributes it has no realistic value for are
+// left unset.
+func (s *valueSynthesizer) fillComputed(resourceType, id string, sch map[string]*schema.Schema, attrs map[string]interface{}) {
+	for _, name := range sortedSchemaKeys(sch) {
+		field := sch[name]
+		if !field.Computed {
+			continue
+		}
+		if _, present := attrs[name]; present {
+			continue
+		}
+		if value, ok := s.attribute(resourceType, id, name, field, attrs); ok {
+			attrs[name] = value
+		}
+	}
+}
+
+// sortedSchemaKeys returns the schema's keys in a fixed order, with those
+// that other values derive from (e.g. private_ip for private_dns) first.
+func sortedSchemaKeys(sch map[string]*schema.Schema) []string {
+	first := []string{"availability_zone", "private_ip", "public_ip", "tags"}
+	keys := make([]string, 0, len(sch))
+	for _, name := range first {
+		if _, ok := sch[name]; ok {
+			keys = append(keys, name)
+		}
+	}
+	rest := make([]string, 0, len(sch))
+	for name := range sch {
+		if !slices.Contains(first, name) {
+			rest = append(rest, name)
+		}
+	}
+	slices.Sort(rest)
+	return append(keys, rest...)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestValueSynthesizerIsDeterministic(t *testing.T) {
+	a := newValueSynthesizer("seed", "us-east-1")
+	b := newValueSynthesizer("seed", "us-east-1")
+	other := newValueSynthesizer("other", "us-east-1")
+
+	if a.resourceID("aws_vpc", "1", nil) != b.resourceID("aws_vpc", "1", nil) {
+		t.Error("the same seed should give the same ID")
+	}
+	if a.resourceID("aws_vpc", "1", nil) == a.resourceID("aws_vpc", "2", nil) {
+		t.Error("different keys should give different IDs")
+	}
+	if a.resourceID("aws_vpc", "1", nil) == other.resourceID("aws_vpc", "1", nil) {
+		t.Error("different seeds should give different IDs")
+	}
+}
+
+// TestValueSynthesizerSnapshot pins synthesized values; plan snapshots
+// elsewhere depend on them staying the same across releases.
+func TestValueSynthesizerSnapshot(t *testing.T) {
+	s := newValueSynthesizer("snapshot", "us-east-1")
+	vpcID := s.resourceID("aws_vpc", "1", nil)
+
+	tests := []struct{ name, got, want string }{
+		{"vpc id", vpcID, "vpc-0ab07d914b27cdaa0"},
+		{"vpc arn", s.arn("aws_vpc", vpcID), "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0ab07d914b27cdaa0"},
+		{"availability zone", s.availabilityZone("aws_subnet/1"), "us-east-1b"},
+		{"private ip", s.privateIP("aws_instance/1"), "10.0.227.22"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestValueSynthesizerFormats(t *testing.T) {
+	s := newValueSynthesizer("formats", "eu-west-1")
+
+	patterns := map[string]struct{ got, pattern string }{
+		"ec2 id":         {s.resourceID("aws_subnet", "1", nil), `^subnet-0[0-9a-f]{16}$`},
+		"hex id":         {s.resourceID("aws_mq_broker", "1", nil), `^[0-9a-f]{20}$`},
+		"name id":        {s.resourceID("aws_sqs_queue", "1", map[string]interface{}{"name": "jobs"}), `^jobs$`},
+		"sqs arn":        {s.arn("aws_sqs_queue", "jobs"), `^arn:aws:sqs:eu-west-1:123456789012:jobs$`},
+		"bucket arn":     {s.arn("aws_s3_bucket", "logs"), `^arn:aws:s3:::logs$`},
+		"role arn":       {s.arn("aws_iam_role", "app"), `^arn:aws:iam::123456789012:role/app$`},
+		"generic arn":    {s.arn("aws_ecs_capacity_provider", "cp"), `^arn:aws:ecs:eu-west-1:123456789012:capacity-provider/cp$`},
+		"zone":           {s.availabilityZone("x"), `^eu-west-1[abc]$`},
+		"zone id":        {s.availabilityZoneID("eu-west-1b"), `^euw1-az2$`},
+		"public ip":      {s.publicIP("x"), `^3\.\d+\.\d+\.\d+$`},
+		"timestamp":      {s.timestamp("x", "create_date"), `^2024-\d\d-\d\dT\d\d:\d\d:\d\dZ$`},
+		"long hex value": {s.hex(100, "x"), `^[0-9a-f]{100}$`},
+	}
+	for name, tt := range patterns {
+		if !regexp.MustCompile(tt.pattern).MatchString(tt.got) {
+			t.Errorf("%s = %q, want match for %s", name, tt.got, tt.pattern)
+		}
+	}
+}
+
+func TestFillComputedKeepsDerivedValuesConsistent(t *testing.T) {
+	s := newValueSynthesizer("fill", "us-east-1")
+	r := Provider().ResourcesMap["aws_instance"]
+
+	attrs := map[string]interface{}{"instance_type": "t3.micro", "public_ip": "3.3.3.3"}
+	s.fillComputed("aws_instance", "i-0123", r.Schema, attrs)
+
+	if attrs["instance_type"] != "t3.micro" || attrs["public_ip"] != "3.3.3.3" {
+		t.Error("values already present must not be overwritten")
+	}
+	if attrs["public_dns"] != publicDNSName("3.3.3.3", "us-east-1") {
+		t.Errorf("public_dns %v does not match public_ip", attrs["public_dns"])
+	}
+	ip, _ := attrs["private_ip"].(string)
+	if attrs["private_dns"] != privateDNSName(ip, "us-east-1") {
+		t.Errorf("private_dns %v does not match private_ip %s", attrs["private_dns"], ip)
+	}
+	if attrs["arn"] != "arn:aws:ec2:us-east-1:123456789012:instance/i-0123" {
+		t.Errorf("arn = %v", attrs["arn"])
+	}
+}
+
+func TestSynthesizingBackendServesStableValues(t *testing.T) {
+	create := func() map[string]interface{} {
+		_, client := newSynthesizingBackend(t, "stand-in")
+		res := Provider().ResourcesMap["aws_vpc"]
+		d := res.TestResourceData()
+		d.Set("cidr_block", "10.1.0.0/16")
+		if diags := res.CreateContext(context.Background(), d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		return map[string]interface{}{
+			"id":                        d.Id(),
+			"arn":                       d.Get("arn"),
+			"owner_id":                  d.Get("owner_id"),
+			"default_security_group_id": d.Get("default_security_group_id"),
+		}
+	}
+
+	first, second := create(), create()
+	for key, value := range first {
+		if value == "" {
+			t.Errorf("%s was not synthesized", key)
+		}
+		if second[key] != value {
+			t.Errorf("%s differs between runs: %v and %v", key, value, second[key])
+		}
+	}
+	if !regexp.MustCompile(`^vpc-0[0-9a-f]{16}$`).MatchString(first["id"].(string)) {
+		t.Errorf("id %v is not a VPC ID", first["id"])
+	}
+}
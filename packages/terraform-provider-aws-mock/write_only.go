@@ -0,0 +1,90 @@
+package main
+
+import (
+	"math/big"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// writeOnlyAttribute is a write-only argument the real provider offers in
+// place of a state-persisted one, e.g. password_wo for password.
+type writeOnlyAttribute struct {
+	name     string
+	replaces string
+}
+
+// knownWriteOnlyAttributes lists the real provider's write-only arguments,
+// so they exist even when the embedded schema dump predates them. Each
+// comes with a <name>_version argument that triggers sending a new value.
+var knownWriteOnlyAttributes = map[string][]writeOnlyAttribute{
+	"aws_db_instance":                   {{name: "password_wo", replaces: "password"}},
+	"aws_docdb_cluster":                 {{name: "master_password_wo", replaces: "master_password"}},
+	"aws_rds_cluster":                   {{name: "master_password_wo", replaces: "master_password"}},
+	"aws_redshift_cluster":              {{name: "master_password_wo", replaces: "master_password"}},
+	"aws_secretsmanager_secret_version": {{name: "secret_string_wo", replaces: "secret_string"}},
+	"aws_ssm_parameter":                 {{name: "value_wo", replaces: "value"}},
+}
+
+// addWriteOnlyAttributes adds the known write-only arguments, and their
+// version arguments, to resources whose schema lacks them.
+func addWriteOnlyAttributes(resources map[string]*schema.Resource) {
+	for resourceType, attributes := range knownWriteOnlyAttributes {
+		r, ok := resources[resourceType]
+		if !ok {
+			continue
+		}
+		for _, wo := range attributes {
+			if _, ok := r.Schema[wo.name]; !ok {
+				s := &schema.Schema{
+					Type:      schema.TypeString,
+					Optional:  true,
+					WriteOnly: true,
+					Sensitive: true,
+				}
+				if _, ok := r.Schema[wo.replaces]; ok {
+					s.ConflictsWith = []string{wo.replaces}
+				}
+				r.Schema[wo.name] = s
+			}
+			if _, ok := r.Schema[wo.name+"_version"]; !ok {
+				r.Schema[wo.name+"_version"] = &schema.Schema{
+					Type:         schema.TypeInt,
+					Optional:     true,
+					RequiredWith: []string{wo.name},
+				}
+			}
+		}
+	}
+}
+
+// writeOnlyValues reads write-only arguments from the configuration, the
+// only place Terraform passes them, for sending to the backend. A value is
+// sent on create, and afterwards only when its <name>_version changes;
+// arguments without a version are sent whenever they are set.
+func writeOnlyValues(d *schema.ResourceData, s map[string]*schema.Schema) map[string]interface{} {
+	values := make(map[string]interface{})
+	for key, field := range s {
+		if !field.WriteOnly {
+			continue
+		}
+		if _, versioned := s[key+"_version"]; versioned && !d.IsNewResource() && !d.HasChange(key+"_version") {
+			continue
+		}
+
+		v, diags := d.GetRawConfigAt(cty.GetAttrPath(key))
+		if diags.HasError() || v.IsNull() || !v.IsKnown() {
+			continue
+		}
+		switch v.Type() {
+		case cty.String:
+			values[key] = v.AsString()
+		case cty.Bool:
+			values[key] = v.True()
+		case cty.Number:
+			n, _ := v.AsBigFloat().Int(new(big.Int))
+			values[key] = n.Int64()
+		}
+	}
+	return values
+}
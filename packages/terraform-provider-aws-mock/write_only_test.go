@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestKnownWriteOnlyAttributesAdded(t *testing.T) {
+	p := Provider()
+	r, ok := p.ResourcesMap["aws_db_instance"]
+	if !ok {
+		t.Skip("aws_db_instance is not in the embedded schema")
+	}
+	if !r.Schema["password_wo"].WriteOnly {
+		t.Error("aws_db_instance.password_wo should be write-only")
+	}
+	if r.Schema["password_wo_version"] == nil {
+		t.Error("aws_db_instance.password_wo_version missing")
+	}
+	if err := p.InternalValidate(); err != nil {
+		t.Errorf("provider schema is invalid: %v", err)
+	}
+}
+
+// resourceObject builds a value of a resource's type with every attribute
+// null, and every block empty, apart from values.
+func resourceObject(r *schema.Resource, values map[string]cty.Value) cty.Value {
+	block := r.CoreConfigSchema()
+	attrs := make(map[string]cty.Value)
+	for name, ty := range block.ImpliedType().AttributeTypes() {
+		_, isBlock := block.BlockTypes[name]
+		switch {
+		case isBlock && ty.IsObjectType():
+			attrs[name] = cty.NullVal(ty)
+		case isBlock && ty.IsSetType():
+			attrs[name] = cty.SetValEmpty(ty.ElementType())
+		case isBlock:
+			attrs[name] = cty.ListValEmpty(ty.ElementType())
+		default:
+			attrs[name] = cty.NullVal(ty)
+		}
+	}
+	for name, v := range values {
+		attrs[name] = v
+	}
+	return cty.ObjectVal(attrs)
+}
+
+// proposedNewState mimics what Terraform core proposes: config values, with
+// prior values kept for computed attributes the configuration leaves unset.
+func proposedNewState(r *schema.Resource, prior, config cty.Value) cty.Value {
+	if prior.IsNull() {
+		return config
+	}
+	merged := make(map[string]cty.Value)
+	priorAttrs := prior.AsValueMap()
+	for name, v := range config.AsValueMap() {
+		if v.IsNull() && r.Schema[name] != nil && r.Schema[name].Computed {
+			v = priorAttrs[name]
+		}
+		merged[name] = v
+	}
+	merged["id"] = priorAttrs["id"]
+	return cty.ObjectVal(merged)
+}
+
+// applyDBInstance plans and applies config over prior, returning the new
+// state as stored by Terraform.
+func applyDBInstance(t *testing.T, server tfprotov5.ProviderServer, r *schema.Resource, prior, config cty.Value) *tfprotov5.DynamicValue {
+	t.Helper()
+	ctx := context.Background()
+	ty := r.CoreConfigSchema().ImpliedType()
+
+	encode := func(v cty.Value) *tfprotov5.DynamicValue {
+		raw, err := msgpack.Marshal(v, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &tfprotov5.DynamicValue{MsgPack: raw}
+	}
+
+	proposed := proposedNewState(r, prior, config)
+
+	validate, err := server.ValidateResourceTypeConfig(ctx, &tfprotov5.ValidateResourceTypeConfigRequest{
+		TypeName:           "aws_db_instance",
+		Config:             encode(config),
+		ClientCapabilities: &tfprotov5.ValidateResourceTypeConfigClientCapabilities{WriteOnlyAttributesAllowed: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range validate.Diagnostics {
+		t.Fatalf("validate: %s: %s", d.Summary, d.Detail)
+	}
+
+	plan, err := server.PlanResourceChange(ctx, &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         "aws_db_instance",
+		PriorState:       encode(prior),
+		ProposedNewState: encode(proposed),
+		Config:           encode(config),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range plan.Diagnostics {
+		t.Fatalf("plan: %s: %s", d.Summary, d.Detail)
+	}
+
+	apply, err := server.ApplyResourceChange(ctx, &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:       "aws_db_instance",
+		PriorState:     encode(prior),
+		PlannedState:   plan.PlannedState,
+		PlannedPrivate: plan.PlannedPrivate,
+		Config:         encode(config),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range apply.Diagnostics {
+		t.Fatalf("apply: %s: %s", d.Summary, d.Detail)
+	}
+	if strings.Contains(string(plan.PlannedState.MsgPack), "s3cr3t") {
+		t.Error("write-only value appears in the plan")
+	}
+	return apply.NewState
+}
+
+func TestWriteOnlyPasswordNeverInState(t *testing.T) {
+	b, client := newMemoryBackend(t)
+	p := Provider()
+	r, ok := p.ResourcesMap["aws_db_instance"]
+	if !ok {
+		t.Skip("aws_db_instance is not in the embedded schema")
+	}
+	p.SetMeta(client)
+	server := schema.NewGRPCProviderServer(p)
+	ty := r.CoreConfigSchema().ImpliedType()
+
+	config := resourceObject(r, map[string]cty.Value{
+		"instance_class":      cty.StringVal("db.t3.micro"),
+		"password_wo":         cty.StringVal("s3cr3t-1"),
+		"password_wo_version": cty.NumberIntVal(1),
+	})
+	state := applyDBInstance(t, server, r, cty.NullVal(ty), config)
+
+	if strings.Contains(string(state.MsgPack), "s3cr3t") {
+		t.Fatal("write-only value appears in state")
+	}
+	stateVal, err := msgpack.Unmarshal(state.MsgPack, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stateVal.GetAttr("password_wo").IsNull() {
+		t.Error("password_wo should be null in state")
+	}
+	id := stateVal.GetAttr("id").AsString()
+	if got := b.get("aws_db_instance", id)["password_wo"]; got != "s3cr3t-1" {
+		t.Errorf("backend password_wo = %v, want s3cr3t-1", got)
+	}
+
+	// A new value with the same version is not sent.
+	config = resourceObject(r, map[string]cty.Value{
+		"instance_class":      cty.StringVal("db.t3.small"),
+		"password_wo":         cty.StringVal("s3cr3t-2"),
+		"password_wo_version": cty.NumberIntVal(1),
+	})
+	state = applyDBInstance(t, server, r, stateVal, config)
+	if got := b.get("aws_db_instance", id)["password_wo"]; got != "s3cr3t-1" {
+		t.Errorf("password_wo sent without a version bump: backend has %v", got)
+	}
+
+	// Bumping the version sends it.
+	stateVal, _ = msgpack.Unmarshal(state.MsgPack, ty)
+	config = resourceObject(r, map[string]cty.Value{
+		"instance_class":      cty.StringVal("db.t3.small"),
+		"password_wo":         cty.StringVal("s3cr3t-2"),
+		"password_wo_version": cty.NumberIntVal(2),
+	})
+	state = applyDBInstance(t, server, r, stateVal, config)
+	if got := b.get("aws_db_instance", id)["password_wo"]; got != "s3cr3t-2" {
+		t.Errorf("backend password_wo = %v after a version bump, want s3cr3t-2", got)
+	}
+	if strings.Contains(string(state.MsgPack), "s3cr3t") {
+		t.Error("write-only value appears in state after update")
+	}
+}